@@ -0,0 +1,268 @@
+// Package analytics collects every comment of a Tistory blog and reports
+// per-post and per-author statistics: comment counts, reply latency and the
+// share of secret comments. Reports export as CSV or JSON.
+//
+//	posts, err := analytics.Collect(blog)
+//	month := analytics.Between(posts, since, until)
+//	report := analytics.NewReport(month)
+//	err = analytics.WriteAuthorsCSV(os.Stdout, report.Authors)
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+)
+
+// PostComments is a post and its comments.
+type PostComments struct {
+	Post     tistory.PostSummary
+	Comments []tistory.Comment
+	// Parents are the comments left out by Between whose replies were kept.
+	// They are not counted; NewReport only uses them for reply latency.
+	Parents []tistory.Comment
+}
+
+/*
+Collect walks every post with GetPostList and reads the comments of the
+posts that have any with GetCommentList.
+*/
+func Collect(blog *tistory.Tistory) ([]PostComments, error) {
+	posts, err := blog.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+
+	collected := make([]PostComments, 0, len(posts))
+	for _, post := range posts {
+		pc := PostComments{Post: post}
+		if post.Comments > 0 {
+			result, err := blog.GetCommentList(post.Id)
+			if err != nil {
+				return nil, err
+			}
+			if pc.Comments, err = tistory.ParseComments(result); err != nil {
+				return nil, err
+			}
+		}
+		collected = append(collected, pc)
+	}
+	return collected, nil
+}
+
+/*
+Between keeps the comments written in [since, until). A zero bound is open.
+The parents of kept replies written outside the range go to Parents, so that
+reply latency can still be measured.
+*/
+func Between(posts []PostComments, since, until time.Time) []PostComments {
+	filtered := make([]PostComments, 0, len(posts))
+	for _, pc := range posts {
+		out := PostComments{Post: pc.Post}
+		kept := map[int]bool{}
+		for _, c := range pc.Comments {
+			if (since.IsZero() || !c.Date.Before(since)) && (until.IsZero() || c.Date.Before(until)) {
+				out.Comments = append(out.Comments, c)
+				kept[c.Id] = true
+			}
+		}
+		parents := map[int]bool{}
+		for _, c := range out.Comments {
+			if c.ParentId != 0 && !kept[c.ParentId] {
+				parents[c.ParentId] = true
+			}
+		}
+		for _, comments := range [][]tistory.Comment{pc.Comments, pc.Parents} {
+			for _, c := range comments {
+				if parents[c.Id] {
+					out.Parents = append(out.Parents, c)
+					delete(parents, c.Id)
+				}
+			}
+		}
+		filtered = append(filtered, out)
+	}
+	return filtered
+}
+
+// Duration is a time.Duration encoded as text ("1h30m0s") in JSON and CSV.
+type Duration time.Duration
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// PostStats are the comment statistics of one post.
+type PostStats struct {
+	PostId      int       `json:"postId"`
+	Title       string    `json:"title"`
+	PostURL     string    `json:"postUrl"`
+	Comments    int       `json:"comments"`
+	Replies     int       `json:"replies"`
+	Secret      int       `json:"secret"`
+	SecretRatio float64   `json:"secretRatio"`
+	Authors     int       `json:"authors"`
+	First       time.Time `json:"first"`
+	Last        time.Time `json:"last"`
+	// AvgReplyLatency and MedianReplyLatency measure the time from a
+	// comment to the replies it received.
+	AvgReplyLatency    Duration `json:"avgReplyLatency"`
+	MedianReplyLatency Duration `json:"medianReplyLatency"`
+}
+
+// AuthorStats are the statistics of one comment author (by name).
+type AuthorStats struct {
+	Name        string    `json:"name"`
+	Comments    int       `json:"comments"`
+	Replies     int       `json:"replies"`
+	Posts       int       `json:"posts"`
+	Secret      int       `json:"secret"`
+	SecretRatio float64   `json:"secretRatio"`
+	First       time.Time `json:"first"`
+	Last        time.Time `json:"last"`
+	// AvgReplyLatency and MedianReplyLatency measure how fast the author
+	// replied to other comments.
+	AvgReplyLatency    Duration `json:"avgReplyLatency"`
+	MedianReplyLatency Duration `json:"medianReplyLatency"`
+}
+
+// Report holds the statistics of a blog.
+type Report struct {
+	Comments int           `json:"comments"`
+	Secret   int           `json:"secret"`
+	Posts    []PostStats   `json:"posts"`
+	Authors  []AuthorStats `json:"authors"`
+}
+
+type author struct {
+	stats     AuthorStats
+	posts     map[int]bool
+	latencies []time.Duration
+}
+
+/*
+NewReport computes the statistics of posts. Posts without comments are left
+out; posts are ordered by comment count and authors by comment count, then name.
+A reply's latency is its date minus the date of its parent comment.
+*/
+func NewReport(posts []PostComments) *Report {
+	report := &Report{}
+	authors := map[string]*author{}
+
+	for _, pc := range posts {
+		if len(pc.Comments) == 0 {
+			continue
+		}
+		byId := make(map[int]tistory.Comment, len(pc.Comments)+len(pc.Parents))
+		for _, c := range pc.Parents {
+			byId[c.Id] = c
+		}
+		for _, c := range pc.Comments {
+			byId[c.Id] = c
+		}
+
+		ps := PostStats{PostId: pc.Post.Id, Title: pc.Post.Title, PostURL: pc.Post.PostURL}
+		names := map[string]bool{}
+		var latencies []time.Duration
+		for _, c := range pc.Comments {
+			ps.Comments++
+			names[c.Name] = true
+			ps.First, ps.Last = span(ps.First, ps.Last, c.Date)
+
+			a, ok := authors[c.Name]
+			if !ok {
+				a = &author{stats: AuthorStats{Name: c.Name}, posts: map[int]bool{}}
+				authors[c.Name] = a
+			}
+			a.stats.Comments++
+			a.posts[pc.Post.Id] = true
+			a.stats.First, a.stats.Last = span(a.stats.First, a.stats.Last, c.Date)
+
+			if c.Secret {
+				ps.Secret++
+				a.stats.Secret++
+			}
+			if c.ParentId != 0 {
+				ps.Replies++
+				a.stats.Replies++
+				if parent, ok := byId[c.ParentId]; ok && !parent.Date.IsZero() && !c.Date.IsZero() {
+					latency := c.Date.Sub(parent.Date)
+					latencies = append(latencies, latency)
+					a.latencies = append(a.latencies, latency)
+				}
+			}
+		}
+		ps.Authors = len(names)
+		ps.SecretRatio = ratio(ps.Secret, ps.Comments)
+		ps.AvgReplyLatency, ps.MedianReplyLatency = latencyStats(latencies)
+
+		report.Comments += ps.Comments
+		report.Secret += ps.Secret
+		report.Posts = append(report.Posts, ps)
+	}
+
+	for _, a := range authors {
+		a.stats.Posts = len(a.posts)
+		a.stats.SecretRatio = ratio(a.stats.Secret, a.stats.Comments)
+		a.stats.AvgReplyLatency, a.stats.MedianReplyLatency = latencyStats(a.latencies)
+		report.Authors = append(report.Authors, a.stats)
+	}
+
+	sort.SliceStable(report.Posts, func(i, j int) bool {
+		if report.Posts[i].Comments != report.Posts[j].Comments {
+			return report.Posts[i].Comments > report.Posts[j].Comments
+		}
+		return report.Posts[i].PostId < report.Posts[j].PostId
+	})
+	sort.Slice(report.Authors, func(i, j int) bool {
+		if report.Authors[i].Comments != report.Authors[j].Comments {
+			return report.Authors[i].Comments > report.Authors[j].Comments
+		}
+		return report.Authors[i].Name < report.Authors[j].Name
+	})
+	return report
+}
+
+func span(first, last, date time.Time) (time.Time, time.Time) {
+	if date.IsZero() {
+		return first, last
+	}
+	if first.IsZero() || date.Before(first) {
+		first = date
+	}
+	if last.IsZero() || date.After(last) {
+		last = date
+	}
+	return first, last
+}
+
+func ratio(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func latencyStats(latencies []time.Duration) (avg, median Duration) {
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, l := range sorted {
+		sum += l
+	}
+	mid := len(sorted) / 2
+	med := sorted[mid]
+	if len(sorted)%2 == 0 {
+		med = (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return Duration(sum / time.Duration(len(sorted))), Duration(med)
+}
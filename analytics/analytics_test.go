@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestReport(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	base := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	add := func(c tistorytest.Comment, after time.Duration) int {
+		c.Date = base.Add(after)
+		return srv.AddComment(tistorytest.DefaultBlog, c)
+	}
+	post1 := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "one"})
+	post2 := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "two"})
+	srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "quiet"})
+
+	q1 := add(tistorytest.Comment{PostID: post1, Name: "alice", Content: "질문"}, 0)
+	add(tistorytest.Comment{PostID: post1, ParentID: q1, Name: "owner", Content: "답변"}, time.Hour)
+	q2 := add(tistorytest.Comment{PostID: post1, Name: "bob", Content: "비밀 질문", Secret: true}, 2*time.Hour)
+	add(tistorytest.Comment{PostID: post1, ParentID: q2, Name: "owner", Content: "답변", Secret: true}, 5*time.Hour)
+	add(tistorytest.Comment{PostID: post2, Name: "alice", Content: "감사합니다"}, 31*24*time.Hour)
+
+	blog := testblog.New(srv)
+	posts, err := Collect(blog)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(posts) != 3 {
+		t.Fatalf("Collect() = %d posts, want 3", len(posts))
+	}
+
+	report := NewReport(posts)
+	if report.Comments != 5 || report.Secret != 2 || len(report.Posts) != 2 || len(report.Authors) != 3 {
+		t.Fatalf("NewReport() = %+v", report)
+	}
+	p := report.Posts[0]
+	if p.PostId != post1 || p.Comments != 4 || p.Replies != 2 || p.Authors != 3 || p.SecretRatio != 0.5 ||
+		p.AvgReplyLatency != Duration(2*time.Hour) || p.MedianReplyLatency != Duration(2*time.Hour) {
+		t.Errorf("NewReport() post = %+v", p)
+	}
+	owner := report.Authors[1]
+	if owner.Name != "owner" || owner.Replies != 2 || owner.Posts != 1 || owner.AvgReplyLatency != Duration(2*time.Hour) {
+		t.Errorf("NewReport() author = %+v", owner)
+	}
+	if alice := report.Authors[0]; alice.Name != "alice" || alice.Comments != 2 || alice.Posts != 2 || alice.Secret != 0 {
+		t.Errorf("NewReport() author = %+v", alice)
+	}
+
+	month := NewReport(Between(posts, base, base.AddDate(0, 1, 0)))
+	if month.Comments != 4 || len(month.Posts) != 1 {
+		t.Errorf("NewReport(Between()) = %+v", month)
+	}
+	// The parent of a reply in the range is only used for its latency.
+	late := NewReport(Between(posts, base.Add(3*time.Hour), base.Add(6*time.Hour)))
+	if late.Comments != 1 || len(late.Posts) != 1 || late.Posts[0].Replies != 1 ||
+		late.Posts[0].AvgReplyLatency != Duration(3*time.Hour) || len(late.Authors) != 1 {
+		t.Errorf("NewReport(Between()) with parent out of range = %+v", late)
+	}
+
+	var buf bytes.Buffer
+	if err := WritePostsCSV(&buf, report.Posts); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 3 || rows[1][3] != "4" || rows[1][6] != "0.500" || rows[1][10] != "2h0m0s" {
+		t.Errorf("WritePostsCSV() = %v (%v)", rows, err)
+	}
+	buf.Reset()
+	if err := WriteCommentsCSV(&buf, posts); err != nil || strings.Count(buf.String(), "\n") != 6 {
+		t.Errorf("WriteCommentsCSV() = %q (%v)", buf.String(), err)
+	}
+	buf.Reset()
+	if err := WriteJSON(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Authors []map[string]interface{} `json:"authors"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded.Authors[1]["avgReplyLatency"] != "2h0m0s" {
+		t.Errorf("WriteJSON() = %s (%v)", buf.String(), err)
+	}
+}
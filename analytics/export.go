@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteJSON writes v (a Report, stats or comments) as indented JSON.
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// WritePostsCSV writes per-post statistics as CSV with a header row.
+func WritePostsCSV(w io.Writer, posts []PostStats) error {
+	rows := [][]string{{"postId", "title", "postUrl", "comments", "replies", "secret", "secretRatio",
+		"authors", "first", "last", "avgReplyLatency", "medianReplyLatency"}}
+	for _, p := range posts {
+		rows = append(rows, []string{
+			strconv.Itoa(p.PostId), p.Title, p.PostURL,
+			strconv.Itoa(p.Comments), strconv.Itoa(p.Replies), strconv.Itoa(p.Secret), formatRatio(p.SecretRatio),
+			strconv.Itoa(p.Authors), formatTime(p.First), formatTime(p.Last),
+			p.AvgReplyLatency.String(), p.MedianReplyLatency.String(),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteAuthorsCSV writes per-author statistics as CSV with a header row.
+func WriteAuthorsCSV(w io.Writer, authors []AuthorStats) error {
+	rows := [][]string{{"name", "comments", "replies", "posts", "secret", "secretRatio",
+		"first", "last", "avgReplyLatency", "medianReplyLatency"}}
+	for _, a := range authors {
+		rows = append(rows, []string{
+			a.Name, strconv.Itoa(a.Comments), strconv.Itoa(a.Replies), strconv.Itoa(a.Posts),
+			strconv.Itoa(a.Secret), formatRatio(a.SecretRatio), formatTime(a.First), formatTime(a.Last),
+			a.AvgReplyLatency.String(), a.MedianReplyLatency.String(),
+		})
+	}
+	return writeCSV(w, rows)
+}
+
+// WriteCommentsCSV writes every comment with its post as CSV with a header row.
+func WriteCommentsCSV(w io.Writer, posts []PostComments) error {
+	rows := [][]string{{"postId", "postTitle", "id", "parentId", "name", "homepage", "secret", "date", "content", "link"}}
+	for _, pc := range posts {
+		for _, c := range pc.Comments {
+			rows = append(rows, []string{
+				strconv.Itoa(pc.Post.Id), pc.Post.Title, strconv.Itoa(c.Id), strconv.Itoa(c.ParentId),
+				c.Name, c.Homepage, strconv.FormatBool(c.Secret), formatTime(c.Date), c.Content, c.Link,
+			})
+		}
+	}
+	return writeCSV(w, rows)
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func formatRatio(r float64) string {
+	return strconv.FormatFloat(r, 'f', 3, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,331 @@
+// Package autoreply answers common questions in the comments of a Tistory blog.
+//
+// A Responder polls GetRecentCommentList and replies to each new comment
+// matching one of its rules through WriteComment with parentId. Cooldowns
+// limit the replies per post and per comment author, and comments written by
+// the blog owner are never answered.
+//
+//	cfg, rules, err := autoreply.LoadRules(file)
+//	r := autoreply.New(blog, rules)
+//	r.PostCooldown = cfg.PostCooldown
+//	err = r.Run(ctx, time.Minute)
+package autoreply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// Reply is the outcome of a comment matched by a rule.
+type Reply struct {
+	Time    time.Time       `json:"time"`
+	Comment tistory.Comment `json:"comment"`
+	Rule    string          `json:"rule"`
+	Content string          `json:"content"`
+	DryRun  bool            `json:"dryRun"`
+	// Skipped names the cooldown that prevented the reply.
+	Skipped string `json:"skipped,omitempty"`
+	Posted  bool   `json:"posted"`
+	Error   string `json:"error,omitempty"`
+	// Attempts counts the failed tries of the reply, the last one included.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// State is the content of the state file.
+type State struct {
+	LastCommentId int                  `json:"lastCommentId"`
+	Posts         map[int]time.Time    `json:"posts"`
+	Authors       map[string]time.Time `json:"authors"`
+	// Failed counts the failed replies of the comments retried next poll.
+	Failed map[int]int `json:"failed,omitempty"`
+}
+
+// Responder replies to new comments matching Rules.
+type Responder struct {
+	Blog  *tistory.Tistory
+	Rules []Rule
+
+	// Owner is the blog nickname; the owner's comments, including the
+	// auto-replies, are never answered (default: the nickname of Blog.Info).
+	Owner string
+	// PostCooldown is the minimum time between auto-replies on the same post.
+	PostCooldown time.Duration
+	// AuthorCooldown is the minimum time between auto-replies to the same author.
+	AuthorCooldown time.Duration
+	// DryRun logs replies without writing them.
+	DryRun bool
+	// Log receives one JSON line per matched comment (nil: no log).
+	Log io.Writer
+	// ErrorLog receives the poll errors Run keeps going after (nil: no log).
+	ErrorLog io.Writer
+	// StatePath is the JSON file holding the last seen comment and the
+	// cooldowns ("": in memory only).
+	StatePath string
+	// MaxPages limits the comment/newest pages (10 comments each) read per poll.
+	MaxPages int
+	// MaxAttempts is the number of polls a failing reply is tried in before
+	// its comment is given up, e.g. on a deleted post (default: 3).
+	MaxAttempts int
+
+	state  State
+	loaded bool
+	now    func() time.Time
+}
+
+func New(blog *tistory.Tistory, rules []Rule) *Responder {
+	return &Responder{Blog: blog, Rules: rules, MaxPages: 5, MaxAttempts: 3}
+}
+
+/*
+Run polls every interval until ctx is done. A zero interval polls once and
+returns its error; otherwise a failed poll is written to ErrorLog and retried
+at the next interval.
+*/
+func (r *Responder) Run(ctx context.Context, interval time.Duration) error {
+	for {
+		_, err := r.Poll()
+		if interval <= 0 {
+			return err
+		}
+		if err != nil && r.ErrorLog != nil {
+			fmt.Fprintf(r.ErrorLog, "autoreply: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+/*
+Poll answers the comments written since the last poll, oldest first, and
+returns the replies for the matched ones. The first poll without a state
+file only records the newest comment, so old comments are not answered.
+A reply that fails to be written stops the poll; it and the comments after
+it are tried again on the next poll, up to MaxAttempts times.
+*/
+func (r *Responder) Poll() ([]Reply, error) {
+	first, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	if r.Owner == "" {
+		info, err := r.Blog.Info()
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to look up the blog owner")
+		}
+		r.Owner = info.Nickname
+	}
+	comments, err := r.newComments()
+	if err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return nil, nil
+	}
+
+	if first {
+		r.state.LastCommentId = comments[len(comments)-1].Id
+		return nil, r.save()
+	}
+	replies, err := r.respondAll(comments)
+	if saveErr := r.save(); err == nil {
+		err = saveErr
+	}
+	return replies, err
+}
+
+// respondAll answers comments in order and moves LastCommentId past each
+// comment answered, skipped, not matched or failed MaxAttempts times.
+func (r *Responder) respondAll(comments []tistory.Comment) ([]Reply, error) {
+	attempts := r.MaxAttempts
+	if attempts < 1 {
+		attempts = 3
+	}
+	var replies []Reply
+	for _, c := range comments {
+		reply, ok, err := r.respond(c)
+		if err != nil {
+			return replies, err
+		}
+		if ok && reply.Error != "" {
+			r.state.Failed[c.Id]++
+			reply.Attempts = r.state.Failed[c.Id]
+			if reply.Attempts < attempts {
+				// The cursor stays before c, so the reply is retried.
+				return append(replies, reply), r.log(reply)
+			}
+		}
+		delete(r.state.Failed, c.Id)
+		r.state.LastCommentId = c.Id
+		if !ok {
+			continue
+		}
+		replies = append(replies, reply)
+		if err := r.log(reply); err != nil {
+			return replies, err
+		}
+	}
+	return replies, nil
+}
+
+// respond replies to c with the first matching rule unless a cooldown applies.
+func (r *Responder) respond(c tistory.Comment) (Reply, bool, error) {
+	if c.Name == r.Owner {
+		return Reply{}, false, nil
+	}
+	for _, rule := range r.Rules {
+		if !rule.Match(c) {
+			continue
+		}
+		content, err := rule.Render(c, r.Blog.BlogName)
+		if err != nil {
+			return Reply{}, false, err
+		}
+		now := r.clock()
+		reply := Reply{Time: now, Comment: c, Rule: rule.Name, Content: content, DryRun: r.DryRun}
+		if last, ok := r.state.Posts[c.PostId]; ok && r.PostCooldown > 0 && now.Sub(last) < r.PostCooldown {
+			reply.Skipped = "post cooldown"
+			return reply, true, nil
+		}
+		if last, ok := r.state.Authors[c.Name]; ok && r.AuthorCooldown > 0 && now.Sub(last) < r.AuthorCooldown {
+			reply.Skipped = "author cooldown"
+			return reply, true, nil
+		}
+		if r.DryRun {
+			return reply, true, nil
+		}
+
+		parentId := c.Id
+		if c.ParentId != 0 {
+			// Tistory allows a single level of replies.
+			parentId = c.ParentId
+		}
+		secret := 0
+		if rule.Secret || c.Secret {
+			secret = 1
+		}
+		if _, err := r.Blog.WriteComment(map[string]interface{}{
+			"postId":   c.PostId,
+			"parentId": parentId,
+			"content":  content,
+			"secret":   secret,
+		}); err != nil {
+			reply.Error = err.Error()
+			return reply, true, nil
+		}
+		reply.Posted = true
+		r.state.Posts[c.PostId] = now
+		r.state.Authors[c.Name] = now
+		return reply, true, nil
+	}
+	return Reply{}, false, nil
+}
+
+// newComments returns the comments newer than the state, oldest first.
+func (r *Responder) newComments() ([]tistory.Comment, error) {
+	pages := r.MaxPages
+	if pages < 1 {
+		pages = 1
+	}
+
+	var comments []tistory.Comment
+	for page := 1; page <= pages; page++ {
+		result, err := r.Blog.GetRecentCommentList(page, 10)
+		if err != nil {
+			return nil, err
+		}
+		list, err := tistory.ParseComments(result)
+		if err != nil {
+			return nil, err
+		}
+		done := len(list) < 10
+		for _, c := range list {
+			if c.Id <= r.state.LastCommentId {
+				done = true
+				break
+			}
+			comments = append(comments, c)
+		}
+		if done {
+			break
+		}
+	}
+
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	return comments, nil
+}
+
+func (r *Responder) log(reply Reply) error {
+	if r.Log == nil {
+		return nil
+	}
+	return json.NewEncoder(r.Log).Encode(reply)
+}
+
+func (r *Responder) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// load reads the state file once and reports whether no state existed yet.
+func (r *Responder) load() (bool, error) {
+	if r.loaded {
+		return false, nil
+	}
+	r.loaded = true
+	r.state = State{Posts: map[int]time.Time{}, Authors: map[string]time.Time{}, Failed: map[int]int{}}
+	if r.StatePath == "" {
+		return true, nil
+	}
+	data, err := os.ReadFile(r.StatePath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to read autoreply state")
+	}
+	if err := json.Unmarshal(data, &r.state); err != nil {
+		return false, errors.Wrapf(err, "Failed to parse autoreply state (%s)", r.StatePath)
+	}
+	if r.state.Posts == nil {
+		r.state.Posts = map[int]time.Time{}
+	}
+	if r.state.Authors == nil {
+		r.state.Authors = map[string]time.Time{}
+	}
+	if r.state.Failed == nil {
+		r.state.Failed = map[int]int{}
+	}
+	return false, nil
+}
+
+func (r *Responder) save() error {
+	if r.StatePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.StatePath), 0o700); err != nil {
+		return errors.Wrap(err, "Failed to save autoreply state")
+	}
+	if err := os.WriteFile(r.StatePath, append(data, '\n'), 0o600); err != nil {
+		return errors.Wrap(err, "Failed to save autoreply state")
+	}
+	return nil
+}
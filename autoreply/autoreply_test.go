@@ -0,0 +1,223 @@
+package autoreply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+const testRules = `
+post_cooldown: 1h
+author_cooldown: 24h
+rules:
+  - name: shipping
+    keywords: ["배송", "Shipping"]
+    reply: "{{.Name}}님, 배송은 주문 후 2~3일 걸립니다."
+  - name: refund
+    regex: "환불.*(어떻게|방법)"
+    reply: "환불은 고객센터로 문의해 주세요."
+    secret: true
+`
+
+func TestLoadRules(t *testing.T) {
+	cfg, rules, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	if cfg.PostCooldown != time.Hour || cfg.AuthorCooldown != 24*time.Hour || len(rules) != 2 {
+		t.Errorf("LoadRules() = %+v, %d rules", cfg, len(rules))
+	}
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "배송 언제 되나요?", want: "shipping"},
+		{content: "free SHIPPING?", want: "shipping"},
+		{content: "환불은 어떻게 하나요", want: "refund"},
+		{content: "환불 안 해요", want: ""},
+		{content: "좋은 글 감사합니다", want: ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		for _, rule := range rules {
+			if rule.Match(tistory.Comment{Content: tt.content}) {
+				got = rule.Name
+				break
+			}
+		}
+		if got != tt.want {
+			t.Errorf("Rule.Match(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{
+		"rules:\n  - name: x\n    reply: hi\n",
+		"rules:\n  - name: x\n    keywords: [a]\n",
+		"rules:\n  - name: x\n    regex: '('\n    reply: hi\n",
+		"rules:\n  - name: x\n    keywords: [a]\n    reply: '{{.Name'\n",
+	} {
+		if _, _, err := LoadRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadRules(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestResponder_Poll(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	post1 := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "one"})
+	post2 := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "two"})
+	add := func(postId int, name, content string) int {
+		return srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: postId, Name: name, Content: content})
+	}
+	add(post1, "old", "배송 언제 돼요?")
+
+	_, rules, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var log bytes.Buffer
+	r := New(testblog.New(srv), rules)
+	r.PostCooldown = time.Hour
+	r.AuthorCooldown = 24 * time.Hour
+	r.Log = &log
+	r.now = func() time.Time { return now }
+
+	// The first poll skips existing comments.
+	if replies, err := r.Poll(); err != nil || len(replies) != 0 {
+		t.Fatalf("Responder.Poll() first = %+v, %v", replies, err)
+	}
+	if r.Owner != srv.Owner {
+		t.Errorf("Responder.Owner = %q, want %q", r.Owner, srv.Owner)
+	}
+
+	shipping := add(post1, "alice", "배송 언제 돼요?")
+	add(post1, "bob", "배송비는요?")        // post cooldown
+	add(post2, "alice", "환불은 어떻게 하나요") // author cooldown
+	refund := add(post2, "carol", "환불 방법 알려주세요")
+	add(post2, "dave", "잘 봤습니다")
+	replies, err := r.Poll()
+	if err != nil {
+		t.Fatalf("Responder.Poll() error = %v", err)
+	}
+	var summary []string
+	for _, reply := range replies {
+		summary = append(summary, reply.Comment.Name+":"+reply.Rule+":"+reply.Skipped)
+	}
+	want := "alice:shipping:,bob:shipping:post cooldown,alice:refund:author cooldown,carol:refund:"
+	if strings.Join(summary, ",") != want {
+		t.Errorf("Responder.Poll() = %v, want %v", summary, want)
+	}
+	if strings.Count(log.String(), "\n") != 4 {
+		t.Errorf("log = %q, want 4 lines", log.String())
+	}
+
+	written := map[int]tistorytest.Comment{}
+	for _, postId := range []int{post1, post2} {
+		for _, c := range srv.Comments(tistorytest.DefaultBlog, postId) {
+			if c.Name == srv.Owner {
+				written[c.ParentID] = c
+			}
+		}
+	}
+	if c := written[shipping]; c.Content != "alice님, 배송은 주문 후 2~3일 걸립니다." || c.Secret {
+		t.Errorf("reply to %d = %+v", shipping, c)
+	}
+	if c := written[refund]; c.Content != "환불은 고객센터로 문의해 주세요." || !c.Secret {
+		t.Errorf("reply to %d = %+v", refund, c)
+	}
+	if len(written) != 2 {
+		t.Errorf("written replies = %+v, want 2", written)
+	}
+
+	// The auto-replies themselves are not answered; cooldowns expire.
+	now = now.Add(2 * time.Hour)
+	add(post1, "bob", "배송 문의")
+	replies, err = r.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 1 || replies[0].Comment.Name != "bob" || !replies[0].Posted {
+		t.Errorf("Responder.Poll() after cooldown = %+v", replies)
+	}
+
+	// A failed reply is retried on the next poll, with the comments after it.
+	failing := &failWrites{RoundTripper: r.Blog.HTTPClient.Transport, on: true}
+	r.Blog.HTTPClient = &http.Client{Transport: failing}
+	erin := add(post2, "erin", "배송 언제 돼요?")
+	after := add(post2, "frank", "잘 봤습니다")
+	replies, err = r.Poll()
+	if err != nil || len(replies) != 1 || replies[0].Comment.Id != erin || replies[0].Error == "" || r.state.LastCommentId >= erin {
+		t.Errorf("Responder.Poll() with failed reply = %+v, %v, last comment %d", replies, err, r.state.LastCommentId)
+	}
+	failing.on = false
+	replies, err = r.Poll()
+	if err != nil || len(replies) != 1 || replies[0].Comment.Id != erin || !replies[0].Posted || r.state.LastCommentId != after {
+		t.Errorf("Responder.Poll() retry = %+v, %v, last comment %d", replies, err, r.state.LastCommentId)
+	}
+
+	// A reply that keeps failing is given up after MaxAttempts polls.
+	now = now.Add(48 * time.Hour)
+	failing.on = true
+	stuck := add(post1, "gina", "배송 문의")
+	for attempt := 1; attempt <= 3; attempt++ {
+		replies, err = r.Poll()
+		if err != nil || len(replies) != 1 || replies[0].Attempts != attempt {
+			t.Fatalf("Responder.Poll() attempt %d = %+v, %v", attempt, replies, err)
+		}
+	}
+	if r.state.LastCommentId != stuck || len(r.state.Failed) != 0 {
+		t.Errorf("after giving up last comment = %d, failed = %v, want %d", r.state.LastCommentId, r.state.Failed, stuck)
+	}
+	failing.on = false
+	next := add(post2, "hana", "배송 언제?")
+	if replies, err = r.Poll(); err != nil || len(replies) != 1 || replies[0].Comment.Id != next || !replies[0].Posted {
+		t.Errorf("Responder.Poll() after giving up = %+v, %v", replies, err)
+	}
+}
+
+// failWrites fails comment/write requests while on is set.
+type failWrites struct {
+	http.RoundTripper
+	on bool
+}
+
+func (f *failWrites) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f.on && strings.HasSuffix(req.URL.Path, "/comment/write") {
+		return nil, errors.New("connection reset")
+	}
+	return f.RoundTripper.RoundTrip(req)
+}
+
+func TestResponder_Run(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+	blog.AccessToken = "expired"
+	var log bytes.Buffer
+	r := New(blog, nil)
+	r.ErrorLog = &log
+
+	// A zero interval returns the error; a daemon logs it and keeps polling.
+	if err := r.Run(context.Background(), 0); err == nil {
+		t.Errorf("Responder.Run() once error = nil, want error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := r.Run(ctx, 10*time.Millisecond); err != nil {
+		t.Errorf("Responder.Run() error = %v", err)
+	}
+	if n := strings.Count(log.String(), "autoreply: "); n < 2 {
+		t.Errorf("ErrorLog = %q, want several errors", log.String())
+	}
+}
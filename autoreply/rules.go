@@ -0,0 +1,120 @@
+package autoreply
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule answers comments containing one of Keywords or matching Pattern.
+type Rule struct {
+	Name     string
+	Keywords []string // matched case-insensitively
+	Pattern  *regexp.Regexp
+	Reply    *template.Template
+	Secret   bool
+}
+
+// ReplyData is the data passed to a reply template.
+type ReplyData struct {
+	tistory.Comment
+	Blog string
+	Rule string
+}
+
+// Match reports whether the content of c matches r.
+func (r Rule) Match(c tistory.Comment) bool {
+	content := strings.ToLower(c.Content)
+	for _, keyword := range r.Keywords {
+		if keyword != "" && strings.Contains(content, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return r.Pattern != nil && r.Pattern.MatchString(c.Content)
+}
+
+// Render executes the reply template for c.
+func (r Rule) Render(c tistory.Comment, blog string) (string, error) {
+	var buf bytes.Buffer
+	if err := r.Reply.Execute(&buf, ReplyData{Comment: c, Blog: blog, Rule: r.Name}); err != nil {
+		return "", errors.Wrapf(err, "Failed to render reply %q", r.Name)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// RuleConfig is one rule of a rules file. The reply is a text/template
+// executed with ReplyData, e.g. "{{.Name}}님, 배송은 2~3일 걸립니다."
+type RuleConfig struct {
+	Name     string   `yaml:"name"`
+	Keywords []string `yaml:"keywords,omitempty"`
+	Regex    string   `yaml:"regex,omitempty"`
+	Reply    string   `yaml:"reply"`
+	Secret   bool     `yaml:"secret,omitempty"`
+}
+
+// RulesConfig is the content of a rules file (YAML or JSON).
+//
+//	post_cooldown: 1h
+//	author_cooldown: 24h
+//	rules:
+//	  - name: shipping
+//	    keywords: ["배송", "shipping"]
+//	    regex: "언제.*(도착|와요)"
+//	    reply: "{{.Name}}님, 배송은 주문 후 2~3일 걸립니다."
+type RulesConfig struct {
+	PostCooldown   time.Duration `yaml:"post_cooldown,omitempty"`
+	AuthorCooldown time.Duration `yaml:"author_cooldown,omitempty"`
+	Rules          []RuleConfig  `yaml:"rules"`
+}
+
+// LoadRules reads a rules file.
+func LoadRules(r io.Reader) (*RulesConfig, []Rule, error) {
+	var cfg RulesConfig
+	if err := yaml.NewDecoder(r).Decode(&cfg); err != nil && err != io.EOF {
+		return nil, nil, errors.Wrap(err, "Failed to parse rules")
+	}
+	rules, err := cfg.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cfg, rules, nil
+}
+
+// Build converts the configuration into rules.
+func (c RulesConfig) Build() ([]Rule, error) {
+	rules := make([]Rule, 0, len(c.Rules))
+	for i, rc := range c.Rules {
+		if rc.Name == "" {
+			return nil, errors.Errorf("rule %d has no name", i+1)
+		}
+		if len(rc.Keywords) == 0 && rc.Regex == "" {
+			return nil, errors.Errorf("rule %q needs keywords or regex", rc.Name)
+		}
+		if strings.TrimSpace(rc.Reply) == "" {
+			return nil, errors.Errorf("rule %q has no reply", rc.Name)
+		}
+
+		rule := Rule{Name: rc.Name, Keywords: rc.Keywords, Secret: rc.Secret}
+		if rc.Regex != "" {
+			re, err := regexp.Compile(rc.Regex)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %q", rc.Name)
+			}
+			rule.Pattern = re
+		}
+		tmpl, err := template.New(rc.Name).Parse(rc.Reply)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %q", rc.Name)
+		}
+		rule.Reply = tmpl
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
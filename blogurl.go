@@ -0,0 +1,99 @@
+package tistory
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const tistoryDomain = ".tistory.com"
+
+/*
+parseBlogURL returns the origin (scheme://host) of blogURL and, for
+*.tistory.com hosts, the blog name. Paths, queries and fragments are ignored,
+so https://myblog.tistory.com/123 yields ("https://myblog.tistory.com", "myblog").
+A custom domain yields an empty name.
+*/
+func parseBlogURL(blogURL string) (origin, name string, err error) {
+	u, err := url.Parse(strings.TrimSpace(blogURL))
+	if err != nil {
+		return "", "", errors.Wrap(err, "blogURL is invalid")
+	}
+	if u.Scheme != "https" || u.Hostname() == "" {
+		return "", "", errors.New("blogURL is invalid (want https://<name>.tistory.com or a custom domain)")
+	}
+
+	host := strings.ToLower(u.Hostname())
+	origin = "https://" + strings.ToLower(u.Host)
+	if !strings.HasSuffix(host, tistoryDomain) {
+		return origin, "", nil
+	}
+
+	name = strings.TrimSuffix(host, tistoryDomain)
+	if name == "" || name == "www" || strings.Contains(name, ".") {
+		return "", "", errors.Errorf("blogURL is invalid (%s is not a blog)", host)
+	}
+	return origin, name, nil
+}
+
+/*
+ResolveBlogName finds the blog whose url or secondaryUrl has the host of
+BlogURL among the account's blogs (blog/info) and stores its name in BlogName.
+It needs an access token and is called automatically when BlogName is empty.
+*/
+func (t *Tistory) ResolveBlogName() (string, error) {
+	origin, name, err := parseBlogURL(t.BlogURL)
+	if err != nil {
+		return "", err
+	}
+	if name != "" {
+		t.BlogName = name
+		return name, nil
+	}
+
+	result, err := t.GetBlogInfo()
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to ResolveBlogName")
+	}
+	blogs, err := parseBlogs(result)
+	if err != nil {
+		return "", err
+	}
+
+	host := strings.TrimPrefix(origin, "https://")
+	for _, b := range blogs {
+		if sameHost(b.URL, host) || sameHost(b.SecondaryURL, host) {
+			t.BlogName = b.Name
+			return b.Name, nil
+		}
+	}
+	return "", errors.Errorf("Failed to ResolveBlogName (no blog of this account uses %s)", host)
+}
+
+func (t *Tistory) resolveBlogName() error {
+	if t.BlogName != "" {
+		return nil
+	}
+	if t.BlogURL == "" {
+		return errors.New("BlogName and BlogURL are empty")
+	}
+	_, err := t.ResolveBlogName()
+	return err
+}
+
+// sameHost reports whether blogURL (as returned by blog/info, possibly
+// without a scheme) points at host.
+func sameHost(blogURL, host string) bool {
+	if blogURL == "" {
+		return false
+	}
+	if !strings.Contains(blogURL, "://") {
+		blogURL = "https://" + blogURL
+	}
+	u, err := url.Parse(blogURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, host)
+}
@@ -0,0 +1,77 @@
+package tistory
+
+import (
+	"testing"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestNewTistory_BlogURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		blogURL  string
+		wantURL  string
+		wantBlog string
+		wantErr  bool
+	}{
+		{name: "tistory domain", blogURL: "https://myblog.tistory.com", wantURL: "https://myblog.tistory.com", wantBlog: "myblog"},
+		{name: "with path", blogURL: "https://MyBlog.tistory.com/123?category=1", wantURL: "https://myblog.tistory.com", wantBlog: "myblog"},
+		{name: "trailing slash", blogURL: "https://myblog.tistory.com/", wantURL: "https://myblog.tistory.com", wantBlog: "myblog"},
+		{name: "custom domain", blogURL: "https://blog.example.com/entry/hello", wantURL: "https://blog.example.com", wantBlog: ""},
+		{name: "http", blogURL: "http://myblog.tistory.com", wantErr: true},
+		{name: "no scheme", blogURL: "myblog.tistory.com", wantErr: true},
+		{name: "tistory home", blogURL: "https://www.tistory.com", wantErr: true},
+		{name: "empty", blogURL: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTistory(tt.blogURL, "client_id", "client_secret")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.BlogURL != tt.wantURL || got.BlogName != tt.wantBlog {
+				t.Errorf("NewTistory() = (%v, %v), want (%v, %v)", got.BlogURL, got.BlogName, tt.wantURL, tt.wantBlog)
+			}
+		})
+	}
+
+	got, err := NewTistoryWithBlogName("https://blog.example.com", "explicit", "client_id", "client_secret")
+	if err != nil || got.BlogName != "explicit" {
+		t.Errorf("NewTistoryWithBlogName() = %v, %v", got, err)
+	}
+}
+
+func TestTistory_ResolveBlogName(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	srv.AddBlog("company")
+	srv.SetSecondaryURL("company", "http://blog.example.com")
+	srv.AddPost("company", tistorytest.Post{Title: "custom domain post"})
+
+	tst, err := NewTistory("https://blog.example.com/entry/1", "client_id", "client_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tst.AccessToken = srv.AccessToken
+	tst.BaseURL = srv.URL
+	tst.HTTPClient = srv.Client()
+
+	// The name is resolved on the first blog API call.
+	if _, err := tst.GetPostList(1); err != nil {
+		t.Fatalf("Tistory.GetPostList() error = %v", err)
+	}
+	if tst.BlogName != "company" {
+		t.Errorf("BlogName = %q, want company", tst.BlogName)
+	}
+
+	unknown, _ := NewTistory("https://other.example.com", "client_id", "client_secret")
+	unknown.AccessToken = srv.AccessToken
+	unknown.BaseURL = srv.URL
+	unknown.HTTPClient = srv.Client()
+	if _, err := unknown.ResolveBlogName(); err == nil {
+		t.Errorf("ResolveBlogName() for a foreign domain error = nil, want error")
+	}
+}
@@ -0,0 +1,254 @@
+package tistory
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PostSelector picks the posts of post/list to read for a bulk operation.
+type PostSelector func(p PostSummary) bool
+
+// PostMutator changes p in place and reports whether it changed.
+// Unchanged posts are not written back.
+type PostMutator func(p *Post) (bool, error)
+
+// BulkOptions configure BulkModify. The zero value is usable.
+type BulkOptions struct {
+	// Workers is the number of posts processed concurrently (default: 4).
+	Workers int
+	// Interval is the minimum time between two API requests of all workers
+	// together, to stay below the rate limit (0: no limit).
+	Interval time.Duration
+	// DryRun runs the mutator without writing posts back.
+	DryRun bool
+	// Rollback receives the original of every post before it is modified,
+	// one JSON object per line; RestorePosts writes them back.
+	Rollback io.Writer
+	// Progress is called after each selected post, one call at a time.
+	Progress func(BulkProgress)
+}
+
+// BulkResult is the outcome for one post.
+type BulkResult struct {
+	PostId  int
+	Title   string
+	Changed bool
+	Err     error
+}
+
+// BulkProgress reports the state of a bulk operation after Result.
+type BulkProgress struct {
+	Done    int
+	Total   int
+	Changed int
+	Failed  int
+	Result  BulkResult
+}
+
+/*
+BulkModify 글 일괄 수정
+GetPostList 로 모든 글을 읽어 selector 가 고른 글을 GetPost 로 가져오고,
+mutator 로 바꾼 글만 ModifyPost 로 저장합니다. 결과는 post/list 순서로 반환합니다.
+Failed posts are reported in their result; the error is for listing posts
+or writing the rollback file. Canceling ctx stops before the next post.
+*/
+func (t *Tistory) BulkModify(ctx context.Context, selector PostSelector, mutator PostMutator, options *BulkOptions) ([]BulkResult, error) {
+	if options == nil {
+		options = &BulkOptions{}
+	}
+	limit := newRateLimiter(options.Interval)
+	defer limit.stop()
+
+	if err := limit.wait(ctx); err != nil {
+		return nil, err
+	}
+	all, err := t.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+	var selected []PostSummary
+	for _, p := range all {
+		if selector == nil || selector(p) {
+			selected = append(selected, p)
+		}
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+
+	// A rollback write error stops the remaining posts.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]BulkResult, len(selected))
+	processed := make([]bool, len(selected))
+	jobs := make(chan int)
+	var (
+		mu          sync.Mutex
+		progress    = BulkProgress{Total: len(selected)}
+		rollbackErr error
+		wg          sync.WaitGroup
+	)
+	rollback := func(p Post) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if rollbackErr != nil {
+			return rollbackErr
+		}
+		if err := json.NewEncoder(options.Rollback).Encode(p); err != nil {
+			rollbackErr = errors.Wrap(err, "Failed to write rollback")
+			cancel()
+		}
+		return rollbackErr
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := t.bulkModifyPost(runCtx, limit, selected[i], mutator, options, rollback)
+
+				mu.Lock()
+				results[i], processed[i] = result, true
+				progress.Done++
+				if result.Changed {
+					progress.Changed++
+				}
+				if result.Err != nil {
+					progress.Failed++
+				}
+				progress.Result = result
+				if options.Progress != nil {
+					options.Progress(progress)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range selected {
+		if runCtx.Err() != nil {
+			break
+		}
+		select {
+		case jobs <- i:
+		case <-runCtx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	done := make([]BulkResult, 0, progress.Done)
+	for i, result := range results {
+		if processed[i] {
+			done = append(done, result)
+		}
+	}
+	if rollbackErr != nil {
+		return done, rollbackErr
+	}
+	return done, ctx.Err()
+}
+
+func (t *Tistory) bulkModifyPost(ctx context.Context, limit *rateLimiter, summary PostSummary, mutator PostMutator,
+	options *BulkOptions, rollback func(Post) error) BulkResult {
+	result := BulkResult{PostId: summary.Id, Title: summary.Title}
+	if err := limit.wait(ctx); err != nil {
+		result.Err = err
+		return result
+	}
+	post, err := t.ReadPost(summary.Id)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	original := post
+	original.Tags = append([]string(nil), post.Tags...)
+
+	changed, err := mutator(&post)
+	if err != nil || !changed {
+		result.Err = err
+		return result
+	}
+	result.Changed = true
+	result.Title = post.Title
+	if options.DryRun {
+		return result
+	}
+
+	if options.Rollback != nil {
+		if err := rollback(original); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	if err := limit.wait(ctx); err != nil {
+		result.Err = err
+		return result
+	}
+	// The snapshot of Revisions reuses original, so the modification is the
+	// only request after this wait.
+	if _, err := t.modifyPost(post.ModifyOption(), &original); err != nil {
+		result.Err = err
+	}
+	return result
+}
+
+/*
+RestorePosts 일괄 수정 되돌리기
+BulkModify 의 rollback 파일을 읽어 각 글을 원래 값으로 ModifyPost 합니다.
+*/
+func (t *Tistory) RestorePosts(r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	restored := 0
+	for {
+		var post Post
+		if err := dec.Decode(&post); err == io.EOF {
+			return restored, nil
+		} else if err != nil {
+			return restored, errors.Wrap(err, "Failed to read rollback")
+		}
+		if _, err := t.ModifyPost(post.ModifyOption()); err != nil {
+			return restored, errors.Wrapf(err, "Failed to restore post %d", post.Id)
+		}
+		restored++
+	}
+}
+
+// rateLimiter spaces requests at least interval apart.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(interval time.Duration) *rateLimiter {
+	if interval <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{ticker: time.NewTicker(interval)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) stop() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
@@ -0,0 +1,153 @@
+package tistory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestTistory_BulkModify(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	oldCategory := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "old"})
+	newCategory := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "new"})
+	var ids []int
+	published := time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 12; i++ {
+		category := oldCategory
+		if i%3 == 0 {
+			category = newCategory
+		}
+		tags := []string{"go"}
+		if i == 1 {
+			tags = nil
+		}
+		ids = append(ids, srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{
+			Title: fmt.Sprintf("post %d", i), Content: "<p>body</p>", Visibility: 3, CategoryID: category, Tags: tags,
+			Published: published.AddDate(0, 0, i)}))
+	}
+
+	var rollback bytes.Buffer
+	var calls []BulkProgress
+	results, err := tst.BulkModify(context.Background(),
+		func(p PostSummary) bool { return p.CategoryId == oldCategory },
+		func(p *Post) (bool, error) {
+			if len(p.Tags) == 0 {
+				return false, nil
+			}
+			p.CategoryId = newCategory
+			p.Tags = append(p.Tags, "moved")
+			return true, nil
+		},
+		&BulkOptions{Workers: 3, Interval: time.Millisecond, Rollback: &rollback, Progress: func(p BulkProgress) { calls = append(calls, p) }})
+	if err != nil {
+		t.Fatalf("Tistory.BulkModify() error = %v", err)
+	}
+	if len(results) != 8 || len(calls) != 8 {
+		t.Fatalf("Tistory.BulkModify() = %d results, %d progress calls, want 8", len(results), len(calls))
+	}
+	last := calls[len(calls)-1]
+	if last.Done != 8 || last.Total != 8 || last.Changed != 7 || last.Failed != 0 {
+		t.Errorf("Tistory.BulkModify() progress = %+v", last)
+	}
+
+	for i, id := range ids {
+		post, _ := srv.Post(tistorytest.DefaultBlog, id)
+		moved := i%3 != 0 && i != 1
+		if moved != (strings.Join(post.Tags, ",") == "go,moved") || post.Content != "<p>body</p>" ||
+			!post.Published.Equal(published.AddDate(0, 0, i)) {
+			t.Errorf("post %d = %+v, moved %v", i, post, moved)
+		}
+	}
+	if n := strings.Count(rollback.String(), "\n"); n != 7 {
+		t.Errorf("rollback lines = %d, want 7", n)
+	}
+
+	restored, err := tst.RestorePosts(&rollback)
+	if err != nil || restored != 7 {
+		t.Fatalf("Tistory.RestorePosts() = %d, %v", restored, err)
+	}
+	for i, id := range ids {
+		post, _ := srv.Post(tistorytest.DefaultBlog, id)
+		if i != 1 && strings.Join(post.Tags, ",") != "go" || i%3 != 0 && post.CategoryID != oldCategory ||
+			!post.Published.Equal(published.AddDate(0, 0, i)) {
+			t.Errorf("restored post %d = %+v", i, post)
+		}
+	}
+
+	// Dry run and cancellation do not write.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tst.BulkModify(ctx, nil, func(p *Post) (bool, error) { p.Title = "x"; return true, nil }, nil); err == nil {
+		t.Errorf("Tistory.BulkModify() with canceled context error = nil, want error")
+	}
+	results, err = tst.BulkModify(context.Background(), nil, func(p *Post) (bool, error) { p.Title = "x"; return true, nil }, &BulkOptions{DryRun: true})
+	if err != nil || len(results) != 12 || !results[0].Changed {
+		t.Errorf("Tistory.BulkModify() dry run = %+v, %v", results, err)
+	}
+	if post, _ := srv.Post(tistorytest.DefaultBlog, ids[0]); post.Title != "post 0" {
+		t.Errorf("dry run modified post: %+v", post)
+	}
+}
+
+func TestTistory_BulkModifyRevisions(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+	tst.Revisions = NewFileRevisionStore(t.TempDir())
+	reads := &countingTransport{RoundTripper: srv.Client().Transport, path: "/apis/post/read"}
+	tst.HTTPClient = &http.Client{Transport: reads}
+
+	for i := 0; i < 3; i++ {
+		srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: fmt.Sprintf("post %d", i), Visibility: 3})
+	}
+	results, err := tst.BulkModify(context.Background(), nil, func(p *Post) (bool, error) {
+		p.Title += " edited"
+		return true, nil
+	}, &BulkOptions{Workers: 2})
+	if err != nil || len(results) != 3 {
+		t.Fatalf("Tistory.BulkModify() = %+v, %v", results, err)
+	}
+	// The revision snapshot reuses the post read by BulkModify.
+	if n := reads.count(); n != 3 {
+		t.Errorf("post/read requests = %d, want 3", n)
+	}
+	for _, r := range results {
+		revisions, err := tst.PostRevisions(r.PostId)
+		if err != nil || len(revisions) != 1 || strings.HasSuffix(revisions[0].Post.Title, "edited") {
+			t.Errorf("revisions of post %d = %+v, %v", r.PostId, revisions, err)
+		}
+	}
+}
+
+// countingTransport counts the requests to path.
+type countingTransport struct {
+	http.RoundTripper
+	path string
+	mu   sync.Mutex
+	n    int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == c.path {
+		c.mu.Lock()
+		c.n++
+		c.mu.Unlock()
+	}
+	return c.RoundTripper.RoundTrip(req)
+}
+
+func (c *countingTransport) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
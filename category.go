@@ -0,0 +1,51 @@
+package tistory
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Category is one entry of category/list.
+type Category struct {
+	Id      int
+	Name    string
+	Parent  int // 0 for a top-level category
+	Label   string
+	Entries int
+}
+
+// ParseCategories converts a category/list response into Categories.
+func ParseCategories(result map[string]interface{}) ([]Category, error) {
+	item, err := responseItem(result)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse category/list")
+	}
+	list, _ := item["categories"].([]interface{})
+
+	categories := make([]Category, 0, len(list))
+	for _, entry := range list {
+		c, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		categories = append(categories, Category{
+			Id:      intField(c, "id"),
+			Name:    stringField(c, "name"),
+			Parent:  intField(c, "parent"),
+			Label:   stringField(c, "label"),
+			Entries: intField(c, "entries"),
+		})
+	}
+	return categories, nil
+}
+
+/*
+Categories 카테고리 목록
+CategoryList 결과를 Category 로 반환합니다.
+*/
+func (t *Tistory) Categories() ([]Category, error) {
+	result, err := t.CategoryList()
+	if err != nil {
+		return nil, err
+	}
+	return ParseCategories(result)
+}
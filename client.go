@@ -0,0 +1,168 @@
+package tistory
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Client holds an access token and hands out a Tistory handle per blog.
+// One token can access every blog the account owns.
+type Client struct {
+	ClientId     string
+	ClientSecret string
+	AccessToken  string
+
+	// BaseURL overrides the Tistory API host (default: https://www.tistory.com).
+	BaseURL string
+	// HTTPClient is used for API requests (default: http.DefaultClient).
+	HTTPClient *http.Client
+	// Revisions is passed on to the blog handles (see Tistory.Revisions).
+	Revisions RevisionStore
+	// AttachProcessor is passed on to the blog handles (see Tistory.AttachProcessor).
+	AttachProcessor AttachProcessor
+}
+
+// BlogInfo is one blog returned by blog/info.
+type BlogInfo struct {
+	Name         string
+	URL          string
+	SecondaryURL string
+	Nickname     string
+	Title        string
+	Description  string
+	Role         string
+	BlogId       string
+	Default      bool
+}
+
+func NewClient(accessToken string) *Client {
+	return &Client{AccessToken: accessToken}
+}
+
+// Client returns a Client sharing the credentials of t.
+func (t *Tistory) Client() *Client {
+	return &Client{
+		ClientId:        t.ClientId,
+		ClientSecret:    t.ClientSecret,
+		AccessToken:     t.AccessToken,
+		BaseURL:         t.BaseURL,
+		HTTPClient:      t.HTTPClient,
+		Revisions:       t.Revisions,
+		AttachProcessor: t.AttachProcessor,
+	}
+}
+
+/*
+Blog returns a handle for the blog named name (e.g. "myblog" for myblog.tistory.com).
+The handle copies the client's access token.
+*/
+func (c *Client) Blog(name string) *Tistory {
+	return c.blog(name, fmt.Sprintf("https://%s.tistory.com", name))
+}
+
+func (c *Client) blog(name, blogURL string) *Tistory {
+	return &Tistory{
+		BlogURL:         blogURL,
+		BlogName:        name,
+		ClientId:        c.ClientId,
+		ClientSecret:    c.ClientSecret,
+		AccessToken:     c.AccessToken,
+		BaseURL:         c.BaseURL,
+		HTTPClient:      c.HTTPClient,
+		Revisions:       c.Revisions,
+		AttachProcessor: c.AttachProcessor,
+	}
+}
+
+/*
+Blogs 계정의 블로그 목록
+https://tistory.github.io/document-tistory-apis/apis/v1/blog/list.html
+*/
+func (c *Client) Blogs() ([]BlogInfo, error) {
+	result, err := c.blog("", "").GetBlogInfo()
+	if err != nil {
+		return nil, err
+	}
+	return parseBlogs(result)
+}
+
+// DefaultBlog returns a handle for the account's default (대표) blog.
+func (c *Client) DefaultBlog() (*Tistory, error) {
+	blogs, err := c.Blogs()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blogs {
+		if b.Default {
+			return c.blog(b.Name, b.URL), nil
+		}
+	}
+	if len(blogs) > 0 {
+		return c.blog(blogs[0].Name, blogs[0].URL), nil
+	}
+	return nil, errors.New("Failed to DefaultBlog (the account has no blogs)")
+}
+
+// Info returns the blog/info entry of t's blog. Its Nickname is the name
+// shown on comments written by the blog owner.
+func (t *Tistory) Info() (BlogInfo, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return BlogInfo{}, err
+	}
+	blogs, err := t.Client().Blogs()
+	if err != nil {
+		return BlogInfo{}, err
+	}
+	for _, b := range blogs {
+		if b.Name == t.BlogName {
+			return b, nil
+		}
+	}
+	return BlogInfo{}, errors.Errorf("Failed to Info (blog %q is not owned by this account)", t.BlogName)
+}
+
+// parseBlogs converts a blog/info response into BlogInfo values.
+func parseBlogs(result map[string]interface{}) ([]BlogInfo, error) {
+	item, err := responseItem(result)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse blog/info")
+	}
+	list, ok := item["blogs"].([]interface{})
+	if !ok {
+		return nil, errors.New("Failed to parse blog/info (missing tistory.item.blogs)")
+	}
+
+	blogs := make([]BlogInfo, 0, len(list))
+	for _, entry := range list {
+		b, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		blogs = append(blogs, BlogInfo{
+			Name:         stringField(b, "name"),
+			URL:          stringField(b, "url"),
+			SecondaryURL: stringField(b, "secondaryUrl"),
+			Nickname:     stringField(b, "nickname"),
+			Title:        stringField(b, "title"),
+			Description:  stringField(b, "description"),
+			Role:         stringField(b, "role"),
+			BlogId:       stringField(b, "blogId"),
+			Default:      stringField(b, "default") == "Y",
+		})
+	}
+	return blogs, nil
+}
+
+// stringField returns m[key] as a string; Tistory encodes numbers as strings.
+func stringField(m map[string]interface{}, key string) string {
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
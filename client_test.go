@@ -0,0 +1,72 @@
+package tistory
+
+import (
+	"testing"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func newTestClient(srv *tistorytest.Server) *Client {
+	c := NewClient(srv.AccessToken)
+	c.BaseURL = srv.URL
+	c.HTTPClient = srv.Client()
+	return c
+}
+
+func TestClient_Blogs(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	srv.AddBlog("second")
+
+	c := newTestClient(srv)
+	blogs, err := c.Blogs()
+	if err != nil {
+		t.Fatalf("Client.Blogs() error = %v", err)
+	}
+	if len(blogs) != 2 || blogs[0].Name != tistorytest.DefaultBlog || !blogs[0].Default || blogs[1].Default {
+		t.Errorf("Client.Blogs() = %+v", blogs)
+	}
+
+	def, err := c.DefaultBlog()
+	if err != nil {
+		t.Fatalf("Client.DefaultBlog() error = %v", err)
+	}
+	if def.BlogName != tistorytest.DefaultBlog || def.BlogURL != blogs[0].URL {
+		t.Errorf("Client.DefaultBlog() = %+v", def)
+	}
+
+	c.AccessToken = "invalid"
+	if _, err := c.Blogs(); err == nil {
+		t.Errorf("Client.Blogs() with invalid token error = nil, want error")
+	}
+}
+
+func TestClient_Blog(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	srv.AddBlog("second")
+	c := newTestClient(srv)
+
+	if _, err := c.Blog("second").WritePost(map[string]interface{}{"title": "second post"}); err != nil {
+		t.Fatalf("Blog(second).WritePost() error = %v", err)
+	}
+	if got := srv.Posts("second"); len(got) != 1 {
+		t.Errorf("posts of second = %v, want 1", got)
+	}
+	if got := srv.Posts(tistorytest.DefaultBlog); len(got) != 0 {
+		t.Errorf("posts of %s = %v, want none", tistorytest.DefaultBlog, got)
+	}
+	if _, err := c.Blog("missing").CategoryList(); err == nil {
+		t.Errorf("Blog(missing).CategoryList() error = nil, want error")
+	}
+
+	tst := newTestTistory(srv)
+	tst.AttachProcessor = jpegProcessor{}
+	if got := tst.Client().Blog("second"); got.AccessToken != srv.AccessToken || got.BaseURL != srv.URL ||
+		got.AttachProcessor != tst.AttachProcessor {
+		t.Errorf("Tistory.Client().Blog() = %+v", got)
+	}
+	if got, err := tst.Client().DefaultBlog(); err != nil || got.AttachProcessor != tst.AttachProcessor {
+		t.Errorf("Tistory.Client().DefaultBlog() = %+v, %v", got, err)
+	}
+}
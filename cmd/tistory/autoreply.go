@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/LimJiAn/tistory-go/autoreply"
+	"github.com/pkg/errors"
+)
+
+// autoreply answers new comments matching a rules file, once or every --interval.
+func (a *app) autoreply(args []string) error {
+	fs := a.newFlagSet("autoreply")
+	rulesFile := fs.String("rules", "", "rules file (YAML or JSON, required)")
+	dryRun := fs.Bool("dry-run", false, "log replies without writing them")
+	interval := fs.Duration("interval", 0, "poll interval (default: poll once)")
+	statePath := fs.String("state", "", "state file with the last seen comment and cooldowns (default: next to the config file)")
+	postCooldown := fs.Duration("post-cooldown", 0, "minimum time between replies on a post (default: from the rules file)")
+	authorCooldown := fs.Duration("author-cooldown", 0, "minimum time between replies to an author (default: from the rules file)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rulesFile == "" {
+		return errors.New("--rules is required")
+	}
+
+	f, err := os.Open(*rulesFile)
+	if err != nil {
+		return err
+	}
+	rc, rules, err := autoreply.LoadRules(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	t, err := profileTistory(cfg, p)
+	if err != nil {
+		return err
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(filepath.Dir(cfg.Path()), "state", "autoreply-"+p.Name+".json")
+	}
+
+	r := autoreply.New(t, rules)
+	r.DryRun = *dryRun
+	r.StatePath = *statePath
+	r.Log = a.stdout
+	r.ErrorLog = a.stderr
+	r.PostCooldown = rc.PostCooldown
+	r.AuthorCooldown = rc.AuthorCooldown
+	if *postCooldown > 0 {
+		r.PostCooldown = *postCooldown
+	}
+	if *authorCooldown > 0 {
+		r.AuthorCooldown = *authorCooldown
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return r.Run(ctx, *interval)
+}
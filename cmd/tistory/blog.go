@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/LimJiAn/tistory-go/config"
+	"github.com/pkg/errors"
+)
+
+// login runs the Kakao login flow and stores the access token in the profile.
+func (a *app) login(args []string) error {
+	fs := a.newFlagSet("login")
+	id := fs.String("id", os.Getenv("KAKAO_ID"), "Kakao account (env KAKAO_ID)")
+	password := fs.String("password", os.Getenv("KAKAO_PASSWORD"), "Kakao password (env KAKAO_PASSWORD)")
+	code := fs.String("code", "", "use this authorization code instead of logging in with a browser")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	t, err := newTistory(p)
+	if err != nil {
+		return err
+	}
+
+	if *code != "" {
+		t.AuthenticationCode = *code
+	} else if _, err := t.GetAuthorizationCode(*id, *password); err != nil {
+		return err
+	}
+	if _, err := t.GetAccessToken(); err != nil {
+		return err
+	}
+
+	if err := config.SaveToken(p.TokenPath(), t.AccessToken); err != nil {
+		return err
+	}
+	// Remember a profile given only through flags and the environment.
+	if _, ok := cfg.Profiles[p.Name]; !ok {
+		// Keep secrets from the environment out of the file.
+		if p.ClientID == os.Getenv("CLIENT_ID") {
+			p.ClientID = "env:CLIENT_ID"
+		}
+		if p.ClientSecret == os.Getenv("CLIENT_SECRET") {
+			p.ClientSecret = "env:CLIENT_SECRET"
+		}
+		cfg.Set(p)
+		if err := cfg.Save(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(a.stdout, "Logged in to %s (profile %q)\n", p.BlogURL, p.Name)
+	return nil
+}
+
+// whoami prints the account and the blogs it owns.
+func (a *app) whoami(args []string) error {
+	fs := a.newFlagSet("whoami")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.GetBlogInfo()
+	if err != nil {
+		return err
+	}
+	it, err := item(result)
+	if err != nil {
+		return err
+	}
+
+	if out.output == "table" && out.format == "" {
+		fmt.Fprintf(a.stdout, "%v\n", it["id"])
+	}
+	return a.printRecords(out, []string{"name", "url", "title", "default", "role"}, records(it["blogs"]))
+}
+
+// profiles lists the profiles of the config file.
+func (a *app) profiles(args []string) error {
+	fs := a.newFlagSet("profiles")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	current := cfg.ProfileName(a.profile)
+	rows := make([]map[string]interface{}, 0, len(cfg.Profiles))
+	for _, name := range cfg.Names() {
+		p := cfg.Profiles[name]
+		_, tokenErr := config.LoadToken(p.TokenPath())
+		rows = append(rows, map[string]interface{}{
+			"name":       name,
+			"blogUrl":    p.BlogURL,
+			"current":    name == current,
+			"loggedIn":   tokenErr == nil,
+			"tokenStore": p.TokenPath(),
+		})
+	}
+	return a.printRecords(out, []string{"name", "blogUrl", "current", "loggedIn"}, rows)
+}
+
+// attach uploads each file and prints the replacer to paste into post content.
+func (a *app) attach(args []string) error {
+	fs := a.newFlagSet("attach")
+	images := addImageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: tistory attach [flags] <file>...")
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	images.apply(t)
+	for _, file := range fs.Args() {
+		result, err := t.AttachPost(file)
+		if err != nil {
+			return err
+		}
+		b, err := body(result)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stdout, "%s\t%v\t%v\n", file, b["url"], b["replacer"])
+	}
+	return nil
+}
+
+// categories lists the categories of the blog.
+func (a *app) categories(args []string) error {
+	fs := a.newFlagSet("categories")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.CategoryList()
+	if err != nil {
+		return err
+	}
+	it, err := item(result)
+	if err != nil {
+		return err
+	}
+	return a.printRecords(out, []string{"id", "name", "parent", "label", "entries"}, records(it["categories"]))
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"syscall"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// postsBulk modifies every post matching the --select flags.
+func (a *app) postsBulk(args []string) error {
+	fs := a.newFlagSet("posts bulk")
+	selectCategory := fs.Int("select-category", 0, "only posts of this category ID")
+	selectTag := fs.String("select-tag", "", "only posts with this tag")
+	selectTitle := fs.String("select-title", "", "only posts whose title matches this regular expression")
+	setCategory := fs.Int("set-category", 0, "move the posts to this category ID")
+	setVisibility := fs.Int("set-visibility", -1, "set visibility (0: private, 1: protected, 3: public)")
+	addTag := fs.String("add-tag", "", "comma separated tags to add")
+	removeTag := fs.String("remove-tag", "", "comma separated tags to remove")
+	bf := addBulkFlags(fs)
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var titlePattern *regexp.Regexp
+	if *selectTitle != "" {
+		var err error
+		if titlePattern, err = regexp.Compile(*selectTitle); err != nil {
+			return errors.Wrap(err, "invalid --select-title")
+		}
+	}
+	adds, removes := splitList(*addTag), splitList(*removeTag)
+	if *setCategory == 0 && *setVisibility < 0 && len(adds) == 0 && len(removes) == 0 {
+		return errors.New("nothing to change (use --set-category, --set-visibility, --add-tag or --remove-tag)")
+	}
+
+	selector := func(p tistory.PostSummary) bool {
+		return (*selectCategory == 0 || p.CategoryId == *selectCategory) &&
+			(titlePattern == nil || titlePattern.MatchString(p.Title))
+	}
+	mutator := func(p *tistory.Post) (bool, error) {
+		if *selectTag != "" && !containsTag(p.Tags, *selectTag) {
+			return false, nil
+		}
+		changed := false
+		if *setCategory != 0 && p.CategoryId != *setCategory {
+			p.CategoryId, changed = *setCategory, true
+		}
+		if *setVisibility >= 0 && tistory.WriteVisibility(p.Visibility) != *setVisibility {
+			p.Visibility, changed = fmt.Sprint(*setVisibility), true
+		}
+		for _, tag := range adds {
+			if !containsTag(p.Tags, tag) {
+				p.Tags, changed = append(p.Tags, tag), true
+			}
+		}
+		for _, tag := range removes {
+			if containsTag(p.Tags, tag) {
+				p.Tags, changed = removeTags(p.Tags, tag), true
+			}
+		}
+		return changed, nil
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	options, closeRollback, err := a.bulkOptions(bf)
+	if err != nil {
+		return err
+	}
+	defer closeRollback()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	results, err := t.BulkModify(ctx, selector, mutator, options)
+	return a.printBulkResults(out, results, err)
+}
+
+// bulkFlags are the options shared by the commands built on BulkModify.
+type bulkFlags struct {
+	workers  int
+	interval time.Duration
+	rollback string
+	dryRun   bool
+}
+
+func addBulkFlags(fs *flag.FlagSet) *bulkFlags {
+	f := &bulkFlags{}
+	fs.IntVar(&f.workers, "workers", 4, "posts processed concurrently")
+	fs.DurationVar(&f.interval, "interval", 200*time.Millisecond, "minimum time between API requests")
+	fs.StringVar(&f.rollback, "rollback", "", "rollback file (default: rollback-<time>.jsonl next to the config file)")
+	fs.BoolVar(&f.dryRun, "dry-run", false, "show the posts that would change without writing them")
+	return f
+}
+
+// bulkOptions reports progress on stderr and, unless --dry-run, opens the
+// rollback file; the returned func closes it.
+func (a *app) bulkOptions(f *bulkFlags) (*tistory.BulkOptions, func(), error) {
+	options := &tistory.BulkOptions{
+		Workers:  f.workers,
+		Interval: f.interval,
+		DryRun:   f.dryRun,
+		Progress: func(p tistory.BulkProgress) {
+			fmt.Fprintf(a.stderr, "\r[%d/%d] changed %d, failed %d", p.Done, p.Total, p.Changed, p.Failed)
+			if p.Done == p.Total {
+				fmt.Fprintln(a.stderr)
+			}
+		},
+	}
+	if f.dryRun {
+		return options, func() {}, nil
+	}
+	cfg, _, err := a.credentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	path := f.rollback
+	if path == "" {
+		path = filepath.Join(filepath.Dir(cfg.Path()), "rollback", "rollback-"+time.Now().Format("20060102-150405")+".jsonl")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	options.Rollback = file
+	fmt.Fprintf(a.stderr, "rollback file: %s\n", path)
+	return options, func() { file.Close() }, nil
+}
+
+// printBulkResults prints the changed and failed posts, then returns err.
+func (a *app) printBulkResults(out *outputFlags, results []tistory.BulkResult, err error) error {
+	rows := []map[string]interface{}{}
+	for _, r := range results {
+		if !r.Changed && r.Err == nil {
+			continue
+		}
+		row := map[string]interface{}{"id": r.PostId, "title": r.Title, "changed": r.Changed, "error": ""}
+		if r.Err != nil {
+			row["error"] = r.Err.Error()
+		}
+		rows = append(rows, row)
+	}
+	if printErr := a.printRecords(out, []string{"id", "title", "changed", "error"}, rows); printErr != nil && err == nil {
+		err = printErr
+	}
+	return err
+}
+
+// postsRestore writes back the original posts of a rollback file.
+func (a *app) postsRestore(args []string) error {
+	fs := a.newFlagSet("posts restore")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: tistory posts restore <rollback file>")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	restored, err := t.RestorePosts(f)
+	fmt.Fprintf(a.stdout, "Restored %d posts\n", restored)
+	return err
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func removeTags(tags []string, tag string) []string {
+	kept := tags[:0]
+	for _, t := range tags {
+		if t != tag {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
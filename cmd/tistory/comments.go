@@ -0,0 +1,330 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/analytics"
+	"github.com/pkg/errors"
+)
+
+func (a *app) comments(args []string) error {
+	return a.subcommand("comments", map[string]command{
+		"list":       (*app).commentsList,
+		"write":      (*app).commentsWrite,
+		"edit":       (*app).commentsEdit,
+		"delete":     (*app).commentsDelete,
+		"unanswered": (*app).commentsUnanswered,
+		"export":     (*app).commentsExport,
+		"stats":      (*app).commentsStats,
+	}, args)
+}
+
+// commentsList lists the comments of a post, or the newest comments of the blog without --post.
+func (a *app) commentsList(args []string) error {
+	fs := a.newFlagSet("comments list")
+	postId := fs.Int("post", 0, "post ID (default: newest comments of the blog)")
+	page := fs.Int("page", 1, "page of newest comments")
+	count := fs.Int("count", 10, "newest comments per page (max 10)")
+	tree := fs.Bool("tree", false, "show the replies of --post as a tree")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+
+	if *tree {
+		if *postId == 0 {
+			return errors.New("--tree needs --post")
+		}
+		threads, err := t.GetCommentThreads(*postId)
+		if err != nil {
+			return err
+		}
+		return tistory.RenderThreads(a.stdout, threads)
+	}
+
+	var result map[string]interface{}
+	if *postId != 0 {
+		result, err = t.GetCommentList(*postId)
+	} else {
+		result, err = t.GetRecentCommentList(*page, *count)
+	}
+	if err != nil {
+		return err
+	}
+	it, err := item(result)
+	if err != nil {
+		return err
+	}
+	return a.printRecords(out, []string{"id", "postId", "parentId", "name", "date", "comment"}, commentRecords(it))
+}
+
+// commentsUnanswered lists top-level comments the blog owner has not replied to.
+func (a *app) commentsUnanswered(args []string) error {
+	fs := a.newFlagSet("comments unanswered")
+	postId := fs.Int("post", 0, "only check this post (default: every post)")
+	owner := fs.String("owner", "", "name of the replying author (default: the blog nickname)")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+
+	if *owner == "" {
+		info, err := t.Info()
+		if err != nil {
+			return err
+		}
+		*owner = info.Nickname
+	}
+
+	var unanswered []*tistory.CommentThread
+	if *postId != 0 {
+		threads, err := t.GetCommentThreads(*postId)
+		if err != nil {
+			return err
+		}
+		unanswered = tistory.UnansweredThreads(threads, *owner)
+	} else if unanswered, err = t.UnansweredComments(*owner); err != nil {
+		return err
+	}
+
+	rows := make([]map[string]interface{}, 0, len(unanswered))
+	for _, c := range unanswered {
+		rows = append(rows, map[string]interface{}{
+			"id":      c.Id,
+			"postId":  c.PostId,
+			"name":    c.Name,
+			"date":    c.Date.Format("2006-01-02 15:04:05"),
+			"replies": c.ReplyCount(),
+			"secret":  c.Secret,
+			"comment": c.Content,
+			"link":    c.Link,
+		})
+	}
+	return a.printRecords(out, []string{"id", "postId", "name", "date", "replies", "comment"}, rows)
+}
+
+func (a *app) commentsWrite(args []string) error {
+	fs := a.newFlagSet("comments write")
+	postId := fs.Int("post", 0, "post ID (required)")
+	parentId := fs.Int("parent", 0, "parent comment ID for a reply")
+	secret := fs.Bool("secret", false, "write a secret comment")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *postId == 0 || fs.NArg() == 0 {
+		return errors.New("usage: tistory comments write --post <id> [--parent <id>] [--secret] <content>")
+	}
+
+	option := map[string]interface{}{
+		"postId":  *postId,
+		"content": strings.Join(fs.Args(), " "),
+		"secret":  boolParam(*secret),
+	}
+	if *parentId != 0 {
+		option["parentId"] = *parentId
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.WriteComment(option)
+	if err != nil {
+		return err
+	}
+	return a.printCommentResult(result)
+}
+
+func (a *app) commentsEdit(args []string) error {
+	fs := a.newFlagSet("comments edit")
+	postId := fs.Int("post", 0, "post ID (required)")
+	commentId := fs.Int("id", 0, "comment ID (required)")
+	parentId := fs.Int("parent", 0, "parent comment ID of a reply")
+	secret := fs.Bool("secret", false, "make the comment secret")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *postId == 0 || *commentId == 0 || fs.NArg() == 0 {
+		return errors.New("usage: tistory comments edit --post <id> --id <id> [--secret] <content>")
+	}
+
+	option := map[string]interface{}{
+		"postId":    *postId,
+		"commentId": *commentId,
+		"content":   strings.Join(fs.Args(), " "),
+		"secret":    boolParam(*secret),
+	}
+	if *parentId != 0 {
+		option["parentId"] = *parentId
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.ModifyComment(option)
+	if err != nil {
+		return err
+	}
+	return a.printCommentResult(result)
+}
+
+func (a *app) commentsDelete(args []string) error {
+	fs := a.newFlagSet("comments delete")
+	postId := fs.Int("post", 0, "post ID (required)")
+	commentId := fs.Int("id", 0, "comment ID (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *postId == 0 || *commentId == 0 {
+		return errors.New("usage: tistory comments delete --post <id> --id <id>")
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	if _, err := t.DeleteComment(map[string]interface{}{"postId": *postId, "commentId": *commentId}); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Deleted comment %d\n", *commentId)
+	return nil
+}
+
+func (a *app) printCommentResult(result map[string]interface{}) error {
+	b, err := body(result)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stdout, b["commentUrl"])
+	return nil
+}
+
+// collectComments reads every comment of the blog written in [--since, --until).
+func (a *app) collectComments(since, until string) ([]analytics.PostComments, error) {
+	from, err := parseDay("--since", since)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseDay("--until", until)
+	if err != nil {
+		return nil, err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return nil, err
+	}
+	posts, err := analytics.Collect(t)
+	if err != nil {
+		return nil, err
+	}
+	return analytics.Between(posts, from, to), nil
+}
+
+// parseDay parses a YYYY-MM-DD date in local time; an empty value is the zero time.
+func parseDay(name, s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %s %q (want YYYY-MM-DD)", name, s)
+	}
+	return day, nil
+}
+
+// commentsExport prints every comment of every post.
+func (a *app) commentsExport(args []string) error {
+	fs := a.newFlagSet("comments export")
+	since := fs.String("since", "", "only comments written on or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "only comments written before this date (YYYY-MM-DD)")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	posts, err := a.collectComments(*since, *until)
+	if err != nil {
+		return err
+	}
+
+	var rows []map[string]interface{}
+	for _, pc := range posts {
+		for _, c := range pc.Comments {
+			rows = append(rows, map[string]interface{}{
+				"postId":    pc.Post.Id,
+				"postTitle": pc.Post.Title,
+				"id":        c.Id,
+				"parentId":  c.ParentId,
+				"name":      c.Name,
+				"homepage":  c.Homepage,
+				"secret":    c.Secret,
+				"date":      c.Date.Format("2006-01-02 15:04:05"),
+				"comment":   c.Content,
+				"link":      c.Link,
+			})
+		}
+	}
+	return a.printRecords(out, []string{"postId", "id", "parentId", "name", "secret", "date", "comment"}, rows)
+}
+
+// commentsStats prints per-post or per-author comment statistics.
+func (a *app) commentsStats(args []string) error {
+	fs := a.newFlagSet("comments stats")
+	by := fs.String("by", "post", "group by post or author")
+	since := fs.String("since", "", "only comments written on or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "only comments written before this date (YYYY-MM-DD)")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *by != "post" && *by != "author" {
+		return errors.Errorf("invalid --by %q (want post or author)", *by)
+	}
+	posts, err := a.collectComments(*since, *until)
+	if err != nil {
+		return err
+	}
+	report := analytics.NewReport(posts)
+
+	var rows []map[string]interface{}
+	if *by == "author" {
+		for _, s := range report.Authors {
+			rows = append(rows, map[string]interface{}{
+				"name":               s.Name,
+				"comments":           s.Comments,
+				"replies":            s.Replies,
+				"posts":              s.Posts,
+				"secret":             s.Secret,
+				"secretRatio":        fmt.Sprintf("%.3f", s.SecretRatio),
+				"avgReplyLatency":    s.AvgReplyLatency.String(),
+				"medianReplyLatency": s.MedianReplyLatency.String(),
+			})
+		}
+		return a.printRecords(out, []string{"name", "comments", "replies", "posts", "secretRatio", "avgReplyLatency"}, rows)
+	}
+	for _, s := range report.Posts {
+		rows = append(rows, map[string]interface{}{
+			"postId":             s.PostId,
+			"title":              s.Title,
+			"comments":           s.Comments,
+			"replies":            s.Replies,
+			"authors":            s.Authors,
+			"secret":             s.Secret,
+			"secretRatio":        fmt.Sprintf("%.3f", s.SecretRatio),
+			"avgReplyLatency":    s.AvgReplyLatency.String(),
+			"medianReplyLatency": s.MedianReplyLatency.String(),
+		})
+	}
+	return a.printRecords(out, []string{"postId", "title", "comments", "replies", "authors", "secretRatio", "avgReplyLatency"}, rows)
+}
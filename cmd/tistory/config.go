@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/config"
+	"github.com/pkg/errors"
+)
+
+func (a *app) loadConfig() (*config.Config, error) {
+	if a.configPath != "" {
+		return config.Load(a.configPath)
+	}
+	return config.LoadDefault()
+}
+
+// credentials returns the selected profile merged with the environment and flags.
+// Flags win over the environment, which wins over the config file.
+func (a *app) credentials() (*config.Config, *config.Profile, error) {
+	cfg, err := a.loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := cfg.ProfileName(a.profile)
+	p := config.NewProfile(name, cfg.Path())
+	if stored, ok := cfg.Profiles[name]; ok {
+		copied := *stored
+		p = &copied
+	}
+	if v := os.Getenv("CLIENT_ID"); v != "" {
+		p.ClientID = v
+	}
+	if v := os.Getenv("CLIENT_SECRET"); v != "" {
+		p.ClientSecret = v
+	}
+	if a.blogURL != "" {
+		p.BlogURL = a.blogURL
+		p.BlogName = ""
+	}
+	if a.blogName != "" {
+		p.BlogName = a.blogName
+	}
+	return cfg, p, nil
+}
+
+// newTistory builds a client from p without requiring an access token.
+func newTistory(p *config.Profile) (*tistory.Tistory, error) {
+	t, err := p.NewTistory()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create client (set --blog, CLIENT_ID and CLIENT_SECRET or add a profile)")
+	}
+	if v := os.Getenv("TISTORY_ACCESS_TOKEN"); v != "" {
+		t.AccessToken = v
+	}
+	// TISTORY_BASE_URL points the client at a proxy or a fake server.
+	t.BaseURL = os.Getenv("TISTORY_BASE_URL")
+	return t, nil
+}
+
+// tistory returns an authenticated client for the selected profile. Posts are
+// snapshotted into <config dir>/revisions/<profile> before every modification.
+func (a *app) tistory() (*tistory.Tistory, error) {
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return nil, err
+	}
+	return profileTistory(cfg, p)
+}
+
+// profileTistory is tistory for a profile the command has already loaded.
+func profileTistory(cfg *config.Config, p *config.Profile) (*tistory.Tistory, error) {
+	t, err := newTistory(p)
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.Errorf("no access token for profile %q (run tistory login)", p.Name)
+	}
+	t.Revisions = tistory.NewFileRevisionStore(filepath.Join(filepath.Dir(cfg.Path()), "revisions", p.Name))
+	return t, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/LimJiAn/tistory-go/watch"
+	"github.com/pkg/errors"
+)
+
+func (a *app) content(args []string) error {
+	return a.subcommand("content", map[string]command{
+		"lint":  (*app).contentLint,
+		"clean": (*app).contentClean,
+	}, args)
+}
+
+// readBody reads a content file and skips its front matter, if any. It also
+// returns the number of lines before the body.
+func (a *app) readBody(file string) (string, int, error) {
+	data, err := a.readContent(file)
+	if err != nil {
+		return "", 0, err
+	}
+	draft, err := watch.ParseDraft([]byte(data))
+	if err != nil {
+		return "", 0, err
+	}
+	offset := 0
+	if i := strings.Index(data, draft.Body); i > 0 && draft.Body != "" {
+		offset = strings.Count(data[:i], "\n")
+	}
+	return draft.Body, offset, nil
+}
+
+// contentLint reports the problems of content files before publishing and
+// fails if any would be removed by the sanitizer.
+func (a *app) contentLint(args []string) error {
+	fs := a.newFlagSet("content lint")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: tistory content lint <file>...")
+	}
+
+	errorCount := 0
+	for _, file := range fs.Args() {
+		body, offset, err := a.readBody(file)
+		if err != nil {
+			return err
+		}
+		for _, issue := range content.Lint(body, content.DefaultPolicy()) {
+			issue.Line += offset
+			fmt.Fprintf(a.stdout, "%s:%s\n", file, issue)
+			if issue.Severity == content.SeverityError {
+				errorCount++
+			}
+		}
+	}
+	if errorCount > 0 {
+		return errors.Errorf("%d errors", errorCount)
+	}
+	return nil
+}
+
+// contentClean prints a content file through the publishing pipeline.
+func (a *app) contentClean(args []string) error {
+	fs := a.newFlagSet("content clean")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: tistory content clean <file>")
+	}
+	body, _, err := a.readBody(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(a.stdout, content.Prepare(body))
+	return err
+}
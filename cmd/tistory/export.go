@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/LimJiAn/tistory-go/export"
+	"github.com/pkg/errors"
+)
+
+// postsExport writes posts as Markdown files with their images.
+func (a *app) postsExport(args []string) error {
+	fs := a.newFlagSet("posts export")
+	dir := fs.String("dir", "export", "output directory (one directory per post)")
+	keepImageURLs := fs.Bool("keep-image-urls", false, "link images on Tistory instead of downloading them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var postIds []int
+	for _, arg := range fs.Args() {
+		postId, err := strconv.Atoi(arg)
+		if err != nil {
+			return errors.Errorf("invalid post id %q", arg)
+		}
+		postIds = append(postIds, postId)
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+
+	e := export.New(t, *dir)
+	e.KeepImageURLs = *keepImageURLs
+	e.Log = a.stderr
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	results, err := e.Export(ctx, postIds)
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(a.stderr, "post %d: %v\n", r.PostId, r.Err)
+			continue
+		}
+		fmt.Fprintf(a.stdout, "%d\t%s\t%d images\n", r.PostId, r.File, r.Images)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d posts failed", failed, len(results))
+	}
+	return nil
+}
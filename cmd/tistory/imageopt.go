@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/imageopt"
+)
+
+// imageFlags are the image optimization options of the commands uploading
+// files with AttachPost.
+type imageFlags struct {
+	optimize bool
+	maxWidth int
+	quality  int
+	jpeg     bool
+}
+
+func addImageFlags(fs *flag.FlagSet) *imageFlags {
+	f := &imageFlags{}
+	fs.BoolVar(&f.optimize, "optimize", false, "strip EXIF/GPS metadata from JPEG and PNG images before uploading")
+	fs.IntVar(&f.maxWidth, "max-width", 0, "scale wider images down to this width (implies --optimize)")
+	fs.IntVar(&f.quality, "quality", imageopt.DefaultQuality, "JPEG quality of re-encoded images, 1 to 100")
+	fs.BoolVar(&f.jpeg, "jpeg", false, "re-encode opaque PNGs as JPEG when smaller (implies --optimize)")
+	return f
+}
+
+// apply sets the AttachProcessor of t when any image flag is given.
+func (f *imageFlags) apply(t *tistory.Tistory) {
+	if f.optimize || f.maxWidth > 0 || f.jpeg {
+		t.AttachProcessor = &imageopt.Optimizer{MaxWidth: f.maxWidth, Quality: f.quality, ConvertPNG: f.jpeg}
+	}
+}
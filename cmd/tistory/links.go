@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/linkcheck"
+	"github.com/LimJiAn/tistory-go/relink"
+	"github.com/pkg/errors"
+)
+
+func (a *app) links(args []string) error {
+	return a.subcommand("links", map[string]command{
+		"check":   (*app).linksCheck,
+		"fix":     (*app).linksFix,
+		"rewrite": (*app).linksRewrite,
+	}, args)
+}
+
+// linksCheck prints the broken links of every post and fails if there are any.
+func (a *app) linksCheck(args []string) error {
+	fs := a.newFlagSet("links check")
+	selectCategory := fs.Int("select-category", 0, "only posts of this category ID")
+	workers := fs.Int("workers", 8, "links checked concurrently")
+	perHost := fs.Int("per-host", 2, "concurrent requests to one host")
+	timeout := fs.Duration("timeout", 15*time.Second, "timeout of each request")
+	fixups := fs.String("fixups", "", "also write the broken links to this fixup file for 'links fix'")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+
+	c := &linkcheck.Checker{Workers: *workers, PerHost: *perHost, Timeout: *timeout, UserAgent: "tistory-go linkcheck"}
+	var selector tistory.PostSelector
+	if *selectCategory != 0 {
+		selector = func(p tistory.PostSummary) bool { return p.CategoryId == *selectCategory }
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	reports, err := c.CheckBlog(ctx, t, selector)
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]interface{}{}
+	for _, r := range reports {
+		if r.Err != nil {
+			fmt.Fprintf(a.stderr, "post %d: %v\n", r.PostId, r.Err)
+		}
+		for _, b := range r.Broken {
+			rows = append(rows, map[string]interface{}{"post": r.PostId, "title": r.Title, "kind": b.Kind, "url": b.URL, "status": b.Status()})
+		}
+	}
+	if *fixups != "" {
+		f, err := os.Create(*fixups)
+		if err != nil {
+			return err
+		}
+		err = linkcheck.WriteFixups(f, linkcheck.Fixups(reports))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := a.printRecords(out, []string{"post", "title", "kind", "url", "status"}, rows); err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		return errors.Errorf("%d broken links", len(rows))
+	}
+	return nil
+}
+
+// linksFix applies the replace and unlink entries of a fixup file.
+func (a *app) linksFix(args []string) error {
+	fs := a.newFlagSet("links fix")
+	bf := addBulkFlags(fs)
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: tistory links fix <fixup file>")
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fixups, err := linkcheck.ReadFixups(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	options, closeRollback, err := a.bulkOptions(bf)
+	if err != nil {
+		return err
+	}
+	defer closeRollback()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	selector, mutator := linkcheck.Mutator(fixups)
+	results, err := t.BulkModify(ctx, selector, mutator, options)
+	return a.printBulkResults(out, results, err)
+}
+
+// linksRewrite rewrites links to old domains and numeric post URLs. With
+// --dry-run it prints the diff of every post that would change.
+func (a *app) linksRewrite(args []string) error {
+	fs := a.newFlagSet("links rewrite")
+	domains := fs.String("domain", "", "comma separated old=new hosts, e.g. myblog.tistory.com=blog.example.com")
+	slugs := fs.Bool("slugs", false, "rewrite /123 links to the post URLs of post/list (slogan URLs)")
+	bf := addBulkFlags(fs)
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	r := &relink.Rewriter{Domains: map[string]string{}}
+	for _, pair := range splitList(*domains) {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+			return errors.Errorf("invalid --domain %q (want old=new)", pair)
+		}
+		r.Domains[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+	if len(r.Domains) == 0 && !*slugs {
+		return errors.New("nothing to rewrite (use --domain or --slugs)")
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	if u, err := url.Parse(t.BlogURL); err == nil && u.Host != "" {
+		r.Hosts = append(r.Hosts, u.Host)
+	}
+	if t.BlogName != "" {
+		r.Hosts = append(r.Hosts, t.BlogName+".tistory.com")
+	}
+	if *slugs {
+		if r.Posts, err = relink.PostURLs(t); err != nil {
+			return err
+		}
+	}
+	options, closeRollback, err := a.bulkOptions(bf)
+	if err != nil {
+		return err
+	}
+	defer closeRollback()
+
+	var report func(relink.Change)
+	if bf.dryRun {
+		report = func(c relink.Change) { fmt.Fprint(a.stdout, c.Diff) }
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	results, err := t.BulkModify(ctx, nil, r.Mutator(report), options)
+	if bf.dryRun {
+		return err
+	}
+	return a.printBulkResults(out, results, err)
+}
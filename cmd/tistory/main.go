@@ -0,0 +1,144 @@
+// Command tistory is a command line client for the Tistory Open API.
+//
+//	tistory [global flags] <command> [flags]
+//
+// Credentials are read from flags, the environment (CLIENT_ID, CLIENT_SECRET,
+// TISTORY_BLOG_URL, TISTORY_ACCESS_TOKEN, KAKAO_ID, KAKAO_PASSWORD) or a
+// profile of the config file (see package config). `tistory login` stores the
+// access token in the profile's token store.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const usage = `Usage: tistory [global flags] <command> [flags]
+
+Commands:
+  login                       Log in with a Kakao account and store the access token
+  whoami                      Show the account and its blogs
+  profiles                    List the profiles of the config file
+  posts list|get|write|edit|diff|bulk|restore|export
+                              Manage posts
+  revisions list|diff|restore Show and restore post snapshots taken before edits
+  queue add|list|move|cancel  Manage reserved (scheduled) posts
+  watch|serve <dir>           Publish drafts whose front matter says status: publish
+  content lint|clean <file>   Check or clean post HTML before publishing
+  attach <file>...            Upload files, optionally optimizing images, and print their replacers
+  categories                  List categories
+  tags list|duplicates|rename|merge|delete
+                              Count tags and rename, merge or delete them on every post
+  mirror sync|query           Copy the blog into a local SQLite database and query it
+  search <query>              Search posts with a local full-text index
+  links check|fix|rewrite     Find and fix broken links, or rewrite links after a domain change
+  comments list|write|edit|delete|unanswered|export|stats
+                              Manage comments
+  moderate --rules <file>     Delete or mark spam among the newest comments
+  autoreply --rules <file>    Answer new comments with templated replies
+  notify                      Send new comments to stdout, webhooks, Slack or mail
+
+Global flags:
+`
+
+// app holds the global flags and streams shared by every command.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	blogURL    string
+	blogName   string
+	profile    string
+	configPath string
+}
+
+type command func(a *app, args []string) error
+
+var commands = map[string]command{
+	"login":      (*app).login,
+	"whoami":     (*app).whoami,
+	"profiles":   (*app).profiles,
+	"posts":      (*app).posts,
+	"revisions":  (*app).revisions,
+	"queue":      (*app).queue,
+	"watch":      (*app).watch,
+	"serve":      (*app).watch,
+	"content":    (*app).content,
+	"attach":     (*app).attach,
+	"categories": (*app).categories,
+	"tags":       (*app).tags,
+	"mirror":     (*app).mirror,
+	"search":     (*app).search,
+	"links":      (*app).links,
+	"comments":   (*app).comments,
+	"moderate":   (*app).moderate,
+	"autoreply":  (*app).autoreply,
+	"notify":     (*app).notify,
+}
+
+func main() {
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	if err := a.run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "tistory:", err)
+		os.Exit(1)
+	}
+}
+
+func (a *app) run(args []string) error {
+	fs := flag.NewFlagSet("tistory", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&a.blogURL, "blog", os.Getenv("TISTORY_BLOG_URL"), "blog URL (env TISTORY_BLOG_URL)")
+	fs.StringVar(&a.blogName, "blog-name", os.Getenv("TISTORY_BLOG_NAME"), "blog name when --blog is a custom domain (env TISTORY_BLOG_NAME)")
+	fs.StringVar(&a.profile, "profile", os.Getenv("TISTORY_PROFILE"), "config profile (env TISTORY_PROFILE, default: the file's default profile)")
+	fs.StringVar(&a.configPath, "config", os.Getenv("TISTORY_CONFIG"), "config file (env TISTORY_CONFIG, default ~/.config/tistory/config.yaml)")
+	fs.Usage = func() {
+		fmt.Fprint(a.stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("no command given")
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		return errors.Errorf("unknown command %q", fs.Arg(0))
+	}
+	return cmd(a, fs.Args()[1:])
+}
+
+// subcommand dispatches args[0] to one of subs.
+func (a *app) subcommand(name string, subs map[string]command, args []string) error {
+	names := make([]string, 0, len(subs))
+	for sub := range subs {
+		names = append(names, sub)
+	}
+	sort.Strings(names)
+
+	if len(args) == 0 {
+		return errors.Errorf("usage: tistory %s %s", name, strings.Join(names, "|"))
+	}
+	sub, ok := subs[args[0]]
+	if !ok {
+		return errors.Errorf("unknown command %q (want %s)", name+" "+args[0], strings.Join(names, "|"))
+	}
+	return sub(a, args[1:])
+}
+
+// newFlagSet returns a flag set for a subcommand that reports errors instead of exiting.
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("tistory "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+// newTestApp returns an app wired to srv with an empty config file.
+func newTestApp(t *testing.T, srv *tistorytest.Server) (*app, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("TISTORY_BASE_URL", srv.URL)
+	t.Setenv("TISTORY_BLOG_URL", "https://"+tistorytest.DefaultBlog+".tistory.com")
+	t.Setenv("TISTORY_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	t.Setenv("TISTORY_ACCESS_TOKEN", "")
+	t.Setenv("CLIENT_ID", "client_id")
+	t.Setenv("CLIENT_SECRET", "client_secret")
+
+	stdout := &bytes.Buffer{}
+	return &app{stdin: strings.NewReader(""), stdout: stdout, stderr: &bytes.Buffer{}}, stdout
+}
+
+func runApp(t *testing.T, a *app, stdout *bytes.Buffer, args ...string) string {
+	t.Helper()
+	stdout.Reset()
+	if err := a.run(args); err != nil {
+		t.Fatalf("tistory %s: %v", strings.Join(args, " "), err)
+	}
+	return stdout.String()
+}
+
+func TestRun(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	a, stdout := newTestApp(t, srv)
+
+	if err := a.run([]string{"whoami"}); err == nil {
+		t.Errorf("whoami before login error = nil, want error")
+	}
+
+	runApp(t, a, stdout, "login", "--code", "authorization_code")
+	if out := runApp(t, a, stdout, "profiles"); !strings.Contains(out, "default") || !strings.Contains(out, "true") {
+		t.Errorf("profiles = %q", out)
+	}
+	if out := runApp(t, a, stdout, "whoami"); !strings.Contains(out, tistorytest.DefaultBlog) {
+		t.Errorf("whoami = %q", out)
+	}
+
+	srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "Go"})
+	if out := runApp(t, a, stdout, "categories"); !strings.Contains(out, "Go") {
+		t.Errorf("categories = %q", out)
+	}
+
+	runApp(t, a, stdout, "posts", "write", "--title", "hello", "--content", "<p>body</p>", "--tag", "go,cli", "--visibility", "3")
+	posts := srv.Posts(tistorytest.DefaultBlog)
+	if len(posts) != 1 {
+		t.Fatalf("posts = %v, want 1", posts)
+	}
+	postId := strconv.Itoa(posts[0].ID)
+
+	if out := runApp(t, a, stdout, "posts", "list"); !strings.Contains(out, "hello") {
+		t.Errorf("posts list = %q", out)
+	}
+	if out := runApp(t, a, stdout, "posts", "get", postId); !strings.Contains(out, "<p>body</p>") {
+		t.Errorf("posts get = %q", out)
+	}
+
+	runApp(t, a, stdout, "posts", "edit", "--id", postId, "--title", "renamed")
+	post, _ := srv.Post(tistorytest.DefaultBlog, posts[0].ID)
+	if post.Title != "renamed" || post.Content != "<p>body</p>" || post.Visibility != 3 || len(post.Tags) != 2 {
+		t.Errorf("edited post = %+v, want only the title changed", post)
+	}
+
+	if out := runApp(t, a, stdout, "revisions", "list", postId); !strings.Contains(out, "hello") {
+		t.Errorf("revisions list = %q", out)
+	}
+	if out := runApp(t, a, stdout, "revisions", "diff", postId, "1"); !strings.Contains(out, "-title: hello\n+title: renamed") {
+		t.Errorf("revisions diff = %q", out)
+	}
+
+	local := filepath.Join(t.TempDir(), "post.html")
+	if err := os.WriteFile(local, []byte("---\ntitle: renamed\n---\n<p>\n  body</p>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runApp(t, a, stdout, "posts", "diff", "--id", postId, "--file", local); out != "" {
+		t.Errorf("posts diff of an equal file = %q, want nothing", out)
+	}
+	if err := os.WriteFile(local, []byte("<p>new body</p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runApp(t, a, stdout, "posts", "diff", "--id", postId, "--file", local); !strings.Contains(out, "+<p>new body</p>") {
+		t.Errorf("posts diff = %q", out)
+	}
+
+	exportDir := t.TempDir()
+	if out := runApp(t, a, stdout, "posts", "export", "--dir", exportDir, postId); !strings.Contains(out, filepath.Join(exportDir, postId, "index.md")) {
+		t.Errorf("posts export = %q", out)
+	}
+	if data, err := os.ReadFile(filepath.Join(exportDir, postId, "index.md")); err != nil || !strings.Contains(string(data), "title: renamed") ||
+		!strings.HasSuffix(string(data), "---\nbody\n") {
+		t.Errorf("exported file = %q, %v", data, err)
+	}
+
+	rollback := filepath.Join(t.TempDir(), "rollback.jsonl")
+	runApp(t, a, stdout, "posts", "bulk", "--select-tag", "cli", "--add-tag", "bulk", "--interval", "0", "--rollback", rollback)
+	if post, _ := srv.Post(tistorytest.DefaultBlog, posts[0].ID); strings.Join(post.Tags, ",") != "go,cli,bulk" {
+		t.Errorf("bulk tags = %v", post.Tags)
+	}
+	if out := runApp(t, a, stdout, "posts", "restore", rollback); !strings.Contains(out, "Restored 1") {
+		t.Errorf("posts restore = %q", out)
+	}
+	if post, _ := srv.Post(tistorytest.DefaultBlog, posts[0].ID); strings.Join(post.Tags, ",") != "go,cli" || post.Title != "renamed" {
+		t.Errorf("restored post = %+v", post)
+	}
+
+	runApp(t, a, stdout, "tags", "rename", "--interval", "0", "--dry-run", "cli", "command-line")
+	if out := runApp(t, a, stdout, "tags", "list", "--interval", "0", "-o", "csv"); !strings.Contains(out, "cli,1") {
+		t.Errorf("tags list after a dry run = %q", out)
+	}
+	runApp(t, a, stdout, "tags", "rename", "--interval", "0", "--rollback", filepath.Join(t.TempDir(), "tags.jsonl"), "cli", "command-line")
+	if post, _ := srv.Post(tistorytest.DefaultBlog, posts[0].ID); strings.Join(post.Tags, ",") != "go,command-line" {
+		t.Errorf("renamed tags = %v", post.Tags)
+	}
+
+	runApp(t, a, stdout, "comments", "write", "--post", postId, "first", "comment")
+	comments := srv.Comments(tistorytest.DefaultBlog, posts[0].ID)
+	if len(comments) != 1 || comments[0].Content != "first comment" {
+		t.Fatalf("comments = %v", comments)
+	}
+	commentId := strconv.Itoa(comments[0].ID)
+	srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: posts[0].ID, Name: "reader", Content: "question"})
+	if out := runApp(t, a, stdout, "comments", "unanswered", "-o", "csv"); !strings.Contains(out, "question") || strings.Contains(out, "first comment") {
+		t.Errorf("comments unanswered = %q", out)
+	}
+	if out := runApp(t, a, stdout, "comments", "stats", "--by", "author", "-o", "csv"); !strings.Contains(out, "name,comments,replies") || !strings.Contains(out, "reader,1,") {
+		t.Errorf("comments stats = %q", out)
+	}
+	if out := runApp(t, a, stdout, "comments", "export", "-o", "json"); !strings.Contains(out, `"postTitle"`) {
+		t.Errorf("comments export = %q", out)
+	}
+	if out := runApp(t, a, stdout, "comments", "list", "--post", postId, "--tree"); !strings.Contains(out, "reader") {
+		t.Errorf("comments list --tree = %q", out)
+	}
+	runApp(t, a, stdout, "comments", "edit", "--post", postId, "--id", commentId, "edited")
+	if out := runApp(t, a, stdout, "comments", "list", "--post", postId); !strings.Contains(out, "edited") {
+		t.Errorf("comments list = %q", out)
+	}
+	runApp(t, a, stdout, "comments", "delete", "--post", postId, "--id", commentId)
+	if got := srv.Comments(tistorytest.DefaultBlog, posts[0].ID); len(got) != 1 {
+		t.Errorf("comments after delete = %v", got)
+	}
+
+	rules := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rules, []byte("rules:\n  - name: q\n    blocklist: [question]\n    action: delete\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runApp(t, a, stdout, "moderate", "--rules", rules, "--dry-run"); !strings.Contains(out, `"dryRun":true`) {
+		t.Errorf("moderate = %q", out)
+	}
+	replies := filepath.Join(t.TempDir(), "replies.yaml")
+	if err := os.WriteFile(replies, []byte("rules:\n  - name: q\n    keywords: [question]\n    reply: answer\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runApp(t, a, stdout, "autoreply", "--rules", replies, "--state", filepath.Join(t.TempDir(), "autoreply.json"), "--dry-run"); out != "" {
+		t.Errorf("autoreply first poll = %q, want no output", out)
+	}
+	state := filepath.Join(t.TempDir(), "notify.json")
+	if out := runApp(t, a, stdout, "notify", "--state", state, "--backfill"); !strings.Contains(out, "새 댓글") {
+		t.Errorf("notify = %q", out)
+	}
+
+	html := filepath.Join(t.TempDir(), "post.html")
+	if err := os.WriteFile(html, []byte("---\ntitle: t\n---\n<h1>title</h1>\n<p onclick=\"x()\">text</p>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout.Reset()
+	if err := a.run([]string{"content", "lint", html}); err == nil || !strings.Contains(stdout.String(), html+":5: error: onclick") {
+		t.Errorf("content lint = %q, %v", stdout.String(), err)
+	}
+	if out := runApp(t, a, stdout, "content", "clean", html); out != "<h2 data-ke-size=\"size26\">title</h2>\n<p>text</p>\n\n" {
+		t.Errorf("content clean = %q", out)
+	}
+
+	file := filepath.Join(t.TempDir(), "image.png")
+	if err := os.WriteFile(file, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runApp(t, a, stdout, "attach", file); !strings.Contains(out, "[##_") {
+		t.Errorf("attach = %q", out)
+	}
+
+	var shot bytes.Buffer
+	if err := png.Encode(&shot, image.NewGray(image.Rect(0, 0, 40, 20))); err != nil {
+		t.Fatal(err)
+	}
+	file = filepath.Join(t.TempDir(), "shot.png")
+	if err := os.WriteFile(file, shot.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runApp(t, a, stdout, "attach", "--max-width", "20", "--jpeg", file)
+	files := srv.Attachments(tistorytest.DefaultBlog)
+	if config, format, err := image.DecodeConfig(bytes.NewReader(files[len(files)-1].Data)); err != nil ||
+		files[len(files)-1].Name != "shot.jpg" || format != "jpeg" || config.Width != 20 {
+		t.Errorf("attach --max-width --jpeg uploaded %q, %s %dx%d, %v", files[len(files)-1].Name, format, config.Width, config.Height, err)
+	}
+
+	drafts := t.TempDir()
+	if err := os.WriteFile(filepath.Join(drafts, "draft.html"), []byte("---\ntitle: from watch\nstatus: publish\n---\n<p>x</p>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runApp(t, a, stdout, "watch", "--once", drafts)
+	if _, err := os.Stat(filepath.Join(drafts, "published", "draft.html")); err != nil {
+		t.Errorf("watch --once did not publish the draft: %v", err)
+	}
+
+	db := filepath.Join(t.TempDir(), "mirror.db")
+	if out := runApp(t, a, stdout, "mirror", "sync", "--db", db); !strings.Contains(out, "Synced 2 posts: 2 read") {
+		t.Errorf("mirror sync = %q", out)
+	}
+	if out := runApp(t, a, stdout, "mirror", "query", "--db", db, "--tag", "command-line", "-o", "csv"); !strings.Contains(out, "renamed") || strings.Contains(out, "from watch") {
+		t.Errorf("mirror query = %q", out)
+	}
+
+	index := filepath.Join(t.TempDir(), "index.json")
+	if out := runApp(t, a, stdout, "search", "--index", index, "-o", "csv", "body", "tag:command-line"); !strings.Contains(out, "renamed") || strings.Contains(out, "from watch") {
+		t.Errorf("search = %q", out)
+	}
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	defer dead.Close()
+	runApp(t, a, stdout, "posts", "write", "--title", "links", "--content", `<p><a href="`+dead.URL+`/x">dead</a></p>`)
+	fixups := filepath.Join(t.TempDir(), "fixups.jsonl")
+	stdout.Reset()
+	if err := a.run([]string{"links", "check", "--fixups", fixups, "-o", "csv"}); err == nil || !strings.Contains(stdout.String(), dead.URL+"/x,404 Not Found") {
+		t.Errorf("links check = %q, %v, want the dead link and an error", stdout.String(), err)
+	}
+	data, err := os.ReadFile(fixups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.Replace(data, []byte(`"replace":""`), []byte(`"replace":"https://example.com/x"`), 1)
+	if err := os.WriteFile(fixups, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runApp(t, a, stdout, "links", "fix", "--interval", "0", "--dry-run", fixups)
+	for _, p := range srv.Posts(tistorytest.DefaultBlog) {
+		if p.Title == "links" && !strings.Contains(p.Content, dead.URL) {
+			t.Errorf("links fix --dry-run changed the post: %q", p.Content)
+		}
+	}
+	runApp(t, a, stdout, "links", "fix", "--interval", "0", "--rollback", filepath.Join(t.TempDir(), "rollback.jsonl"), fixups)
+	for _, p := range srv.Posts(tistorytest.DefaultBlog) {
+		if p.Title == "links" && p.Content != `<p><a href="https://example.com/x">dead</a></p>` {
+			t.Errorf("links fix content = %q", p.Content)
+		}
+	}
+
+	runApp(t, a, stdout, "posts", "write", "--title", "internal", "--content", `<p><a href="http://old.example.com/`+postId+`">old</a></p>`)
+	newURL := "https://" + tistorytest.DefaultBlog + ".tistory.com/" + postId
+	if out := runApp(t, a, stdout, "links", "rewrite", "--domain", "old.example.com="+tistorytest.DefaultBlog+".tistory.com", "--slugs",
+		"--interval", "0", "--dry-run"); !strings.Contains(out, `+<p><a href="`+newURL+`">old</a></p>`) {
+		t.Errorf("links rewrite --dry-run = %q", out)
+	}
+	runApp(t, a, stdout, "links", "rewrite", "--domain", "old.example.com="+tistorytest.DefaultBlog+".tistory.com", "--slugs",
+		"--interval", "0", "--rollback", filepath.Join(t.TempDir(), "rewrite.jsonl"))
+	for _, p := range srv.Posts(tistorytest.DefaultBlog) {
+		if p.Title == "internal" && p.Content != `<p><a href="`+newURL+`">old</a></p>` {
+			t.Errorf("links rewrite content = %q", p.Content)
+		}
+	}
+
+	at := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	runApp(t, a, stdout, "queue", "add", "--title", "scheduled", "--content", "<p>later</p>", "--at", at.Format(time.RFC3339))
+	if err := a.run([]string{"queue", "add", "--title", "clash", "--at", at.Add(10 * time.Second).Format(time.RFC3339)}); err == nil {
+		t.Errorf("queue add in a taken slot error = nil, want error")
+	}
+	runApp(t, a, stdout, "queue", "move", "--at", at.Add(time.Hour).Format(time.RFC3339), "1")
+	if out := runApp(t, a, stdout, "queue", "list", "-o", "csv"); !strings.Contains(out, "scheduled") || !strings.Contains(out, "reserved") {
+		t.Errorf("queue list = %q", out)
+	}
+	if out := runApp(t, a, stdout, "queue", "cancel", "1"); !strings.Contains(out, "canceled") {
+		t.Errorf("queue cancel = %q", out)
+	}
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	a := &app{stdout: &bytes.Buffer{}, stderr: &bytes.Buffer{}}
+	for _, args := range [][]string{{}, {"nope"}, {"posts"}, {"posts", "nope"}} {
+		if err := a.run(args); err == nil {
+			t.Errorf("run(%q) error = nil, want error", args)
+		}
+	}
+}
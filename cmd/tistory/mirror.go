@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/mirror"
+)
+
+func (a *app) mirror(args []string) error {
+	return a.subcommand("mirror", map[string]command{
+		"sync":  (*app).mirrorSync,
+		"query": (*app).mirrorQuery,
+	}, args)
+}
+
+func addMirrorFlag(fs *flag.FlagSet) *string {
+	return fs.String("db", "", "mirror database (default: <config dir>/mirror/<profile>.db)")
+}
+
+// openMirror opens the mirror database of the selected profile.
+func (a *app) openMirror(path string) (*mirror.Mirror, error) {
+	if path == "" {
+		cfg, p, err := a.credentials()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(filepath.Dir(cfg.Path()), "mirror", p.Name+".db")
+	}
+	return mirror.Open(path)
+}
+
+// mirrorSync copies new and changed posts, categories and comments into the mirror.
+func (a *app) mirrorSync(args []string) error {
+	fs := a.newFlagSet("mirror sync")
+	db := addMirrorFlag(fs)
+	full := fs.Bool("full", false, "read every post again, not only those whose list metadata changed")
+	verbose := fs.Bool("v", false, "print each post that is read")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	m, err := a.openMirror(*db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	options := &mirror.SyncOptions{Full: *full}
+	if *verbose {
+		options.Progress = func(p tistory.PostSummary) {
+			fmt.Fprintf(a.stderr, "%d\t%s\n", p.Id, p.Title)
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := m.Sync(ctx, t, options)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Synced %d posts: %d read, %d with new comments, %d removed, %d categories\n",
+		result.Posts, result.Fetched, result.Comments, result.Deleted, result.Categories)
+	return nil
+}
+
+// mirrorQuery lists mirrored posts without calling the API.
+func (a *app) mirrorQuery(args []string) error {
+	fs := a.newFlagSet("mirror query")
+	db := addMirrorFlag(fs)
+	category := fs.String("category", "", "category ID, name or label (includes subcategories)")
+	tag := fs.String("tag", "", "only posts with this tag")
+	title := fs.String("title", "", "only posts whose title contains this text")
+	visibility := fs.String("visibility", "", "only posts with this visibility (0, 15 or 20)")
+	since := fs.String("since", "", "only posts published on or after this date (YYYY-MM-DD)")
+	until := fs.String("until", "", "only posts published before this date (YYYY-MM-DD)")
+	limit := fs.Int("limit", 0, "at most this many posts")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	q := mirror.Query{Category: *category, Tag: *tag, Title: *title, Visibility: *visibility, Limit: *limit}
+	var err error
+	if q.Since, err = parseDay("--since", *since); err != nil {
+		return err
+	}
+	if q.Until, err = parseDay("--until", *until); err != nil {
+		return err
+	}
+
+	m, err := a.openMirror(*db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	ctx := context.Background()
+	posts, err := m.Posts(ctx, q)
+	if err != nil {
+		return err
+	}
+	categories, err := m.Categories(ctx)
+	if err != nil {
+		return err
+	}
+	labels := map[int]string{}
+	for _, c := range categories {
+		labels[c.Id] = c.Label
+	}
+
+	rows := make([]map[string]interface{}, 0, len(posts))
+	for _, p := range posts {
+		rows = append(rows, map[string]interface{}{
+			"id":         p.Id,
+			"title":      p.Title,
+			"category":   labels[p.CategoryId],
+			"categoryId": p.CategoryId,
+			"tags":       strings.Join(p.Tags, ","),
+			"visibility": p.Visibility,
+			"comments":   p.Comments,
+			"date":       p.Date.Local().Format("2006-01-02 15:04:05"),
+			"postUrl":    p.PostURL,
+		})
+	}
+	return a.printRecords(out, []string{"id", "title", "category", "tags", "comments", "date"}, rows)
+}
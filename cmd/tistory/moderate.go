@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/LimJiAn/tistory-go/moderation"
+	"github.com/pkg/errors"
+)
+
+// moderate applies a rules file to the newest comments, once or every --interval.
+func (a *app) moderate(args []string) error {
+	fs := a.newFlagSet("moderate")
+	rulesFile := fs.String("rules", "", "rules file (YAML or JSON, required)")
+	dryRun := fs.Bool("dry-run", false, "log decisions without deleting or marking comments")
+	interval := fs.Duration("interval", 0, "poll interval (default: poll once)")
+	pages := fs.Int("pages", 1, "pages of 10 newest comments to check per poll")
+	auditFile := fs.String("audit", "", "append the audit log to this file (default: stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *rulesFile == "" {
+		return errors.New("--rules is required")
+	}
+
+	f, err := os.Open(*rulesFile)
+	if err != nil {
+		return err
+	}
+	rules, err := moderation.LoadRules(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	info, err := t.Info()
+	if err != nil {
+		return err
+	}
+
+	m := moderation.New(t, rules)
+	m.DryRun = *dryRun
+	m.ErrorLog = a.stderr
+	m.Pages = *pages
+	m.Owner = info.Nickname
+	m.Audit = a.stdout
+	if *auditFile != "" {
+		audit, err := os.OpenFile(*auditFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer audit.Close()
+		m.Audit = audit
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return m.Run(ctx, *interval)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/LimJiAn/tistory-go/notify"
+	"github.com/pkg/errors"
+)
+
+// notify sends new comments to stdout, webhooks or mail, once or every --interval.
+func (a *app) notify(args []string) error {
+	fs := a.newFlagSet("notify")
+	statePath := fs.String("state", "", "state file with the last seen comment (default: next to the config file)")
+	interval := fs.Duration("interval", 0, "poll interval (default: poll once)")
+	batch := fs.Int("batch", 0, "maximum comments per message (default: one message per poll)")
+	backfill := fs.Bool("backfill", false, "send the comments found by the first poll instead of only recording them")
+	templateFile := fs.String("template", "", "Go text/template file for messages")
+	stdout := fs.Bool("stdout", false, "print messages (default when no other sink is set)")
+	webhook := fs.String("webhook", "", "comma separated URLs receiving each batch as JSON")
+	slack := fs.String("slack", "", "comma separated Slack-compatible incoming webhook URLs")
+	smtpAddr := fs.String("smtp", "", "SMTP server host:port (auth: env SMTP_USERNAME, SMTP_PASSWORD)")
+	mailFrom := fs.String("mail-from", "", "mail sender")
+	mailTo := fs.String("mail-to", "", "comma separated mail recipients")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var text []byte
+	if *templateFile != "" {
+		var err error
+		if text, err = os.ReadFile(*templateFile); err != nil {
+			return err
+		}
+	}
+	tmpl, err := notify.ParseTemplate(string(text))
+	if err != nil {
+		return err
+	}
+
+	var sinks []notify.Sink
+	for _, url := range splitList(*webhook) {
+		sinks = append(sinks, notify.NewWebhookSink(url))
+	}
+	for _, url := range splitList(*slack) {
+		s := notify.NewSlackSink(url)
+		s.Template = tmpl
+		sinks = append(sinks, s)
+	}
+	if *smtpAddr != "" {
+		to := splitList(*mailTo)
+		if *mailFrom == "" || len(to) == 0 {
+			return errors.New("--smtp requires --mail-from and --mail-to")
+		}
+		s := notify.NewMailSink(*smtpAddr, *mailFrom, to...)
+		s.Template = tmpl
+		if user := os.Getenv("SMTP_USERNAME"); user != "" {
+			host, _, _ := net.SplitHostPort(*smtpAddr)
+			s.Auth = smtp.PlainAuth("", user, os.Getenv("SMTP_PASSWORD"), host)
+		}
+		sinks = append(sinks, s)
+	}
+	if *stdout || len(sinks) == 0 {
+		s := notify.NewWriterSink(a.stdout)
+		s.Template = tmpl
+		sinks = append(sinks, s)
+	}
+
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	t, err := profileTistory(cfg, p)
+	if err != nil {
+		return err
+	}
+	if *statePath == "" {
+		*statePath = filepath.Join(filepath.Dir(cfg.Path()), "state", "notify-"+p.Name+".json")
+	}
+
+	w := notify.New(t, *statePath, sinks...)
+	w.BatchSize = *batch
+	w.Backfill = *backfill
+	w.ErrorLog = a.stderr
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return w.Run(ctx, *interval)
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
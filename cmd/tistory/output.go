@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFlags select how listing commands print their records.
+type outputFlags struct {
+	output string
+	format string
+}
+
+// addOutputFlags registers --output (-o) and --format on fs.
+func addOutputFlags(fs *flag.FlagSet) *outputFlags {
+	f := &outputFlags{}
+	fs.StringVar(&f.output, "output", "table", "output format: table, json, yaml or csv")
+	fs.StringVar(&f.output, "o", "table", "shorthand for --output")
+	fs.StringVar(&f.format, "format", "", "Go template applied to each record, e.g. '{{.ID}} {{.Title}}'")
+	return f
+}
+
+// printRecords writes rows in the selected format. columns are the fields
+// shown by the table and csv formats; json, yaml and templates see every field.
+func (a *app) printRecords(f *outputFlags, columns []string, rows []map[string]interface{}) error {
+	if f.format != "" {
+		return a.printTemplate(f.format, rows)
+	}
+	switch f.output {
+	case "table", "":
+		return a.printTable(columns, rows)
+	case "json":
+		return a.printJSON(rows)
+	case "yaml":
+		return a.printYAML(rows)
+	case "csv":
+		return a.printCSV(columns, rows)
+	default:
+		return errors.Errorf("unknown output format %q (want table, json, yaml or csv)", f.output)
+	}
+}
+
+// body returns the "tistory" object of an API response.
+func body(result map[string]interface{}) (map[string]interface{}, error) {
+	b, ok := result["tistory"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("unexpected response: missing tistory object")
+	}
+	return b, nil
+}
+
+// item returns the "tistory.item" object of an API response.
+func item(result map[string]interface{}) (map[string]interface{}, error) {
+	b, err := body(result)
+	if err != nil {
+		return nil, err
+	}
+	it, ok := b["item"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("unexpected response: missing item object")
+	}
+	return it, nil
+}
+
+// records converts a JSON array of objects into a slice of maps.
+func records(v interface{}) []map[string]interface{} {
+	list, _ := v.([]interface{})
+	out := make([]map[string]interface{}, 0, len(list))
+	for _, entry := range list {
+		if m, ok := entry.(map[string]interface{}); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// commentRecords unwraps {"comment": [...]} as returned by the comment APIs.
+func commentRecords(it map[string]interface{}) []map[string]interface{} {
+	comments, _ := it["comments"].(map[string]interface{})
+	return records(comments["comment"])
+}
+
+func (a *app) printJSON(v interface{}) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+func (a *app) printYAML(v interface{}) error {
+	enc := yaml.NewEncoder(a.stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func (a *app) printCSV(columns []string, rows []map[string]interface{}) error {
+	w := csv.NewWriter(a.stdout)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			if v, ok := row[column]; ok && v != nil {
+				record[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// printTemplate executes text once per row. Field names are exported the
+// way Go would spell them: "id" is .ID, "postUrl" is .PostURL.
+func (a *app) printTemplate(text string, rows []map[string]interface{}) error {
+	tmpl, err := template.New("format").Parse(text)
+	if err != nil {
+		return errors.Wrap(err, "invalid --format")
+	}
+	for _, row := range rows {
+		if err := tmpl.Execute(a.stdout, templateData(row)); err != nil {
+			return err
+		}
+		fmt.Fprintln(a.stdout)
+	}
+	return nil
+}
+
+func templateData(row map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = templateData(nested)
+		}
+		data[templateKey(key)] = value
+	}
+	return data
+}
+
+func templateKey(key string) string {
+	if key == "" {
+		return key
+	}
+	r := []rune(key)
+	r[0] = unicode.ToUpper(r[0])
+	key = string(r)
+	for _, initialism := range []string{"Id", "Url"} {
+		if strings.HasSuffix(key, initialism) {
+			key = strings.TrimSuffix(key, initialism) + strings.ToUpper(initialism)
+		}
+	}
+	return key
+}
+
+// printTable writes the given columns of rows as an aligned table.
+func (a *app) printTable(columns []string, rows []map[string]interface{}) error {
+	w := tabwriter.NewWriter(a.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = cell(row[column])
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+func cell(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	s := strings.Join(strings.Fields(fmt.Sprintf("%v", v)), " ")
+	if r := []rune(s); len(r) > 60 {
+		s = string(r[:57]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintRecords(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": "1", "title": "hello, world", "postUrl": "https://test.tistory.com/1"},
+		{"id": "2", "title": "second", "postUrl": "https://test.tistory.com/2"},
+	}
+	columns := []string{"id", "title"}
+
+	tests := []struct {
+		name string
+		out  outputFlags
+		want string
+	}{
+		{
+			name: "table",
+			out:  outputFlags{output: "table"},
+			want: "ID  TITLE\n1   hello, world\n2   second\n",
+		},
+		{
+			name: "csv",
+			out:  outputFlags{output: "csv"},
+			want: "id,title\n1,\"hello, world\"\n2,second\n",
+		},
+		{
+			name: "json",
+			out:  outputFlags{output: "json"},
+			want: `"postUrl": "https://test.tistory.com/1"`,
+		},
+		{
+			name: "yaml",
+			out:  outputFlags{output: "yaml"},
+			want: "- id: \"1\"\n  postUrl: https://test.tistory.com/1\n  title: hello, world\n",
+		},
+		{
+			name: "template",
+			out:  outputFlags{output: "table", format: "{{.ID}} {{.Title}} {{.PostURL}}"},
+			want: "1 hello, world https://test.tistory.com/1\n2 second https://test.tistory.com/2\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			a := &app{stdout: stdout}
+			if err := a.printRecords(&tt.out, columns, rows); err != nil {
+				t.Fatalf("printRecords() error = %v", err)
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("printRecords() = %q, want %q", stdout.String(), tt.want)
+			}
+		})
+	}
+
+	a := &app{stdout: &bytes.Buffer{}}
+	if err := a.printRecords(&outputFlags{output: "xml"}, columns, rows); err == nil {
+		t.Errorf("printRecords(xml) error = nil, want error")
+	}
+	if err := a.printRecords(&outputFlags{format: "{{.ID"}, columns, rows); err == nil {
+		t.Errorf("printRecords(bad template) error = nil, want error")
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/LimJiAn/tistory-go/watch"
+	"github.com/pkg/errors"
+)
+
+func (a *app) posts(args []string) error {
+	return a.subcommand("posts", map[string]command{
+		"list":    (*app).postsList,
+		"get":     (*app).postsGet,
+		"write":   (*app).postsWrite,
+		"edit":    (*app).postsEdit,
+		"bulk":    (*app).postsBulk,
+		"restore": (*app).postsRestore,
+		"diff":    (*app).postsDiff,
+		"export":  (*app).postsExport,
+	}, args)
+}
+
+func (a *app) postsList(args []string) error {
+	fs := a.newFlagSet("posts list")
+	page := fs.Int("page", 1, "page number")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.GetPostList(*page)
+	if err != nil {
+		return err
+	}
+	it, err := item(result)
+	if err != nil {
+		return err
+	}
+	return a.printRecords(out, []string{"id", "title", "visibility", "categoryId", "comments", "date"}, records(it["posts"]))
+}
+
+func (a *app) postsGet(args []string) error {
+	fs := a.newFlagSet("posts get")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	postId, err := postIdArg(fs)
+	if err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.GetPost(postId)
+	if err != nil {
+		return err
+	}
+	it, err := item(result)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(it)
+}
+
+// postFlags are the options shared by `posts write` and `posts edit`.
+type postFlags struct {
+	fs            *flag.FlagSet
+	title         string
+	content       string
+	file          string
+	visibility    int
+	category      int
+	tag           string
+	published     string
+	slogan        string
+	acceptComment bool
+	password      string
+	clean         bool
+}
+
+func (a *app) newPostFlags(name string) *postFlags {
+	f := &postFlags{fs: a.newFlagSet(name)}
+	f.fs.StringVar(&f.title, "title", "", "post title")
+	f.fs.StringVar(&f.content, "content", "", "post content (HTML)")
+	f.fs.StringVar(&f.file, "file", "", "read content from file ('-' for stdin)")
+	f.fs.IntVar(&f.visibility, "visibility", 0, "0: private, 1: protected, 3: public")
+	f.fs.IntVar(&f.category, "category", 0, "category ID")
+	f.fs.StringVar(&f.tag, "tag", "", "comma separated tags")
+	f.fs.StringVar(&f.published, "published", "", "publish time (RFC 3339 or unix timestamp); future times are reserved")
+	f.fs.StringVar(&f.slogan, "slogan", "", "post slogan (URL)")
+	f.fs.BoolVar(&f.acceptComment, "accept-comment", true, "allow comments")
+	f.fs.StringVar(&f.password, "password", "", "password of a protected post")
+	f.fs.BoolVar(&f.clean, "clean", false, "sanitize the content and convert headings, code blocks and images to the editor's format")
+	return f
+}
+
+// option returns the API parameters of every flag that was set explicitly.
+func (a *app) postOption(f *postFlags) (map[string]interface{}, error) {
+	option := map[string]interface{}{}
+	var err error
+	f.fs.Visit(func(fl *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch fl.Name {
+		case "title":
+			option["title"] = f.title
+		case "content":
+			option["content"] = f.content
+		case "file":
+			var text string
+			text, err = a.readContent(f.file)
+			option["content"] = text
+		case "visibility":
+			option["visibility"] = f.visibility
+		case "category":
+			option["category"] = f.category
+		case "tag":
+			option["tag"] = f.tag
+		case "published":
+			var ts int64
+			ts, err = parsePublished(f.published)
+			option["published"] = ts
+		case "slogan":
+			option["slogan"] = f.slogan
+		case "accept-comment":
+			option["acceptComment"] = boolParam(f.acceptComment)
+		case "password":
+			option["password"] = f.password
+		}
+	})
+	if c, ok := option["content"].(string); ok && f.clean {
+		option["content"] = content.Prepare(c)
+	}
+	return option, err
+}
+
+func (a *app) postsWrite(args []string) error {
+	f := a.newPostFlags("posts write")
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	option, err := a.postOption(f)
+	if err != nil {
+		return err
+	}
+	if option["title"] == nil || option["title"] == "" {
+		return errors.New("--title is required")
+	}
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	p.ApplyDefaults(option)
+
+	t, err := profileTistory(cfg, p)
+	if err != nil {
+		return err
+	}
+	result, err := t.WritePost(option)
+	if err != nil {
+		return err
+	}
+	return a.printPostResult(result)
+}
+
+// postsEdit modifies a post, keeping the current value of every field not given as a flag.
+func (a *app) postsEdit(args []string) error {
+	f := a.newPostFlags("posts edit")
+	postId := f.fs.Int("id", 0, "post ID (required)")
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if *postId == 0 {
+		return errors.New("--id is required")
+	}
+	option, err := a.postOption(f)
+	if err != nil {
+		return err
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.EditPost(*postId, option)
+	if err != nil {
+		return err
+	}
+	return a.printPostResult(result)
+}
+
+func (a *app) printPostResult(result map[string]interface{}) error {
+	b, err := body(result)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%v\t%v\n", b["postId"], b["url"])
+	return nil
+}
+
+func (a *app) readContent(file string) (string, error) {
+	if file == "-" {
+		data, err := io.ReadAll(a.stdin)
+		return string(data), err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to read content")
+	}
+	return string(data), nil
+}
+
+func parsePublished(s string) (int64, error) {
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ts, nil
+	}
+	tm, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, errors.Errorf("invalid --published %q (want RFC 3339 or unix timestamp)", s)
+	}
+	return tm.Unix(), nil
+}
+
+func postIdArg(fs *flag.FlagSet) (int, error) {
+	if fs.NArg() != 1 {
+		return 0, errors.Errorf("usage: %s <post id>", fs.Name())
+	}
+	postId, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return 0, errors.Errorf("invalid post id %q", fs.Arg(0))
+	}
+	return postId, nil
+}
+
+func boolParam(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// postsDiff compares a post with a local file. The file may start with a
+// front matter (see `tistory watch`); fields it does not set keep their remote value.
+func (a *app) postsDiff(args []string) error {
+	fs := a.newFlagSet("posts diff")
+	postId := fs.Int("id", 0, "post ID (required)")
+	file := fs.String("file", "", "local content file ('-' for stdin, required)")
+	asJSON := fs.Bool("json", false, "print the structured diff as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *postId == 0 || *file == "" {
+		return errors.New("usage: tistory posts diff --id <post id> --file <file>")
+	}
+	data, err := a.readContent(*file)
+	if err != nil {
+		return err
+	}
+	draft, err := watch.ParseDraft([]byte(data))
+	if err != nil {
+		return err
+	}
+
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	remote, err := t.ReadPost(*postId)
+	if err != nil {
+		return err
+	}
+	local := remote
+	local.Content = draft.Body
+	if draft.Title != "" {
+		local.Title = draft.Title
+	}
+	if draft.Tags != nil {
+		local.Tags = draft.Tags
+	}
+	if draft.Category != 0 {
+		local.CategoryId = draft.Category
+	}
+	if draft.Visibility != nil {
+		local.Visibility = strconv.Itoa(*draft.Visibility)
+	}
+
+	d := tistory.DiffPosts(remote, local)
+	if *asJSON {
+		return a.printJSON(d)
+	}
+	_, err = fmt.Fprint(a.stdout, d.Unified())
+	return err
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/config"
+	"github.com/LimJiAn/tistory-go/schedule"
+	"github.com/pkg/errors"
+)
+
+func (a *app) queue(args []string) error {
+	return a.subcommand("queue", map[string]command{
+		"add":    (*app).queueAdd,
+		"list":   (*app).queueList,
+		"move":   (*app).queueMove,
+		"cancel": (*app).queueCancel,
+	}, args)
+}
+
+// queueFlags are the options shared by the queue subcommands.
+type queueFlags struct {
+	path string
+	slot time.Duration
+}
+
+func addQueueFlags(fs *flag.FlagSet) *queueFlags {
+	f := &queueFlags{}
+	fs.StringVar(&f.path, "queue", "", "queue file (default: next to the config file)")
+	fs.DurationVar(&f.slot, "slot", schedule.DefaultSlot, "publishing slot; two posts less than a slot apart conflict")
+	return f
+}
+
+// scheduler opens the queue of the selected profile.
+func (a *app) scheduler(f *queueFlags) (*schedule.Scheduler, error) {
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return nil, err
+	}
+	return profileScheduler(cfg, p, f)
+}
+
+// profileScheduler is scheduler for a profile the command has already loaded.
+func profileScheduler(cfg *config.Config, p *config.Profile, f *queueFlags) (*schedule.Scheduler, error) {
+	t, err := profileTistory(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	path := f.path
+	if path == "" {
+		path = filepath.Join(filepath.Dir(cfg.Path()), "state", "queue-"+p.Name+".json")
+	}
+	store, err := schedule.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	s := schedule.New(t, store)
+	s.Slot = f.slot
+	return s, nil
+}
+
+// queueAdd writes a post reserved for --at and records it in the queue.
+func (a *app) queueAdd(args []string) error {
+	f := a.newPostFlags("queue add")
+	at := f.fs.String("at", "", "publish time (RFC 3339 or unix timestamp, required)")
+	qf := addQueueFlags(f.fs)
+	if err := f.fs.Parse(args); err != nil {
+		return err
+	}
+	if *at == "" {
+		return errors.New("--at is required")
+	}
+	published, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+	option, err := a.postOption(f)
+	if err != nil {
+		return err
+	}
+	if option["title"] == nil || option["title"] == "" {
+		return errors.New("--title is required")
+	}
+	delete(option, "published")
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	p.ApplyDefaults(option)
+
+	s, err := profileScheduler(cfg, p, qf)
+	if err != nil {
+		return err
+	}
+	e, err := s.Add(option, published)
+	if err != nil {
+		return err
+	}
+	return a.printEntry(e)
+}
+
+func (a *app) queueList(args []string) error {
+	fs := a.newFlagSet("queue list")
+	qf := addQueueFlags(fs)
+	all := fs.Bool("all", false, "include published and canceled entries")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := a.scheduler(qf)
+	if err != nil {
+		return err
+	}
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]interface{}{}
+	for _, e := range entries {
+		if !*all && e.Status != schedule.StatusReserved {
+			continue
+		}
+		rows = append(rows, map[string]interface{}{
+			"id":        e.Id,
+			"postId":    e.PostId,
+			"title":     e.Title,
+			"published": e.Published.Local().Format("2006-01-02 15:04:05"),
+			"status":    string(e.Status),
+			"postUrl":   e.PostURL,
+		})
+	}
+	return a.printRecords(out, []string{"id", "postId", "title", "published", "status"}, rows)
+}
+
+// queueMove reschedules a reservation.
+func (a *app) queueMove(args []string) error {
+	fs := a.newFlagSet("queue move")
+	at := fs.String("at", "", "new publish time (RFC 3339 or unix timestamp, required)")
+	qf := addQueueFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id, err := queueIdArg(fs)
+	if err != nil {
+		return err
+	}
+	if *at == "" {
+		return errors.New("--at is required")
+	}
+	published, err := parseAt(*at)
+	if err != nil {
+		return err
+	}
+	s, err := a.scheduler(qf)
+	if err != nil {
+		return err
+	}
+	e, err := s.Move(id, published)
+	if err != nil {
+		return err
+	}
+	return a.printEntry(e)
+}
+
+// queueCancel makes a reservation private so that it is not published.
+func (a *app) queueCancel(args []string) error {
+	fs := a.newFlagSet("queue cancel")
+	qf := addQueueFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id, err := queueIdArg(fs)
+	if err != nil {
+		return err
+	}
+	s, err := a.scheduler(qf)
+	if err != nil {
+		return err
+	}
+	e, err := s.Cancel(id)
+	if err != nil {
+		return err
+	}
+	return a.printEntry(e)
+}
+
+func (a *app) printEntry(e *schedule.Entry) error {
+	_, err := fmt.Fprintf(a.stdout, "%d\t%d\t%s\t%s\t%s\n",
+		e.Id, e.PostId, e.Published.Local().Format(time.RFC3339), e.Status, e.Title)
+	return err
+}
+
+func parseAt(s string) (time.Time, error) {
+	ts, err := parsePublished(s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid --at %q (want RFC 3339 or unix timestamp)", s)
+	}
+	return time.Unix(ts, 0), nil
+}
+
+func queueIdArg(fs *flag.FlagSet) (int, error) {
+	if fs.NArg() != 1 {
+		return 0, errors.Errorf("usage: %s [flags] <queue id>", fs.Name())
+	}
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return 0, errors.Errorf("invalid queue id %q", fs.Arg(0))
+	}
+	return id, nil
+}
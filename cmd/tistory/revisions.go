@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+func (a *app) revisions(args []string) error {
+	return a.subcommand("revisions", map[string]command{
+		"list":    (*app).revisionsList,
+		"diff":    (*app).revisionsDiff,
+		"restore": (*app).revisionsRestore,
+	}, args)
+}
+
+func (a *app) revisionsList(args []string) error {
+	fs := a.newFlagSet("revisions list")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	postId, err := postIdArg(fs)
+	if err != nil {
+		return err
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	revisions, err := t.PostRevisions(postId)
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]interface{}{}
+	for _, r := range revisions {
+		rows = append(rows, map[string]interface{}{
+			"revision": r.Number,
+			"postId":   r.PostId,
+			"time":     r.Time.Local().Format("2006-01-02 15:04:05"),
+			"title":    r.Post.Title,
+			"length":   len(r.Post.Content),
+		})
+	}
+	return a.printRecords(out, []string{"revision", "time", "title", "length"}, rows)
+}
+
+// revisionsDiff prints the diff of two revisions, or of a revision and the current post.
+func (a *app) revisionsDiff(args []string) error {
+	fs := a.newFlagSet("revisions diff")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 || fs.NArg() > 3 {
+		return errors.New("usage: tistory revisions diff <post id> <revision> [<revision>]")
+	}
+	numbers := make([]int, 3)
+	for i, arg := range fs.Args() {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return errors.Errorf("invalid number %q", arg)
+		}
+		numbers[i] = n
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	d, err := t.DiffRevisions(numbers[0], numbers[1], numbers[2])
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(a.stdout, d)
+	return err
+}
+
+func (a *app) revisionsRestore(args []string) error {
+	fs := a.newFlagSet("revisions restore")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("usage: tistory revisions restore <post id> <revision>")
+	}
+	postId, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return errors.Errorf("invalid post id %q", fs.Arg(0))
+	}
+	number, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return errors.Errorf("invalid revision %q", fs.Arg(1))
+	}
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	result, err := t.RestoreRevision(postId, number)
+	if err != nil {
+		return err
+	}
+	return a.printPostResult(result)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/LimJiAn/tistory-go/search"
+	"github.com/pkg/errors"
+)
+
+// search queries the local full-text index, updating it first with --update
+// or when it is empty.
+func (a *app) search(args []string) error {
+	fs := a.newFlagSet("search")
+	path := fs.String("index", "", "index file (default: <config dir>/search/<profile>.json)")
+	update := fs.Bool("update", false, "read new and changed posts into the index before searching")
+	full := fs.Bool("full", false, "with --update, read every post again")
+	limit := fs.Int("limit", 10, "at most this many results (0: all)")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if query == "" && !*update {
+		return errors.New(`usage: tistory search [--update] <words, "phrases", tag:NAME, category:NAME>`)
+	}
+
+	if *path == "" {
+		cfg, p, err := a.credentials()
+		if err != nil {
+			return err
+		}
+		*path = filepath.Join(filepath.Dir(cfg.Path()), "search", p.Name+".json")
+	}
+	idx, err := search.Open(*path)
+	if err != nil {
+		return err
+	}
+	if *update || len(idx.Docs) == 0 {
+		t, err := a.tistory()
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		result, err := idx.Update(ctx, t, *full)
+		if err != nil {
+			return err
+		}
+		if err := idx.Save(); err != nil {
+			return err
+		}
+		fmt.Fprintf(a.stderr, "Indexed %d of %d posts, removed %d\n", result.Indexed, result.Posts, result.Removed)
+	}
+	if query == "" {
+		return nil
+	}
+
+	rows := []map[string]interface{}{}
+	for _, r := range idx.Search(search.ParseQuery(query), *limit) {
+		rows = append(rows, map[string]interface{}{
+			"id":      r.Id,
+			"title":   r.Title,
+			"score":   fmt.Sprintf("%.2f", r.Score),
+			"date":    r.Date.Local().Format("2006-01-02"),
+			"tags":    strings.Join(r.Tags, ","),
+			"postUrl": r.PostURL,
+			"snippet": r.Snippet,
+		})
+	}
+	return a.printRecords(out, []string{"id", "title", "score", "date", "snippet"}, rows)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/tags"
+	"github.com/pkg/errors"
+)
+
+func (a *app) tags(args []string) error {
+	return a.subcommand("tags", map[string]command{
+		"list":       (*app).tagsList,
+		"duplicates": (*app).tagsDuplicates,
+		"rename":     (*app).tagsRename,
+		"merge":      (*app).tagsMerge,
+		"delete":     (*app).tagsDelete,
+	}, args)
+}
+
+// collectTags reads every post and counts its tags.
+func (a *app) collectTags(workers int, interval time.Duration) ([]tags.Tag, error) {
+	t, err := a.tistory()
+	if err != nil {
+		return nil, err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return tags.Collect(ctx, t, &tistory.BulkOptions{Workers: workers, Interval: interval})
+}
+
+// tagsList prints every tag with the number of posts using it.
+func (a *app) tagsList(args []string) error {
+	fs := a.newFlagSet("tags list")
+	minCount := fs.Int("min", 1, "only tags used by at least this many posts")
+	workers := fs.Int("workers", 4, "posts read concurrently")
+	interval := fs.Duration("interval", 200*time.Millisecond, "minimum time between API requests")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	counts, err := a.collectTags(*workers, *interval)
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]interface{}{}
+	for _, tag := range counts {
+		if tag.Count < *minCount {
+			continue
+		}
+		rows = append(rows, map[string]interface{}{"name": tag.Name, "count": tag.Count, "posts": tag.PostIds})
+	}
+	return a.printRecords(out, []string{"name", "count"}, rows)
+}
+
+// tagsDuplicates prints groups of tags that are probably the same tag.
+func (a *app) tagsDuplicates(args []string) error {
+	fs := a.newFlagSet("tags duplicates")
+	workers := fs.Int("workers", 4, "posts read concurrently")
+	interval := fs.Duration("interval", 200*time.Millisecond, "minimum time between API requests")
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	counts, err := a.collectTags(*workers, *interval)
+	if err != nil {
+		return err
+	}
+
+	rows := []map[string]interface{}{}
+	for _, g := range tags.Duplicates(counts, tags.DefaultAliases) {
+		spellings := make([]string, len(g.Tags))
+		for i, tag := range g.Tags {
+			spellings[i] = fmt.Sprintf("%s(%d)", tag.Name, tag.Count)
+		}
+		rows = append(rows, map[string]interface{}{"canonical": g.Canonical, "tags": strings.Join(spellings, ", ")})
+	}
+	return a.printRecords(out, []string{"canonical", "tags"}, rows)
+}
+
+// tagsRename renames a tag on every post.
+func (a *app) tagsRename(args []string) error {
+	fs := a.newFlagSet("tags rename")
+	bf := addBulkFlags(fs)
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 || fs.Arg(1) == "" {
+		return errors.New("usage: tistory tags rename <old> <new>")
+	}
+	return a.retag(bf, out, func(ctx context.Context, t *tistory.Tistory, options *tistory.BulkOptions) ([]tistory.BulkResult, error) {
+		return tags.Rename(ctx, t, fs.Arg(0), fs.Arg(1), options)
+	})
+}
+
+// tagsMerge replaces several tags with --into on every post.
+func (a *app) tagsMerge(args []string) error {
+	fs := a.newFlagSet("tags merge")
+	into := fs.String("into", "", "tag that replaces the others (required)")
+	bf := addBulkFlags(fs)
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *into == "" || fs.NArg() == 0 {
+		return errors.New("usage: tistory tags merge --into <tag> <tag>...")
+	}
+	return a.retag(bf, out, func(ctx context.Context, t *tistory.Tistory, options *tistory.BulkOptions) ([]tistory.BulkResult, error) {
+		return tags.Merge(ctx, t, fs.Args(), *into, options)
+	})
+}
+
+// tagsDelete removes tags from every post.
+func (a *app) tagsDelete(args []string) error {
+	fs := a.newFlagSet("tags delete")
+	bf := addBulkFlags(fs)
+	out := addOutputFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("usage: tistory tags delete <tag>...")
+	}
+	return a.retag(bf, out, func(ctx context.Context, t *tistory.Tistory, options *tistory.BulkOptions) ([]tistory.BulkResult, error) {
+		return tags.Delete(ctx, t, fs.Args(), options)
+	})
+}
+
+// retag runs a tag operation with the bulk flags and prints the changed posts.
+func (a *app) retag(bf *bulkFlags, out *outputFlags,
+	op func(ctx context.Context, t *tistory.Tistory, options *tistory.BulkOptions) ([]tistory.BulkResult, error)) error {
+	t, err := a.tistory()
+	if err != nil {
+		return err
+	}
+	options, closeRollback, err := a.bulkOptions(bf)
+	if err != nil {
+		return err
+	}
+	defer closeRollback()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	results, err := op(ctx, t, options)
+	return a.printBulkResults(out, results, err)
+}
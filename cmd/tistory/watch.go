@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/watch"
+	"github.com/pkg/errors"
+)
+
+// watch publishes drafts of a directory until SIGINT or SIGTERM.
+func (a *app) watch(args []string) error {
+	fs := a.newFlagSet("watch")
+	interval := fs.Duration("interval", 10*time.Second, "polling interval")
+	publishedDir := fs.String("published-dir", "", "directory receiving published drafts (default: <dir>/published)")
+	once := fs.Bool("once", false, "scan once and exit")
+	images := addImageFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: tistory watch [flags] <drafts dir>")
+	}
+
+	cfg, p, err := a.credentials()
+	if err != nil {
+		return err
+	}
+	t, err := profileTistory(cfg, p)
+	if err != nil {
+		return err
+	}
+	images.apply(t)
+
+	w := watch.New(t, fs.Arg(0))
+	w.Interval = *interval
+	w.PublishedDir = *publishedDir
+	w.Log = a.stderr
+	w.Defaults = map[string]interface{}{}
+	p.ApplyDefaults(w.Defaults)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *once {
+		_, err := w.Scan(ctx)
+		return err
+	}
+	return w.Run(ctx)
+}
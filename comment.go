@@ -0,0 +1,257 @@
+package tistory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Comment is one comment of comment/list or comment/newest.
+type Comment struct {
+	Id       int
+	PostId   int
+	ParentId int // 0 for a top-level comment
+	Name     string
+	Homepage string
+	Content  string
+	Secret   bool
+	Date     time.Time
+	Link     string
+}
+
+// CommentThread is a comment and its replies.
+type CommentThread struct {
+	Comment
+	Parent  *CommentThread
+	Replies []*CommentThread
+}
+
+// ParseComments converts a comment/list or comment/newest response into Comments.
+func ParseComments(result map[string]interface{}) ([]Comment, error) {
+	item, err := responseItem(result)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse comments")
+	}
+	postId := intField(item, "postId")
+
+	wrapper, _ := item["comments"].(map[string]interface{})
+	var list []interface{}
+	switch v := wrapper["comment"].(type) {
+	case []interface{}:
+		list = v
+	case map[string]interface{}:
+		// A single comment may be returned as an object instead of an array.
+		list = []interface{}{v}
+	}
+
+	comments := make([]Comment, 0, len(list))
+	for _, entry := range list {
+		c, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		comment := Comment{
+			Id:       intField(c, "id"),
+			PostId:   intField(c, "postId"),
+			ParentId: intField(c, "parentId"),
+			Name:     stringField(c, "name"),
+			Homepage: stringField(c, "homepage"),
+			Content:  stringField(c, "comment"),
+			Secret:   stringField(c, "open") == "N" || stringField(c, "visibility") == "0",
+			Date:     parseDate(stringField(c, "date")),
+			Link:     stringField(c, "link"),
+		}
+		if comment.PostId == 0 {
+			comment.PostId = postId
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+/*
+BuildCommentThreads links replies to their parents by ParentId.
+Threads and replies are ordered by date; replies whose parent is missing
+become top-level threads.
+*/
+func BuildCommentThreads(comments []Comment) []*CommentThread {
+	nodes := make(map[int]*CommentThread, len(comments))
+	for _, c := range comments {
+		nodes[c.Id] = &CommentThread{Comment: c}
+	}
+
+	var roots []*CommentThread
+	for _, c := range comments {
+		node := nodes[c.Id]
+		parent, ok := nodes[c.ParentId]
+		if c.ParentId == 0 || !ok || parent == node {
+			roots = append(roots, node)
+			continue
+		}
+		node.Parent = parent
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	sortThreads(roots)
+	return roots
+}
+
+func sortThreads(threads []*CommentThread) {
+	sort.SliceStable(threads, func(i, j int) bool {
+		if threads[i].Date.Equal(threads[j].Date) {
+			return threads[i].Id < threads[j].Id
+		}
+		return threads[i].Date.Before(threads[j].Date)
+	})
+	for _, thread := range threads {
+		sortThreads(thread.Replies)
+	}
+}
+
+/*
+GetCommentThreads 댓글 트리
+GetCommentList 결과를 대댓글 트리로 반환합니다.
+*/
+func (t *Tistory) GetCommentThreads(postId int) ([]*CommentThread, error) {
+	result, err := t.GetCommentList(postId)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := ParseComments(result)
+	if err != nil {
+		return nil, err
+	}
+	return BuildCommentThreads(comments), nil
+}
+
+// ReplyCount returns the number of replies below c at any depth.
+func (c *CommentThread) ReplyCount() int {
+	n := 0
+	for _, reply := range c.Replies {
+		n += 1 + reply.ReplyCount()
+	}
+	return n
+}
+
+// AnsweredBy reports whether a reply below c was written by name.
+// An empty name matches any reply.
+func (c *CommentThread) AnsweredBy(name string) bool {
+	for _, reply := range c.Replies {
+		if name == "" || reply.Name == name || reply.AnsweredBy(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Walk calls fn for c and every reply below it, depth first.
+func (c *CommentThread) Walk(fn func(node *CommentThread, depth int)) {
+	c.walk(fn, 0)
+}
+
+func (c *CommentThread) walk(fn func(node *CommentThread, depth int), depth int) {
+	fn(c, depth)
+	for _, reply := range c.Replies {
+		reply.walk(fn, depth+1)
+	}
+}
+
+// Render writes the thread as an indented outline.
+func (c *CommentThread) Render(w io.Writer) error {
+	var err error
+	c.Walk(func(node *CommentThread, depth int) {
+		if err != nil {
+			return
+		}
+		secret := ""
+		if node.Secret {
+			secret = " (secret)"
+		}
+		_, err = fmt.Fprintf(w, "%s#%d %s [%s]%s: %s\n",
+			strings.Repeat("  ", depth), node.Id, node.Name,
+			node.Date.Format(dateLayout), secret, strings.Join(strings.Fields(node.Content), " "))
+	})
+	return err
+}
+
+// RenderThreads writes every thread with Render.
+func RenderThreads(w io.Writer, threads []*CommentThread) error {
+	for _, thread := range threads {
+		if err := thread.Render(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+UnansweredThreads returns the top-level comments without a reply from owner
+(the blog nickname). Comments written by owner are skipped. An empty owner
+treats any reply as an answer.
+*/
+func UnansweredThreads(threads []*CommentThread, owner string) []*CommentThread {
+	var unanswered []*CommentThread
+	for _, thread := range threads {
+		if thread.Parent != nil || (owner != "" && thread.Name == owner) {
+			continue
+		}
+		if !thread.AnsweredBy(owner) {
+			unanswered = append(unanswered, thread)
+		}
+	}
+	return unanswered
+}
+
+/*
+UnansweredComments 답변하지 않은 댓글
+댓글이 있는 모든 글을 확인하여 owner 가 답하지 않은 최상위 댓글을 반환합니다.
+*/
+func (t *Tistory) UnansweredComments(owner string) ([]*CommentThread, error) {
+	posts, err := t.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+
+	var unanswered []*CommentThread
+	for _, post := range posts {
+		if post.Comments == 0 {
+			continue
+		}
+		threads, err := t.GetCommentThreads(post.Id)
+		if err != nil {
+			return nil, err
+		}
+		unanswered = append(unanswered, UnansweredThreads(threads, owner)...)
+	}
+	return unanswered, nil
+}
+
+/*
+ReplyComment 대댓글 작성
+WriteComment 에 parentId 를 지정하여 c 에 답글을 작성합니다.
+Replies to a reply are attached to its top-level comment, as Tistory allows a single level.
+*/
+func (t *Tistory) ReplyComment(c *CommentThread, content string, secret bool) (map[string]interface{}, error) {
+	if c == nil {
+		return nil, errors.New("Failed to ReplyComment (comment is nil)")
+	}
+	root := c
+	for root.Parent != nil {
+		root = root.Parent
+	}
+
+	secretFlag := 0
+	if secret {
+		secretFlag = 1
+	}
+	return t.WriteComment(map[string]interface{}{
+		"postId":   c.PostId,
+		"parentId": root.Id,
+		"content":  content,
+		"secret":   secretFlag,
+	})
+}
@@ -0,0 +1,108 @@
+package tistory
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestBuildCommentThreads(t *testing.T) {
+	base := time.Date(2023, 9, 1, 12, 0, 0, 0, kst)
+	comments := []Comment{
+		{Id: 4, ParentId: 1, Name: "owner", Content: "answer", Date: base.Add(3 * time.Minute)},
+		{Id: 1, Name: "reader", Content: "question", Date: base},
+		{Id: 2, Name: "reader2", Content: "another question", Date: base.Add(time.Minute)},
+		{Id: 3, ParentId: 2, Name: "reader3", Content: "me too", Date: base.Add(2 * time.Minute)},
+		{Id: 5, ParentId: 99, Name: "orphan", Content: "parent deleted", Date: base.Add(4 * time.Minute)},
+		{Id: 6, Name: "owner", Content: "notice", Date: base.Add(5 * time.Minute)},
+	}
+	threads := BuildCommentThreads(comments)
+
+	if len(threads) != 4 || threads[0].Id != 1 || threads[1].Id != 2 || threads[2].Id != 5 {
+		t.Fatalf("BuildCommentThreads() roots = %v", threadIds(threads))
+	}
+	if threads[0].ReplyCount() != 1 || threads[0].Replies[0].Parent != threads[0] {
+		t.Errorf("thread 1 replies = %v", threadIds(threads[0].Replies))
+	}
+
+	tests := []struct {
+		name  string
+		owner string
+		want  []int
+	}{
+		{name: "owner replies only", owner: "owner", want: []int{2, 5}},
+		{name: "any reply", owner: "", want: []int{5, 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := threadIds(UnansweredThreads(threads, tt.owner))
+			if len(got) != len(tt.want) {
+				t.Fatalf("UnansweredThreads() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("UnansweredThreads() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := threads[0].Render(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "#1 reader [2023-09-01 12:00:00]: question\n  #4 owner [2023-09-01 12:03:00]: answer\n"
+	if buf.String() != want {
+		t.Errorf("Render() = %q, want %q", buf.String(), want)
+	}
+}
+
+func threadIds(threads []*CommentThread) []int {
+	ids := make([]int, len(threads))
+	for i, thread := range threads {
+		ids[i] = thread.Id
+	}
+	return ids
+}
+
+func TestTistory_UnansweredComments(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	answered := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "answered"})
+	open := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "open"})
+	srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "quiet"})
+	question := srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: answered, Name: "reader", Content: "q1"})
+	srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: answered, ParentID: question, Name: srv.Owner, Content: "a1"})
+	srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: open, Name: "reader", Content: "q2", Secret: true})
+
+	unanswered, err := tst.UnansweredComments(srv.Owner)
+	if err != nil {
+		t.Fatalf("Tistory.UnansweredComments() error = %v", err)
+	}
+	if len(unanswered) != 1 || unanswered[0].Content != "q2" || !unanswered[0].Secret || unanswered[0].PostId != open {
+		t.Fatalf("Tistory.UnansweredComments() = %+v", unanswered)
+	}
+
+	if _, err := tst.ReplyComment(unanswered[0], "a2", false); err != nil {
+		t.Fatalf("Tistory.ReplyComment() error = %v", err)
+	}
+	threads, err := tst.GetCommentThreads(open)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(threads) != 1 || threads[0].ReplyCount() != 1 || threads[0].Replies[0].Content != "a2" {
+		t.Errorf("threads after reply = %+v", threads)
+	}
+
+	// A reply to a reply is attached to the top-level comment.
+	if _, err := tst.ReplyComment(threads[0].Replies[0], "a3", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.Comments(tistorytest.DefaultBlog, open); got[len(got)-1].ParentID != threads[0].Id {
+		t.Errorf("nested reply parent = %d, want %d", got[len(got)-1].ParentID, threads[0].Id)
+	}
+}
@@ -0,0 +1,301 @@
+// Package config loads named Tistory profiles from a YAML file.
+//
+// The default file is $XDG_CONFIG_HOME/tistory/config.yaml
+// (~/.config/tistory/config.yaml on Linux):
+//
+//	default: main
+//	profiles:
+//	  main:
+//	    blog_url: https://myblog.tistory.com
+//	    client_id: env:TISTORY_CLIENT_ID
+//	    client_secret: file:~/.secrets/tistory
+//	    defaults:
+//	      category: 1043527
+//	      visibility: 3
+//	  notes:
+//	    blog_url: https://notes.example.com
+//	    blog_name: notes
+//	    client_id: 0123456789abcdef
+//	    client_secret: env:NOTES_CLIENT_SECRET
+//	    token_store: ~/.cache/tistory/notes.json
+//
+// client_id and client_secret are either literal values or references:
+// "env:NAME" reads an environment variable and "file:PATH" reads a file
+// (relative paths are relative to the config file).
+// Access tokens are kept outside the config file in a per-profile token store
+// (default: tokens/<profile>.json next to the config file; a relative
+// token_store is relative to the config file too).
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is used when neither the caller nor the file names a profile.
+const DefaultProfile = "default"
+
+// Config is the content of a config file.
+type Config struct {
+	// Default is the profile used when no name is given.
+	Default  string              `yaml:"default,omitempty"`
+	Profiles map[string]*Profile `yaml:"profiles"`
+
+	path string
+}
+
+// Profile describes one blog and how to authenticate against it.
+type Profile struct {
+	// Name is the key of the profile in Config.Profiles.
+	Name string `yaml:"-"`
+
+	BlogURL string `yaml:"blog_url"`
+	// BlogName is needed only when BlogURL is a custom domain and the
+	// name should not be looked up through blog/info.
+	BlogName     string `yaml:"blog_name,omitempty"`
+	ClientID     string `yaml:"client_id,omitempty"`
+	ClientSecret string `yaml:"client_secret,omitempty"`
+	// TokenStore is the file holding the access token, relative to the
+	// config file unless absolute.
+	TokenStore string `yaml:"token_store,omitempty"`
+	// Defaults are applied to new posts written through this profile.
+	Defaults Defaults `yaml:"defaults,omitempty"`
+
+	dir string
+}
+
+// Defaults are post options used when the caller does not set them.
+type Defaults struct {
+	Category int `yaml:"category,omitempty"`
+	// Visibility is a pointer so that 0 (비공개) can be a default.
+	Visibility *int `yaml:"visibility,omitempty"`
+}
+
+// DefaultPath returns $TISTORY_CONFIG or <user config dir>/tistory/config.yaml.
+func DefaultPath() (string, error) {
+	if path := os.Getenv("TISTORY_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tistory", "config.yaml"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty Config
+// that Save will create.
+func Load(path string) (*Config, error) {
+	path = expandHome(path)
+	cfg := &Config{Profiles: map[string]*Profile{}, path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read config")
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse config %s", path)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	for name, p := range cfg.Profiles {
+		if p == nil {
+			return nil, errors.Errorf("profile %q in %s is empty", name, path)
+		}
+		p.Name = name
+		p.dir = filepath.Dir(path)
+	}
+	return cfg, nil
+}
+
+// LoadDefault reads the config file at DefaultPath.
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// NewTistory builds a client for the named profile of the default config file.
+func NewTistory(profile string) (*tistory.Tistory, error) {
+	cfg, err := LoadDefault()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewTistory(profile)
+}
+
+// Path returns the file the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Names returns the profile names in alphabetical order.
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileName resolves an empty name to the file's default profile.
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if c.Default != "" {
+		return c.Default
+	}
+	return DefaultProfile
+}
+
+// Profile returns the named profile, or the default profile when name is empty.
+func (c *Config) Profile(name string) (*Profile, error) {
+	name = c.ProfileName(name)
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, errors.Errorf("profile %q not found in %s", name, c.path)
+	}
+	return p, nil
+}
+
+// Set adds or replaces a profile.
+func (c *Config) Set(p *Profile) {
+	p.dir = filepath.Dir(c.path)
+	c.Profiles[p.Name] = p
+}
+
+// NewTistory builds a client for the named profile.
+func (c *Config) NewTistory(name string) (*tistory.Tistory, error) {
+	p, err := c.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	return p.NewTistory()
+}
+
+// Save writes the config back to the file it was loaded from.
+func (c *Config) Save() error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o600)
+}
+
+// NewProfile returns an empty profile stored next to the config file at path.
+func NewProfile(name, path string) *Profile {
+	return &Profile{Name: name, dir: filepath.Dir(expandHome(path))}
+}
+
+// Credentials resolves the client ID and secret references.
+func (p *Profile) Credentials() (clientId, clientSecret string, err error) {
+	if clientId, err = resolve(p.ClientID, p.dir); err != nil {
+		return "", "", errors.Wrapf(err, "profile %q client_id", p.Name)
+	}
+	if clientSecret, err = resolve(p.ClientSecret, p.dir); err != nil {
+		return "", "", errors.Wrapf(err, "profile %q client_secret", p.Name)
+	}
+	return clientId, clientSecret, nil
+}
+
+// TokenPath returns the token store file of the profile.
+func (p *Profile) TokenPath() string {
+	if p.TokenStore != "" {
+		path := expandHome(p.TokenStore)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(p.dir, path)
+		}
+		return path
+	}
+	return filepath.Join(p.dir, "tokens", p.Name+".json")
+}
+
+// NewTistory builds a client from the profile. The access token is read from
+// the token store; a missing store leaves AccessToken empty.
+func (p *Profile) NewTistory() (*tistory.Tistory, error) {
+	clientId, clientSecret, err := p.Credentials()
+	if err != nil {
+		return nil, err
+	}
+	t, err := tistory.NewTistoryWithBlogName(p.BlogURL, p.BlogName, clientId, clientSecret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "profile %q", p.Name)
+	}
+
+	token, err := LoadToken(p.TokenPath())
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		return nil, err
+	}
+	t.AccessToken = token.AccessToken
+	return t, nil
+}
+
+// ApplyDefaults sets the profile's default category and visibility on a
+// WritePost option unless the option already has them.
+func (p *Profile) ApplyDefaults(option map[string]interface{}) {
+	if _, ok := option["category"]; !ok && p.Defaults.Category != 0 {
+		option["category"] = p.Defaults.Category
+	}
+	if _, ok := option["visibility"]; !ok && p.Defaults.Visibility != nil {
+		option["visibility"] = *p.Defaults.Visibility
+	}
+}
+
+// Resolve returns the value of a reference: "env:NAME" reads an environment
+// variable, "file:PATH" reads a file and anything else is returned as is.
+func Resolve(ref string) (string, error) {
+	return resolve(ref, "")
+}
+
+// resolve is Resolve with relative file paths taken relative to dir.
+func resolve(ref, dir string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, "file:"):
+		path := expandHome(strings.TrimPrefix(ref, "file:"))
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return ref, nil
+	}
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
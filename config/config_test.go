@@ -0,0 +1,147 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testConfig = `default: main
+profiles:
+  main:
+    blog_url: https://main.tistory.com
+    client_id: main-id
+    client_secret: env:TEST_TISTORY_SECRET
+    defaults:
+      category: 7
+      visibility: 3
+  notes:
+    blog_url: https://notes.tistory.com
+    client_id: notes-id
+    client_secret: file:secret.txt
+    token_store: notes-token.json
+`
+
+func writeConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("notes-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfig_NewTistory(t *testing.T) {
+	path := writeConfig(t)
+	t.Setenv("TEST_TISTORY_SECRET", "main-secret")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		wantBlog   string
+		wantSecret string
+		wantToken  string
+		wantErr    bool
+	}{
+		{name: "default profile", profile: "", wantBlog: "main", wantSecret: "main-secret", wantToken: "main-token"},
+		{name: "named profile", profile: "notes", wantBlog: "notes", wantSecret: "notes-secret", wantToken: "notes-token"},
+		{name: "unknown profile", profile: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if p, err := cfg.Profile(tt.profile); err == nil {
+				if err := SaveToken(p.TokenPath(), tt.wantBlog+"-token"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := cfg.NewTistory(tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Config.NewTistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.BlogName != tt.wantBlog || got.ClientSecret != tt.wantSecret || got.AccessToken != tt.wantToken {
+				t.Errorf("Config.NewTistory() = %+v", got)
+			}
+		})
+	}
+
+	main, _ := cfg.Profile("")
+	if want := filepath.Join(filepath.Dir(path), "tokens", "main.json"); main.TokenPath() != want {
+		t.Errorf("Profile.TokenPath() = %v, want %v", main.TokenPath(), want)
+	}
+	notes, _ := cfg.Profile("notes")
+	if want := filepath.Join(filepath.Dir(path), "notes-token.json"); notes.TokenPath() != want {
+		t.Errorf("Profile.TokenPath() of a relative token_store = %v, want %v", notes.TokenPath(), want)
+	}
+}
+
+func TestConfig_MissingSecret(t *testing.T) {
+	cfg, err := Load(writeConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_TISTORY_SECRET", "")
+	os.Unsetenv("TEST_TISTORY_SECRET")
+	if _, err := cfg.NewTistory("main"); err == nil {
+		t.Errorf("Config.NewTistory() with unset env reference error = nil, want error")
+	}
+}
+
+func TestConfig_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of missing file error = %v", err)
+	}
+	p := NewProfile("blog", path)
+	p.BlogURL = "https://blog.tistory.com"
+	p.ClientID = "id"
+	cfg.Set(p)
+	cfg.Default = "blog"
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.Profile("")
+	if err != nil || got.BlogURL != p.BlogURL || got.Name != "blog" {
+		t.Errorf("Load() profile = %+v, %v", got, err)
+	}
+}
+
+func TestProfile_ApplyDefaults(t *testing.T) {
+	public, private := 3, 0
+	p := &Profile{Defaults: Defaults{Category: 7, Visibility: &public}}
+	option := map[string]interface{}{"title": "title", "visibility": 0}
+	p.ApplyDefaults(option)
+	if option["category"] != 7 || option["visibility"] != 0 {
+		t.Errorf("ApplyDefaults() = %v", option)
+	}
+
+	// A private default is applied, unlike an unset one.
+	p.Defaults.Visibility = &private
+	option = map[string]interface{}{"title": "title"}
+	p.ApplyDefaults(option)
+	if v, ok := option["visibility"]; !ok || v != 0 {
+		t.Errorf("ApplyDefaults() with a private default = %v", option)
+	}
+	p.Defaults.Visibility = nil
+	option = map[string]interface{}{"title": "title"}
+	p.ApplyDefaults(option)
+	if _, ok := option["visibility"]; ok {
+		t.Errorf("ApplyDefaults() without a default = %v", option)
+	}
+}
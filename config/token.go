@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Token is the content of a token store file.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LoadToken reads a token store file. The returned error wraps
+// os.ErrNotExist when the file is missing.
+func LoadToken(path string) (Token, error) {
+	var token Token
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return token, errors.Wrap(err, "Failed to read token store")
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return token, errors.Wrapf(err, "Failed to parse token store %s", path)
+	}
+	return token, nil
+}
+
+// SaveToken writes accessToken to a token store file readable only by the user.
+func SaveToken(path, accessToken string) error {
+	data, err := json.MarshalIndent(Token{AccessToken: accessToken, CreatedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
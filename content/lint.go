@@ -0,0 +1,167 @@
+package content
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Severity of a lint Issue.
+type Severity string
+
+const (
+	// SeverityError marks content that Sanitize would remove.
+	SeverityError Severity = "error"
+	// SeverityWarning marks content that publishes but is probably wrong.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found by Lint.
+type Issue struct {
+	Line     int
+	Severity Severity
+	Rule     string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%d: %s: %s (%s)", i.Line, i.Severity, i.Message, i.Rule)
+}
+
+/*
+Lint reports the problems of an HTML body before it is published, by line:
+
+	disallowed-element    element p drops (script, style, form, ...) or an iframe from another host
+	event-handler         on* attribute
+	unsafe-url            href or src with a scheme p does not allow (javascript:, data:)
+	style-property        style declaration p drops
+	insecure-url          http:// link or image (mixed content on https blogs)
+	local-image           image with a relative path that was never uploaded
+	external-image        image hotlinked from another site
+	image-alt             image without alt text
+	heading-h1            <h1>, which is the post title's level
+	heading-skip          heading more than one level below the previous one
+	code-language         code block without a language
+*/
+func Lint(s string, p *Policy) []Issue {
+	var issues []Issue
+	line := 1
+	add := func(severity Severity, rule, format string, args ...interface{}) {
+		issues = append(issues, Issue{Line: line, Severity: severity, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	lastHeading := 0
+	var pre *preState
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				add(SeverityError, "parse", "%v", z.Err())
+			}
+			break
+		}
+		raw := string(z.Raw())
+		if tt == html.StartTagToken || tt == html.SelfClosingTagToken {
+			token := z.Token()
+			lintTag(token, p, add, &lastHeading)
+			if token.Data == "pre" && pre == nil && tt == html.StartTagToken {
+				pre = &preState{line: line, language: tokenLanguage(token)}
+			} else if token.Data == "code" && pre != nil && pre.language == "" {
+				pre.language = tokenLanguage(token)
+			}
+		}
+		if tt == html.EndTagToken && pre != nil && z.Token().Data == "pre" {
+			if pre.language == "" {
+				issues = append(issues, Issue{Line: pre.line, Severity: SeverityWarning, Rule: "code-language",
+					Message: "code block without a language (add class=\"language-X\")"})
+			}
+			pre = nil
+		}
+		line += strings.Count(raw, "\n")
+	}
+	return issues
+}
+
+// preState tracks an open <pre> for the code-language rule.
+type preState struct {
+	line     int
+	language string
+}
+
+func tokenLanguage(token html.Token) string {
+	n := &html.Node{Type: html.ElementNode, Data: token.Data, Attr: token.Attr}
+	return CodeLanguage(n)
+}
+
+func lintTag(token html.Token, p *Policy, add func(Severity, string, string, ...interface{}), lastHeading *int) {
+	name := token.Data
+	switch {
+	case p.Drop[name]:
+		add(SeverityError, "disallowed-element", "<%s> is removed with its content", name)
+	case name == "iframe" && !p.AllowedIframe(attr(token, "src")):
+		add(SeverityError, "disallowed-element", "<iframe> from %q is not an allowed embed", attr(token, "src"))
+	case !p.AllowedElement(name):
+		add(SeverityWarning, "disallowed-element", "<%s> is unwrapped", name)
+	}
+
+	for _, a := range token.Attr {
+		switch {
+		case strings.HasPrefix(a.Key, "on"):
+			add(SeverityError, "event-handler", "%s attribute on <%s>", a.Key, name)
+		case (a.Key == "href" || a.Key == "src") && !p.SafeURL(a.Val):
+			add(SeverityError, "unsafe-url", "%s=%q on <%s>", a.Key, truncate(a.Val, 40), name)
+		case a.Key == "style":
+			if _, dropped := p.CleanStyle(a.Val); len(dropped) > 0 {
+				add(SeverityWarning, "style-property", "style %s on <%s> is removed", strings.Join(dropped, ", "), name)
+			}
+		}
+		if (a.Key == "href" || a.Key == "src") && strings.HasPrefix(strings.ToLower(strings.TrimSpace(a.Val)), "http://") {
+			add(SeverityWarning, "insecure-url", "http:// %s on <%s>: %s", a.Key, name, a.Val)
+		}
+	}
+
+	switch level := headingLevel(&html.Node{Type: html.ElementNode, Data: name}); {
+	case name == "img":
+		src := strings.TrimSpace(attr(token, "src"))
+		u, err := url.Parse(src)
+		switch {
+		case src == "" || err != nil:
+			add(SeverityWarning, "local-image", "<img> without a valid src")
+		case u.Host == "" && u.Scheme == "":
+			add(SeverityWarning, "local-image", "image %q is a local file; upload it with AttachPost", src)
+		case u.Scheme != "data" && !isTistoryHost(u.Hostname()):
+			add(SeverityWarning, "external-image", "image hotlinked from %s", u.Host)
+		}
+		if _, ok := attrOk(token, "alt"); !ok {
+			add(SeverityWarning, "image-alt", "image %q has no alt text", truncate(src, 40))
+		}
+	case level == 1:
+		add(SeverityWarning, "heading-h1", "<h1> is the level of the post title; start at <h2>")
+		*lastHeading = 1
+	case level > 0:
+		if *lastHeading > 0 && level > *lastHeading+1 {
+			add(SeverityWarning, "heading-skip", "<h%d> follows <h%d>", level, *lastHeading)
+		}
+		*lastHeading = level
+	}
+}
+
+func attrOk(token html.Token, key string) (string, bool) {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n]) + "…"
+	}
+	return s
+}
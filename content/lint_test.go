@@ -0,0 +1,39 @@
+package content
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	in := `<h1>title</h1>
+<h4 style="position: fixed">deep</h4>
+<p onclick="x()"><a href="javascript:void(0)">x</a> <a href="http://old.example.com">old</a></p>
+<script>alert(1)</script>
+<img src="images/cat.png" alt="cat">
+<img src="https://example.com/hot.png">
+<img src="https://blog.kakaocdn.net/dn/k/img.png" alt="">
+<pre><code>no language</code></pre>
+<pre><code class="language-go">ok</code></pre>`
+
+	var got []string
+	for _, issue := range Lint(in, DefaultPolicy()) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"1: warning: <h1> is the level of the post title; start at <h2> (heading-h1)",
+		"2: warning: style position on <h4> is removed (style-property)",
+		"2: warning: <h4> follows <h1> (heading-skip)",
+		"3: error: onclick attribute on <p> (event-handler)",
+		`3: error: href="javascript:void(0)" on <a> (unsafe-url)`,
+		"3: warning: http:// href on <a>: http://old.example.com (insecure-url)",
+		"4: error: <script> is removed with its content (disallowed-element)",
+		`5: warning: image "images/cat.png" is a local file; upload it with AttachPost (local-image)`,
+		"6: warning: image hotlinked from example.com (external-image)",
+		`6: warning: image "https://example.com/hot.png" has no alt text (image-alt)`,
+		`8: warning: code block without a language (add class="language-X") (code-language)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() =\n%q\nwant\n%q", got, want)
+	}
+}
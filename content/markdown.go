@@ -0,0 +1,619 @@
+package content
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// MarkdownOptions customizes Markdown.
+type MarkdownOptions struct {
+	// Image maps the URL of each image, e.g. to a downloaded file next to
+	// the Markdown file. nil keeps the URLs.
+	Image func(src string) string
+}
+
+/*
+Markdown converts the HTML body of a Tistory post into CommonMark:
+
+	[##_Image|kage@KEY|..._##]       ![alt](https://blog.kakaocdn.net/dn/KEY), caption below
+	[##_File|kage@KEY|..._##]        [filename](https://blog.kakaocdn.net/dn/KEY)
+	<pre data-ke-language="go">      fenced code block with the language
+	<div data-ke-type="moreLess">    <details><summary>더보기</summary> ... </details>
+	<figure data-ke-type="opengraph"> link to the previewed page
+	<table>                          pipe table, or HTML when cells span or hold blocks
+
+Other replacers are kept as text, and embeds (<iframe>, <video>) as HTML.
+Tables use the GitHub extension, which most Markdown renderers support.
+*/
+func Markdown(s string, options *MarkdownOptions) string {
+	if options == nil {
+		options = &MarkdownOptions{}
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), root)
+	if err != nil {
+		return s
+	}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	expandReplacers(root)
+
+	m := &markdown{options: options}
+	out := strings.Join(m.blocks(root), "\n\n")
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+// FileURL returns the URL of a replacer file, kage@KEY or cfile@KEY.
+func FileURL(file string) string {
+	switch {
+	case strings.HasPrefix(file, "kage@"):
+		return "https://blog.kakaocdn.net/dn/" + strings.TrimPrefix(file, "kage@")
+	case strings.HasPrefix(file, "cfile@"):
+		return "https://t1.daumcdn.net/cfile/tistory/" + strings.TrimPrefix(file, "cfile@")
+	}
+	return file
+}
+
+// expandReplacers replaces the image and file replacers in the text of root
+// with <img> and <a> elements.
+func expandReplacers(root *html.Node) {
+	var texts []*html.Node
+	walk(root, func(n *html.Node) {
+		if n.Type == html.TextNode && Replacer.MatchString(n.Data) && !inside(n, "pre") {
+			texts = append(texts, n)
+		}
+	})
+	for _, t := range texts {
+		insert := func(n *html.Node) { t.Parent.InsertBefore(n, t) }
+		last := 0
+		for _, m := range Replacer.FindAllStringSubmatchIndex(t.Data, -1) {
+			insert(&html.Node{Type: html.TextNode, Data: t.Data[last:m[0]]})
+			nodes := replacerNodes(t.Data[m[2]:m[3]], t.Data[m[4]:m[5]])
+			if nodes == nil {
+				nodes = []*html.Node{{Type: html.TextNode, Data: t.Data[m[0]:m[1]]}}
+			}
+			for _, n := range nodes {
+				insert(n)
+			}
+			last = m[1]
+		}
+		insert(&html.Node{Type: html.TextNode, Data: t.Data[last:]})
+		t.Parent.RemoveChild(t)
+	}
+}
+
+// replacerNodes returns the elements of a replacer, or nil if it has no
+// image or file.
+func replacerNodes(kind, args string) []*html.Node {
+	isImage := kind == "Image" || kind == "ImageGrid" || kind == "Gallery" || kind[0] >= '0' && kind[0] <= '9'
+	if !isImage && kind != "File" {
+		return nil
+	}
+	segments := strings.Split(args, "|")
+	var nodes []*html.Node
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "kage@") && !strings.HasPrefix(segment, "cfile@") {
+			continue
+		}
+		options := map[string]interface{}{}
+		for _, next := range segments[i+1:] {
+			if strings.HasPrefix(next, "kage@") || strings.HasPrefix(next, "cfile@") {
+				break
+			}
+			if strings.HasPrefix(next, "{") && json.Unmarshal([]byte(next), &options) == nil {
+				break
+			}
+		}
+		option := func(key string) string {
+			s, _ := options[key].(string)
+			return strings.TrimSpace(s)
+		}
+
+		if len(nodes) > 0 {
+			nodes = append(nodes, &html.Node{Type: html.TextNode, Data: " "})
+		}
+		if kind == "File" {
+			name := option("filename")
+			if name == "" {
+				name = path.Base(segment)
+			}
+			a := element("a", "href", FileURL(segment))
+			a.AppendChild(&html.Node{Type: html.TextNode, Data: name})
+			nodes = append(nodes, a)
+			continue
+		}
+		nodes = append(nodes, element("img", "src", FileURL(segment), "alt", option("alt")))
+		if caption := option("caption"); caption != "" {
+			em := element("em")
+			em.AppendChild(&html.Node{Type: html.TextNode, Data: caption})
+			nodes = append(nodes, element("br"), em)
+		}
+	}
+	return nodes
+}
+
+func element(name string, attrs ...string) *html.Node {
+	n := &html.Node{Type: html.ElementNode, Data: name, DataAtom: atom.Lookup([]byte(name))}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		setAttr(n, attrs[i], attrs[i+1])
+	}
+	return n
+}
+
+// markdown converts a parsed body. Inline text marks hard line breaks with
+// lineBreak until a block is finished.
+type markdown struct {
+	options *MarkdownOptions
+}
+
+const lineBreak = "\x00"
+
+var (
+	lineBreaks     = regexp.MustCompile(` *\x00 *`)
+	listItemStart  = regexp.MustCompile(`^(-|\d+\.)( |$)`)
+	blockLineStart = regexp.MustCompile(`^(#|>|[-+=] |[-+=]$|(\d+)([.)]))`)
+	textEscaper    = strings.NewReplacer(`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`)
+	cellEscaper    = strings.NewReplacer(`|`, `\|`, "\n", "<br>")
+)
+
+// blocks converts the children of parent. Runs of inline children become
+// paragraphs.
+func (m *markdown) blocks(parent *html.Node) []string {
+	var blocks []string
+	var inline strings.Builder
+	flush := func() {
+		if text := finishInline(inline.String()); text != "" {
+			blocks = append(blocks, escapeLineStarts(text))
+		}
+		inline.Reset()
+	}
+	for c := parent.FirstChild; c != nil; c = c.NextSibling {
+		if !isBlock(c) {
+			inline.WriteString(m.inline(c))
+			continue
+		}
+		flush()
+		if block := m.block(c); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+	return blocks
+}
+
+func isBlock(n *html.Node) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	return n.Data != "br" && blockTags[n.Data] || n.Data == "details" || n.Data == "summary"
+}
+
+// finishInline trims inline text and turns the line break marks into
+// backslash hard breaks.
+func finishInline(s string) string {
+	s = strings.Trim(s, " \n"+lineBreak)
+	return lineBreaks.ReplaceAllString(s, "\\\n")
+}
+
+// escapeLineStarts escapes the text at the start of lines that would
+// otherwise begin a heading, quote or list.
+func escapeLineStarts(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if m := blockLineStart.FindStringSubmatchIndex(line); m != nil {
+			if m[4] >= 0 {
+				lines[i] = line[:m[6]] + `\` + line[m[6]:]
+			} else {
+				lines[i] = `\` + line
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *markdown) block(n *html.Node) string {
+	if level := headingLevel(n); level > 0 {
+		text := strings.ReplaceAll(finishInline(m.inlineChildren(n)), "\\\n", " ")
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+	}
+	switch n.Data {
+	case "pre":
+		return codeBlock(n)
+	case "ul", "ol":
+		return m.list(n)
+	case "blockquote":
+		return prefixLines(strings.Join(m.blocks(n), "\n\n"), "> ")
+	case "hr":
+		return "---"
+	case "table":
+		return m.table(n)
+	case "figcaption":
+		if text := finishInline(m.inlineChildren(n)); text != "" {
+			return "*" + text + "*"
+		}
+		return ""
+	case "details":
+		var summary string
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type == html.ElementNode && c.Data == "summary" {
+				summary = strings.TrimSpace(textContent(c))
+				n.RemoveChild(c)
+				break
+			}
+		}
+		return m.details(summary, n)
+	}
+	switch nodeAttr(n, "data-ke-type") {
+	case "moreLess":
+		return m.moreLess(n)
+	case "opengraph":
+		if link := m.opengraph(n); link != "" {
+			return link
+		}
+	}
+	return strings.Join(m.blocks(n), "\n\n")
+}
+
+// moreLess converts the fold of the Tistory editor:
+//
+//	<div data-ke-type="moreLess" data-text-more="더보기" data-text-less="닫기">
+//	<a class="btn-toggle-moreless">더보기</a><div class="moreless-content">...</div></div>
+func (m *markdown) moreLess(n *html.Node) string {
+	summary := nodeAttr(n, "data-text-more")
+	body := n
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case hasClass(c, "btn-toggle-moreless"):
+			if summary == "" {
+				summary = strings.TrimSpace(textContent(c))
+			}
+		case hasClass(c, "moreless-content"):
+			body = c
+		}
+	}
+	if body == n {
+		// Without a content element, everything but the toggle is the content.
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if hasClass(c, "btn-toggle-moreless") {
+				n.RemoveChild(c)
+				break
+			}
+		}
+	}
+	return m.details(summary, body)
+}
+
+func (m *markdown) details(summary string, body *html.Node) string {
+	if summary == "" {
+		summary = "더보기"
+	}
+	out := "<details>\n<summary>" + html.EscapeString(summary) + "</summary>\n\n"
+	if content := strings.Join(m.blocks(body), "\n\n"); content != "" {
+		out += content + "\n\n"
+	}
+	return out + "</details>"
+}
+
+// opengraph converts a link preview of the Tistory editor into a link.
+func (m *markdown) opengraph(n *html.Node) string {
+	href := nodeAttr(n, "data-og-url")
+	title := strings.TrimSpace(nodeAttr(n, "data-og-title"))
+	walk(n, func(c *html.Node) {
+		if href == "" && c.Type == html.ElementNode && c.Data == "a" {
+			href = nodeAttr(c, "href")
+		}
+	})
+	if href == "" {
+		return ""
+	}
+	if title == "" {
+		title = href
+	}
+	return "[" + escapeText(title) + "](" + destination(href) + ")"
+}
+
+func hasClass(n *html.Node, class string) bool {
+	if n.Type != html.ElementNode {
+		return false
+	}
+	for _, c := range strings.Fields(nodeAttr(n, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func codeBlock(pre *html.Node) string {
+	language := CodeLanguage(pre)
+	if class := strings.Fields(nodeAttr(pre, "class")); language == "" && len(class) == 1 &&
+		nodeAttr(pre, "data-ke-type") == "codeblock" {
+		// The editor also writes the language as the only class.
+		language = strings.ToLower(class[0])
+	}
+	code := strings.TrimSuffix(textContent(pre), "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + code + "\n" + fence
+}
+
+func (m *markdown) list(n *html.Node) string {
+	ordered := n.Data == "ol"
+	number := 1
+	if start, err := strconv.Atoi(nodeAttr(n, "start")); err == nil && ordered {
+		number = start
+	}
+	var items []string
+	loose := false
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		marker := "-"
+		if ordered {
+			marker = strconv.Itoa(number) + "."
+			number++
+		}
+		var item strings.Builder
+		for i, block := range m.blocks(c) {
+			switch {
+			case i == 0:
+			case listItemStart.MatchString(block):
+				// A nested list follows its text directly.
+				item.WriteString("\n")
+			default:
+				loose = true
+				item.WriteString("\n\n")
+			}
+			item.WriteString(block)
+		}
+		indent := strings.Repeat(" ", len(marker)+1)
+		items = append(items, strings.TrimRight(marker+" "+indentLines(item.String(), indent), " "))
+	}
+	if loose {
+		return strings.Join(items, "\n\n")
+	}
+	return strings.Join(items, "\n")
+}
+
+// indentLines indents every line of s but the first.
+func indentLines(s, indent string) string {
+	lines := strings.Split(s, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func prefixLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// table converts a table into a pipe table with its first row as the
+// header. Tables that a pipe table cannot hold are kept as HTML.
+func (m *markdown) table(n *html.Node) string {
+	var rows [][]string
+	columns := 0
+	simple := true
+	walk(n, func(c *html.Node) {
+		if c.Type != html.ElementNode || !simple {
+			return
+		}
+		switch c.Data {
+		case "table":
+			simple = c == n
+		case "tr":
+			var row []string
+			for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type != html.ElementNode || cell.Data != "td" && cell.Data != "th" {
+					continue
+				}
+				if span(cell, "colspan") > 1 || span(cell, "rowspan") > 1 {
+					simple = false
+					return
+				}
+				if !inlineCell(cell) {
+					simple = false
+					return
+				}
+				text := strings.ReplaceAll(strings.Join(m.blocks(cell), "\n"), "\\\n", "\n")
+				row = append(row, cellEscaper.Replace(text))
+			}
+			rows = append(rows, row)
+			columns = max(columns, len(row))
+		}
+	})
+	if !simple || len(rows) == 0 || columns == 0 {
+		return m.rawHTML(n)
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		for i := 0; i < columns; i++ {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			b.WriteString("| " + cell + " ")
+		}
+		b.WriteString("|\n")
+	}
+	writeRow(rows[0])
+	b.WriteString(strings.Repeat("| --- ", columns) + "|\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// inlineCell reports whether a table cell holds only paragraphs of inline
+// content, which fit in a pipe table cell.
+func inlineCell(cell *html.Node) bool {
+	ok := true
+	walk(cell, func(c *html.Node) {
+		if c != cell && isBlock(c) && c.Data != "p" && c.Data != "div" {
+			ok = false
+		}
+	})
+	return ok
+}
+
+func span(cell *html.Node, key string) int {
+	n, _ := strconv.Atoi(nodeAttr(cell, key))
+	return n
+}
+
+// rawHTML renders n as an HTML block, with the images mapped and without
+// blank lines, which would end the block.
+func (m *markdown) rawHTML(n *html.Node) string {
+	walk(n, func(c *html.Node) {
+		if c.Type == html.ElementNode && c.Data == "img" {
+			setAttr(c, "src", m.image(nodeAttr(c, "src")))
+		}
+	})
+	var b strings.Builder
+	html.Render(&b, n)
+	var lines []string
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *markdown) image(src string) string {
+	if m.options.Image == nil || src == "" {
+		return src
+	}
+	return m.options.Image(src)
+}
+
+func (m *markdown) inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(m.inline(c))
+	}
+	return b.String()
+}
+
+func (m *markdown) inline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		text := strings.ReplaceAll(n.Data, " ", " ")
+		return escapeText(spaces.ReplaceAllString(text, " "))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.Data {
+	case "br":
+		return lineBreak
+	case "strong", "b":
+		return wrap("**", m.inlineChildren(n))
+	case "em", "i":
+		return wrap("*", m.inlineChildren(n))
+	case "del", "s", "strike":
+		return wrap("~~", m.inlineChildren(n))
+	case "code", "kbd", "tt":
+		return codeSpan(textContent(n))
+	case "a":
+		text := strings.Trim(m.inlineChildren(n), " ")
+		href := nodeAttr(n, "href")
+		if href == "" {
+			return text
+		}
+		if text == "" {
+			text = escapeText(href)
+		}
+		return "[" + text + "](" + destination(href) + title(nodeAttr(n, "title")) + ")"
+	case "img":
+		return "![" + escapeText(nodeAttr(n, "alt")) + "](" + destination(m.image(nodeAttr(n, "src"))) +
+			title(nodeAttr(n, "title")) + ")"
+	case "iframe", "video", "audio", "sup", "sub":
+		return m.rawHTML(n)
+	case "script", "style", "noscript", "template":
+		return ""
+	}
+	text := m.inlineChildren(n)
+	if isBlock(n) {
+		// A block inside inline content, such as <div> in <a>.
+		return " " + text + " "
+	}
+	return text
+}
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// wrap puts delimiters around text, keeping its surrounding spaces outside.
+func wrap(delimiter, text string) string {
+	trimmed := strings.Trim(text, " ")
+	if trimmed == "" {
+		return text
+	}
+	lead := text[:strings.Index(text, trimmed)]
+	trail := text[len(lead)+len(trimmed):]
+	return lead + delimiter + trimmed + delimiter + trail
+}
+
+func codeSpan(code string) string {
+	code = spaces.ReplaceAllString(code, " ")
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+	longest, run := 0, 0
+	for _, r := range code {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// destination formats a link destination, in angle brackets when it holds
+// characters that would end it.
+func destination(u string) string {
+	u = strings.TrimSpace(u)
+	if strings.ContainsAny(u, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(u) + ">"
+	}
+	return u
+}
+
+func title(t string) string {
+	if t = strings.TrimSpace(t); t == "" {
+		return ""
+	}
+	return ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(t) + `"`
+}
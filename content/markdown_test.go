@@ -0,0 +1,75 @@
+package content
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "inline",
+			in: `<h2 data-ke-size="size26">제목</h2><p data-ke-size="size16">안녕 <b>굵게 </b>and <i>it</i> ` +
+				`<a href="https://a.test/x (1)" title="t">link</a> 1*2 <code>a` + "`" + `b</code><br>다음&nbsp;줄</p>` +
+				`<p data-ke-size="size16">&nbsp;</p><p>- not a list</p><p>1. not ordered</p>`,
+			want: "## 제목\n\n안녕 **굵게** and *it* [link](<https://a.test/x (1)> \"t\") 1\\*2 ``a`b``\\\n다음 줄\n\n" +
+				"\\- not a list\n\n1\\. not ordered\n",
+		},
+		{
+			name: "replacers",
+			in: `<p>[##_Image|kage@bX/img.png|CDM|1.3|{"originWidth":640,"alt":"고양이","caption":"캡션"}_##]</p>` +
+				`<p>[##_ImageGrid|kage@a/1.png|CDM|1.3|{}|cfile@99AB|CDM|1.3|{}_##]</p>` +
+				`<p>[##_File|kage@f/a.zip|{"filename":"a.zip"}_##] [##_Video|x_##]</p>`,
+			want: "![고양이](https://blog.kakaocdn.net/dn/bX/img.png)\\\n*캡션*\n\n" +
+				"![](https://blog.kakaocdn.net/dn/a/1.png) ![](https://t1.daumcdn.net/cfile/tistory/99AB)\n\n" +
+				"[a.zip](https://blog.kakaocdn.net/dn/f/a.zip) \\[##\\_Video|x\\_##\\]\n",
+		},
+		{
+			name: "code block",
+			in:   "<pre class=\"go\" data-ke-language=\"go\" data-ke-type=\"codeblock\"><code>fmt.Println(\"```\")\n</code></pre><pre class=\"bash\" data-ke-type=\"codeblock\"><code>ls</code></pre>",
+			want: "````go\nfmt.Println(\"```\")\n````\n\n```bash\nls\n```\n",
+		},
+		{
+			name: "more less",
+			in: `<div data-ke-type="moreLess" data-text-more="펼치기" data-text-less="닫기"><a class="btn-toggle-moreless">펼치기</a>` +
+				`<div class="moreless-content"><p>숨김 &lt;b&gt;</p></div></div>`,
+			want: "<details>\n<summary>펼치기</summary>\n\n숨김 \\<b>\n\n</details>\n",
+		},
+		{
+			name: "lists and quotes",
+			in: `<ul><li>a<ul><li>b</li></ul></li><li>c</li></ul><ol start="3"><li><p>x</p><p>y</p></li><li>z</li></ol>` +
+				`<blockquote data-ke-style="style2">인용<br>둘째</blockquote><hr data-ke-style="style5">`,
+			want: "- a\n  - b\n- c\n\n3. x\n\n   y\n\n4. z\n\n> 인용\\\n> 둘째\n\n---\n",
+		},
+		{
+			name: "tables",
+			in: `<table border="1"><tbody><tr><td>이름</td><td>값</td></tr><tr><td>a|b</td><td>1<br>2</td></tr><tr><td>c</td></tr></tbody></table>` +
+				`<table><tbody><tr><td colspan="2"><img src="https://blog.kakaocdn.net/dn/k/x.png"></td></tr></tbody></table>`,
+			want: "| 이름 | 값 |\n| --- | --- |\n| a\\|b | 1<br>2 |\n| c |  |\n\n" +
+				`<table><tbody><tr><td colspan="2"><img src="x.png"/></td></tr></tbody></table>` + "\n",
+		},
+		{
+			name: "embeds",
+			in: `<figure data-ke-type="opengraph" data-og-title="OG [1]" data-og-url="https://og.test"><a href="https://og.test">x</a></figure>` +
+				`<figure data-ke-type="video"><iframe src="https://www.youtube.com/embed/x"></iframe></figure><script>x()</script>`,
+			want: "[OG \\[1\\]](https://og.test)\n\n<iframe src=\"https://www.youtube.com/embed/x\"></iframe>\n",
+		},
+	}
+	options := &MarkdownOptions{Image: func(src string) string {
+		if strings.HasSuffix(src, "/x.png") {
+			return "x.png"
+		}
+		return src
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(tt.in, options); got != tt.want {
+				t.Errorf("Markdown() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Package content processes the HTML body of Tistory posts: Normalize and Text
+// for comparing and indexing, a publishing pipeline (Sanitize,
+// NormalizeHeadings, NormalizeCodeBlocks, ImageReplacers) checked by Lint,
+// and Markdown for exporting.
+package content
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockTags start on a new line in normalized output.
+var blockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true,
+	"div": true, "dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "ol": true, "p": true, "pre": true, "section": true, "table": true,
+	"tbody": true, "td": true, "tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+var spaces = regexp.MustCompile(`\s+`)
+
+/*
+Normalize rewrites an HTML body into a canonical form for comparison:
+whitespace is collapsed (except in <pre>), attributes are sorted, comments
+are dropped, every block element starts on its own line and images are
+reduced to their file key, so that a Tistory image replacer
+([##_Image|kage@KEY|..._##]) and the <img> rendered from it compare equal.
+*/
+func Normalize(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	pre := 0
+	// blockStart is set after a block tag; leading space of the next text is dropped.
+	blockStart := true
+	newline := func() {
+		out := b.String()
+		if len(out) > 0 && !strings.HasSuffix(out, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				b.Write(z.Raw())
+			}
+			return strings.TrimSpace(b.String()) + "\n"
+		case html.CommentToken, html.DoctypeToken:
+			continue
+		case html.TextToken:
+			text := string(z.Text())
+			if pre > 0 {
+				b.WriteString(html.EscapeString(text))
+				continue
+			}
+			text = spaces.ReplaceAllString(text, " ")
+			if blockStart {
+				text = strings.TrimLeft(text, " ")
+			}
+			if text != "" {
+				blockStart = false
+			}
+			b.WriteString(replaceReplacers(html.EscapeString(text)))
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			token := z.Token()
+			name := token.Data
+			blockStart = blockTags[name]
+			if blockTags[name] && tt != html.EndTagToken {
+				newline()
+			}
+			if tt == html.EndTagToken {
+				trimTrailingSpace(&b)
+			}
+			if name == "pre" {
+				if tt == html.StartTagToken {
+					pre++
+				} else if tt == html.EndTagToken && pre > 0 {
+					pre--
+				}
+			}
+			if name == "img" {
+				b.WriteString(`<img src="` + html.EscapeString(ImageKey(attr(token, "src"))) + `"`)
+				writeAttrs(&b, token.Attr, "src")
+				b.WriteString(">")
+				continue
+			}
+			b.WriteString(renderTag(tt, token))
+			if blockTags[name] && tt == html.EndTagToken {
+				newline()
+			}
+		}
+	}
+}
+
+func trimTrailingSpace(b *strings.Builder) {
+	out := b.String()
+	if trimmed := strings.TrimRight(out, " "); len(trimmed) != len(out) {
+		b.Reset()
+		b.WriteString(trimmed)
+	}
+}
+
+func renderTag(tt html.TokenType, token html.Token) string {
+	var b strings.Builder
+	if tt == html.EndTagToken {
+		return "</" + token.Data + ">"
+	}
+	b.WriteString("<" + token.Data)
+	writeAttrs(&b, token.Attr, "")
+	b.WriteString(">")
+	return b.String()
+}
+
+// writeAttrs writes the attributes sorted by name, skipping skip.
+func writeAttrs(b *strings.Builder, attrs []html.Attribute, skip string) {
+	sorted := append([]html.Attribute(nil), attrs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	for _, a := range sorted {
+		if a.Key == skip {
+			continue
+		}
+		value := strings.TrimSpace(spaces.ReplaceAllString(a.Val, " "))
+		b.WriteString(" " + a.Key + `="` + html.EscapeString(value) + `"`)
+	}
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// Replacer matches a Tistory replacer such as [##_Image|kage@KEY|CDM|1.3|{...}_##].
+var Replacer = regexp.MustCompile(`\[##_(\w+)\|(.*?)_##\]`)
+
+var replacerFile = regexp.MustCompile(`(?:kage|cfile)@([^|\]]+)`)
+
+// replaceReplacers turns the image replacers of text into <img> tags.
+func replaceReplacers(text string) string {
+	return Replacer.ReplaceAllStringFunc(text, func(r string) string {
+		files := replacerFile.FindAllStringSubmatch(r, -1)
+		if len(files) == 0 {
+			return r
+		}
+		var b strings.Builder
+		for _, f := range files {
+			b.WriteString(`<img src="` + f[1] + `">`)
+		}
+		return b.String()
+	})
+}
+
+/*
+ImageKey returns the file key of a Tistory hosted image URL, so that
+different CDN URLs of the same upload compare equal:
+
+	https://blog.kakaocdn.net/dn/KEY                → KEY
+	https://img1.daumcdn.net/thumb/R800x0/?fname=U  → ImageKey(U)
+	https://t1.daumcdn.net/cfile/tistory/KEY        → KEY
+	https://cfile1.uf.tistory.com/image/KEY         → KEY
+	kage@KEY, cfile@KEY                             → KEY
+
+Other URLs are returned unchanged.
+*/
+func ImageKey(src string) string {
+	src = strings.TrimSpace(src)
+	for _, prefix := range []string{"kage@", "cfile@"} {
+		if strings.HasPrefix(src, prefix) {
+			return strings.TrimPrefix(src, prefix)
+		}
+	}
+	u, err := url.Parse(src)
+	if err != nil || u.Host == "" {
+		return src
+	}
+	if fname := u.Query().Get("fname"); fname != "" && strings.HasSuffix(u.Host, "daumcdn.net") {
+		return ImageKey(fname)
+	}
+	for _, prefix := range []string{"/dn/", "/cfile/tistory/", "/image/"} {
+		if i := strings.Index(u.Path, prefix); i >= 0 && isTistoryHost(u.Host) {
+			return u.Path[i+len(prefix):]
+		}
+	}
+	return src
+}
+
+func isTistoryHost(host string) bool {
+	for _, suffix := range []string{"kakaocdn.net", "daumcdn.net", "tistory.com"} {
+		if host == suffix || strings.HasSuffix(host, "."+suffix) {
+			return true
+		}
+	}
+	return false
+}
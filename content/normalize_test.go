@@ -0,0 +1,48 @@
+package content
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{
+			name: "whitespace and attribute order",
+			a:    `<p class="x"  id="y">Hello,   <b>world</b> </p><p>second</p>`,
+			b:    "<p id=\"y\" class=\"x\">\n  Hello, <b>world</b>\n</p>\n\n<p>second</p>\n",
+		},
+		{
+			name: "replacer and rendered image",
+			a:    `<p>[##_Image|kage@bWp8/abc/img.png|CDM|1.3|{"originWidth":800}_##]</p>`,
+			b:    `<p><img src="https://blog.kakaocdn.net/dn/bWp8/abc/img.png"></p>`,
+		},
+		{
+			name: "thumbnail url",
+			a:    `<img src="https://img1.daumcdn.net/thumb/R800x0/?fname=https%3A%2F%2Fblog.kakaocdn.net%2Fdn%2Fk%2Fimg.png" alt="cat">`,
+			b:    `<img alt="cat" src="https://blog.kakaocdn.net/dn/k/img.png">`,
+		},
+		{
+			name: "comments",
+			a:    `<p>a<!-- note --></p>`,
+			b:    `<p>a</p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if a, b := Normalize(tt.a), Normalize(tt.b); a != b {
+				t.Errorf("Normalize() differ:\n%q\n%q", a, b)
+			}
+		})
+	}
+
+	if got := Normalize("<pre>a\n  b</pre>"); got != "<pre>a\n  b</pre>\n" {
+		t.Errorf("Normalize(pre) = %q", got)
+	}
+	if got := Normalize(`<p>one</p><p>two</p>`); got != "<p>one</p>\n<p>two</p>\n" {
+		t.Errorf("Normalize(blocks) = %q", got)
+	}
+	if Normalize(`<p>a</p>`) == Normalize(`<p>b</p>`) {
+		t.Errorf("Normalize() hides a text change")
+	}
+}
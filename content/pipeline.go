@@ -0,0 +1,293 @@
+package content
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Transform rewrites a parsed HTML fragment in place. root is a <body>
+// element holding the fragment.
+type Transform func(root *html.Node)
+
+/*
+Apply parses the HTML fragment s, runs the transforms in order and renders
+the result. Replacers in text are written back verbatim, except for < and >.
+*/
+func Apply(s string, transforms ...Transform) string {
+	root := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(s), root)
+	if err != nil {
+		// The tokenizer only fails on read errors, which a string cannot have.
+		return s
+	}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	for _, t := range transforms {
+		t(root)
+	}
+	return render(root)
+}
+
+/*
+Prepare runs the default pipeline before publishing: Sanitize with
+DefaultPolicy, NormalizeHeadings, NormalizeCodeBlocks and ImageReplacers.
+*/
+func Prepare(s string) string {
+	return Apply(s, DefaultPolicy().Sanitizer(), NormalizeHeadings, NormalizeCodeBlocks, ImageReplacers)
+}
+
+// render renders the children of root, keeping replacers verbatim.
+func render(root *html.Node) string {
+	var b strings.Builder
+	walk(root, func(n *html.Node) {
+		if n.Type == html.TextNode && Replacer.MatchString(n.Data) && !inside(n, "pre") {
+			n.Type, n.Data = html.RawNode, escapeAround(n.Data)
+		}
+	})
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		html.Render(&b, c)
+	}
+	return b.String()
+}
+
+// replacerEscaper escapes the markup characters of a replacer; its quotes
+// are kept for the JSON options.
+var replacerEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;")
+
+// escapeAround escapes text except its replacers, which only have < and >
+// escaped so that markup in them cannot come back as live HTML.
+func escapeAround(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range Replacer.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:m[0]]))
+		b.WriteString(replacerEscaper.Replace(text[m[0]:m[1]]))
+		last = m[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// headingSizes are the data-ke-size values of the Tistory editor headings.
+var headingSizes = map[int]string{2: "size26", 3: "size23", 4: "size20"}
+
+/*
+NormalizeHeadings shifts headings so that the top level used becomes <h2>
+(the post title is the page's <h1>) and caps them at <h4>, the levels of
+the Tistory editor, setting their data-ke-size.
+*/
+func NormalizeHeadings(root *html.Node) {
+	var headings []*html.Node
+	top := 7
+	walk(root, func(n *html.Node) {
+		if level := headingLevel(n); level > 0 {
+			headings = append(headings, n)
+			top = min(top, level)
+		}
+	})
+	for _, h := range headings {
+		level := min(max(headingLevel(h)-top+2, 2), 4)
+		h.Data, h.DataAtom = "h"+strconv.Itoa(level), atom.Lookup([]byte("h"+strconv.Itoa(level)))
+		setAttr(h, "data-ke-size", headingSizes[level])
+	}
+}
+
+func headingLevel(n *html.Node) int {
+	if n.Type != html.ElementNode || len(n.Data) != 2 || n.Data[0] != 'h' || n.Data[1] < '1' || n.Data[1] > '6' {
+		return 0
+	}
+	return int(n.Data[1] - '0')
+}
+
+/*
+NormalizeCodeBlocks rewrites every <pre> into the Tistory editor's code
+block: <pre class="LANG" data-ke-language="LANG" data-ke-type="codeblock">
+with a single <code> holding the plain text, so that markup left by syntax
+highlighters is dropped. The language comes from a language-X or lang-X
+class of the <pre> or <code>, or an existing data-ke-language.
+*/
+func NormalizeCodeBlocks(root *html.Node) {
+	var blocks []*html.Node
+	walk(root, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "pre" && !inside(n.Parent, "pre") {
+			blocks = append(blocks, n)
+		}
+	})
+	for _, pre := range blocks {
+		language := CodeLanguage(pre)
+		text := textContent(pre)
+		for c := pre.FirstChild; c != nil; c = pre.FirstChild {
+			pre.RemoveChild(c)
+		}
+		code := &html.Node{Type: html.ElementNode, Data: "code", DataAtom: atom.Code}
+		code.AppendChild(&html.Node{Type: html.TextNode, Data: strings.TrimSuffix(text, "\n")})
+		pre.AppendChild(code)
+
+		pre.Attr = nil
+		if language != "" {
+			setAttr(pre, "class", language)
+			setAttr(pre, "data-ke-language", language)
+		}
+		setAttr(pre, "data-ke-type", "codeblock")
+	}
+}
+
+// CodeLanguage returns the language of a <pre> code block, or "".
+func CodeLanguage(pre *html.Node) string {
+	if lang := nodeAttr(pre, "data-ke-language"); lang != "" {
+		return lang
+	}
+	candidates := []*html.Node{pre}
+	for c := pre.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "code" {
+			candidates = append(candidates, c)
+		}
+	}
+	for _, n := range candidates {
+		for _, class := range strings.Fields(nodeAttr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if strings.HasPrefix(class, prefix) && len(class) > len(prefix) {
+					return strings.ToLower(class[len(prefix):])
+				}
+			}
+		}
+	}
+	return ""
+}
+
+/*
+ImageReplacers replaces each <img> of an image uploaded to Tistory (see
+ImageKey) with the editor's image replacer, [##_Image|kage@KEY|CDM|1.3|{...}_##].
+A <figure> holding only the image and a <figcaption> becomes one replacer
+with the caption; replacers outside a paragraph are put in a <p>. Other
+images are left alone.
+*/
+func ImageReplacers(root *html.Node) {
+	var images []*html.Node
+	walk(root, func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "img" {
+			images = append(images, n)
+		}
+	})
+	for _, img := range images {
+		file := replacerFileOf(nodeAttr(img, "src"))
+		if file == "" {
+			continue
+		}
+		options := map[string]interface{}{"style": "alignCenter"}
+		for _, key := range []string{"width", "height"} {
+			if n, err := strconv.Atoi(nodeAttr(img, key)); err == nil {
+				options["origin"+strings.ToUpper(key[:1])+key[1:]] = n
+			}
+		}
+		if alt := nodeAttr(img, "alt"); alt != "" {
+			options["alt"] = alt
+		}
+		target := img
+		if figure := img.Parent; figure != nil && figure.Data == "figure" {
+			if caption := onlyCaption(figure, img); caption != nil {
+				options["caption"] = strings.TrimSpace(textContent(caption))
+				target = figure
+			}
+		}
+		data, _ := json.Marshal(options)
+		replacer := &html.Node{Type: html.TextNode, Data: "[##_Image|" + file + "|CDM|1.3|" + string(data) + "_##]"}
+		if target.Parent == root || target.Data == "figure" {
+			// The editor keeps block level replacers in a paragraph.
+			p := &html.Node{Type: html.ElementNode, Data: "p", DataAtom: atom.P}
+			p.AppendChild(replacer)
+			replacer = p
+		}
+		target.Parent.InsertBefore(replacer, target)
+		target.Parent.RemoveChild(target)
+	}
+}
+
+// replacerFileOf returns "kage@KEY" or "cfile@KEY" for an image uploaded
+// to Tistory, or "".
+func replacerFileOf(src string) string {
+	key := ImageKey(src)
+	if key == "" || key == strings.TrimSpace(src) {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(src))
+	if err == nil && (strings.Contains(u.Path, "/cfile/") || strings.HasPrefix(u.Host, "cfile") ||
+		strings.HasPrefix(u.Query().Get("fname"), "https://t1.daumcdn.net/cfile/")) {
+		return "cfile@" + key
+	}
+	return "kage@" + key
+}
+
+// onlyCaption returns the <figcaption> of figure if figure holds only img and it.
+func onlyCaption(figure, img *html.Node) *html.Node {
+	var caption *html.Node
+	for c := figure.FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case c == img:
+		case c.Type == html.ElementNode && c.Data == "figcaption" && caption == nil:
+			caption = c
+		case c.Type == html.TextNode && strings.TrimSpace(c.Data) == "":
+		default:
+			return nil
+		}
+	}
+	return caption
+}
+
+// walk calls fn for n and its descendants in document order.
+func walk(n *html.Node, fn func(n *html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		walk(c, fn)
+		c = next
+	}
+}
+
+func inside(n *html.Node, name string) bool {
+	for ; n != nil; n = n.Parent {
+		if n.Type == html.ElementNode && n.Data == name {
+			return true
+		}
+	}
+	return false
+}
+
+// textContent returns the text of n and its descendants, with <br> as newlines.
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(c *html.Node) {
+		switch {
+		case c.Type == html.TextNode:
+			b.WriteString(c.Data)
+		case c.Type == html.ElementNode && c.Data == "br":
+			b.WriteByte('\n')
+		}
+	})
+	return b.String()
+}
+
+func nodeAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, value string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
@@ -0,0 +1,63 @@
+package content
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "script and handlers", in: `<p onclick="x()">hi<script>alert(1)</script></p>`, want: `<p>hi</p>`},
+		{name: "unknown element unwrapped", in: `<p><font color="red">red</font> <big>b</big></p>`, want: `<p>red b</p>`},
+		{name: "unsafe url", in: `<a href="javascript:alert(1)" title="t">x</a><a href="/1">y</a>`, want: `<a title="t">x</a><a href="/1">y</a>`},
+		{name: "style properties", in: `<p style="text-align: center; position: fixed; background: url(x)">c</p>`, want: `<p style="text-align: center;">c</p>`},
+		{name: "iframe hosts", in: `<iframe src="https://evil.test/x"></iframe><iframe src="https://www.youtube.com/embed/id"></iframe>`, want: `<iframe src="https://www.youtube.com/embed/id"></iframe>`},
+		{name: "editor attributes and replacers", in: `<p data-ke-size="size16">[##_Image|kage@k/img.png|CDM|1.3|{"style":"alignCenter"}_##] &amp;</p><!-- note -->`, want: `<p data-ke-size="size16">[##_Image|kage@k/img.png|CDM|1.3|{"style":"alignCenter"}_##] &amp;</p>`},
+		{name: "script in replacer", in: `<p>[##_Image|&lt;script&gt;alert(1)&lt;/script&gt;|_##]</p>`, want: `<p>[##_Image|&lt;script&gt;alert(1)&lt;/script&gt;|_##]</p>`},
+		{name: "handler in replacer", in: `<p>[##_Image|&lt;img src=x onerror=alert(2)&gt;|_##]</p>`, want: `<p>[##_Image|&lt;img src=x onerror=alert(2)&gt;|_##]</p>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sanitize(tt.in, DefaultPolicy()); got != tt.want {
+				t.Errorf("Sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "headings",
+			in:   `<h1>a</h1><h3>b</h3><h6>c</h6>`,
+			want: `<h2 data-ke-size="size26">a</h2><h4 data-ke-size="size20">b</h4><h4 data-ke-size="size20">c</h4>`,
+		},
+		{
+			name: "code block",
+			in:   "<pre><code class=\"language-Go\"><span class=\"k\">func</span> main() {}\n</code></pre><pre>plain</pre>",
+			want: `<pre class="go" data-ke-language="go" data-ke-type="codeblock"><code>func main() {}</code></pre>` +
+				`<pre data-ke-type="codeblock"><code>plain</code></pre>`,
+		},
+		{
+			name: "image replacers",
+			in: `<p><img src="https://blog.kakaocdn.net/dn/bX/img.png" width="640" alt="cat"></p>` +
+				`<figure><img src="https://t1.daumcdn.net/cfile/tistory/99AB"><figcaption> 캡션 </figcaption></figure>` +
+				`<p><img src="https://example.com/a.png"></p>`,
+			want: `<p>[##_Image|kage@bX/img.png|CDM|1.3|{"alt":"cat","originWidth":640,"style":"alignCenter"}_##]</p>` +
+				`<p>[##_Image|cfile@99AB|CDM|1.3|{"caption":"캡션","style":"alignCenter"}_##]</p>` +
+				`<p><img src="https://example.com/a.png"/></p>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Prepare(tt.in); got != tt.want {
+				t.Errorf("Prepare() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package content
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+/*
+Policy is an allowlist of elements, attributes, URL schemes and CSS
+properties. Elements that are not allowed are unwrapped (their content is
+kept) unless they are in Drop, which removes them with their content.
+*/
+type Policy struct {
+	// Elements maps each allowed element to its allowed attributes.
+	Elements map[string][]string
+	// Global attributes are allowed on every element; a trailing "*"
+	// allows a prefix ("data-ke-*").
+	Global []string
+	// Drop lists the elements removed with their content.
+	Drop map[string]bool
+	// URLSchemes are the schemes allowed in href and src; relative URLs are allowed.
+	URLSchemes []string
+	// IframeHosts are the hosts (and their subdomains) an iframe may embed.
+	IframeHosts []string
+	// Styles are the CSS properties kept in style attributes.
+	Styles []string
+}
+
+// DefaultPolicy allows what the Tistory editor produces and embeds from
+// common video sites.
+func DefaultPolicy() *Policy {
+	cells := []string{"colspan", "rowspan", "width", "height"}
+	return &Policy{
+		Elements: map[string][]string{
+			"a": {"href", "target", "rel"}, "abbr": nil, "b": nil, "blockquote": {"cite"}, "br": nil,
+			"caption": nil, "code": nil, "col": {"span", "width"}, "colgroup": {"span"}, "dd": nil, "del": nil,
+			"div": nil, "dl": nil, "dt": nil, "em": nil, "figcaption": nil, "figure": nil,
+			"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil, "hr": nil, "i": nil,
+			"iframe": {"src", "width", "height", "frameborder", "allow", "allowfullscreen"},
+			"img":    {"src", "alt", "width", "height"}, "ins": nil, "kbd": nil, "li": nil, "mark": nil,
+			"ol": {"start", "type"}, "p": nil, "pre": nil, "s": nil, "small": nil, "span": nil, "strong": nil,
+			"sub": nil, "sup": nil, "table": {"border"}, "tbody": nil, "td": cells, "tfoot": nil, "th": cells,
+			"thead": nil, "tr": nil, "u": nil, "ul": nil,
+		},
+		Global:      []string{"class", "id", "title", "lang", "dir", "style", "data-ke-*"},
+		Drop:        map[string]bool{"script": true, "style": true, "noscript": true, "object": true, "embed": true, "form": true, "input": true, "button": true, "select": true, "textarea": true, "link": true, "meta": true, "base": true, "template": true},
+		URLSchemes:  []string{"http", "https", "mailto", "tel"},
+		IframeHosts: []string{"youtube.com", "youtube-nocookie.com", "player.vimeo.com", "tv.kakao.com", "play-tv.kakao.com", "codepen.io", "gist.github.com"},
+		Styles:      []string{"text-align", "color", "background-color", "font-weight", "font-style", "text-decoration", "width", "height"},
+	}
+}
+
+// Sanitize removes from s everything p does not allow.
+func Sanitize(s string, p *Policy) string {
+	return Apply(s, p.Sanitizer())
+}
+
+// Sanitizer returns the sanitizing step of a pipeline.
+func (p *Policy) Sanitizer() Transform {
+	return p.clean
+}
+
+func (p *Policy) clean(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch c.Type {
+		case html.CommentNode, html.DoctypeNode:
+			n.RemoveChild(c)
+		case html.ElementNode:
+			switch {
+			case p.Drop[c.Data], c.Data == "iframe" && !p.AllowedIframe(nodeAttr(c, "src")):
+				n.RemoveChild(c)
+			case !p.AllowedElement(c.Data):
+				// Unwrap: clean the children, then move them in place of c.
+				p.clean(c)
+				for gc := c.FirstChild; gc != nil; gc = c.FirstChild {
+					c.RemoveChild(gc)
+					n.InsertBefore(gc, c)
+				}
+				n.RemoveChild(c)
+			default:
+				c.Attr = p.cleanAttrs(c.Data, c.Attr)
+				p.clean(c)
+			}
+		}
+		c = next
+	}
+}
+
+// AllowedElement reports whether the element name is kept.
+func (p *Policy) AllowedElement(name string) bool {
+	_, ok := p.Elements[name]
+	return ok
+}
+
+// AllowedAttr reports whether the attribute key is kept on element name.
+func (p *Policy) AllowedAttr(name, key string) bool {
+	if strings.HasPrefix(key, "on") {
+		return false
+	}
+	for _, allowed := range p.Elements[name] {
+		if allowed == key {
+			return true
+		}
+	}
+	for _, allowed := range p.Global {
+		if allowed == key || strings.HasSuffix(allowed, "*") && strings.HasPrefix(key, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// SafeURL reports whether u is relative or uses an allowed scheme.
+func (p *Policy) SafeURL(u string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil {
+		return false
+	}
+	if parsed.Scheme == "" {
+		return true
+	}
+	for _, scheme := range p.URLSchemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedIframe reports whether an iframe may embed src.
+func (p *Policy) AllowedIframe(src string) bool {
+	u, err := url.Parse(strings.TrimSpace(src))
+	if err != nil || u.Scheme != "https" && u.Scheme != "" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range p.IframeHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanStyle keeps the allowed declarations of a style attribute and
+// reports the properties it dropped.
+func (p *Policy) CleanStyle(style string) (string, []string) {
+	var kept, dropped []string
+	for _, decl := range strings.Split(style, ";") {
+		property, value, ok := strings.Cut(decl, ":")
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.TrimSpace(value)
+		if !ok || property == "" {
+			continue
+		}
+		lower := strings.ToLower(value)
+		if !p.allowedStyle(property) || strings.Contains(lower, "url(") || strings.Contains(lower, "expression(") {
+			dropped = append(dropped, property)
+			continue
+		}
+		kept = append(kept, property+": "+value)
+	}
+	if len(kept) == 0 {
+		return "", dropped
+	}
+	return strings.Join(kept, "; ") + ";", dropped
+}
+
+func (p *Policy) allowedStyle(property string) bool {
+	for _, allowed := range p.Styles {
+		if allowed == property {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) cleanAttrs(name string, attrs []html.Attribute) []html.Attribute {
+	kept := attrs[:0]
+	for _, a := range attrs {
+		if !p.AllowedAttr(name, a.Key) {
+			continue
+		}
+		switch a.Key {
+		case "href", "src", "cite":
+			if !p.SafeURL(a.Val) {
+				continue
+			}
+		case "style":
+			if a.Val, _ = p.CleanStyle(a.Val); a.Val == "" {
+				continue
+			}
+		}
+		kept = append(kept, a)
+	}
+	return kept
+}
@@ -0,0 +1,45 @@
+package content
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+/*
+Text returns the readable text of an HTML body: tags, comments, replacers
+and the contents of <script> and <style> are dropped, block elements are
+separated by a space and whitespace is collapsed. Image alt texts are kept.
+*/
+func Text(s string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	skip := 0
+	for {
+		switch tt := z.Next(); tt {
+		case html.ErrorToken:
+			return strings.Join(strings.Fields(b.String()), " ")
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(Replacer.ReplaceAllString(string(z.Text()), " "))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			token := z.Token()
+			switch token.Data {
+			case "script", "style":
+				if tt == html.StartTagToken {
+					skip++
+				} else if tt == html.EndTagToken && skip > 0 {
+					skip--
+				}
+			case "img":
+				if alt := attr(token, "alt"); alt != "" {
+					b.WriteString(" " + alt + " ")
+				}
+			}
+			if blockTags[token.Data] {
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package content
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "blocks", in: "<h2>제목</h2><p>첫 <b>문단</b></p><p>둘째</p>", want: "제목 첫 문단 둘째"},
+		{name: "script and style", in: "<style>p{}</style><p>text</p><script>alert(1)</script>", want: "text"},
+		{name: "replacer and alt", in: `<p>[##_Image|kage@abc/img.png|CDM|1.3|{}_##]<img src="x.png" alt="고양이"></p>`, want: "고양이"},
+		{name: "entities", in: "<p>a &amp; b&nbsp;c</p>", want: "a & b c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.in); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
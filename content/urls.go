@@ -0,0 +1,93 @@
+package content
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+/*
+RewriteURLs passes the href of every <a> and the src of every <img> of s
+through fn and reports whether any changed. fn returns the URL unchanged to
+keep it, another URL to replace it, or "" to remove the link (keeping its
+text) or the image. Only the rewritten tags are rendered again; the rest of
+s, including replacers, is kept byte for byte.
+*/
+func RewriteURLs(s string, fn func(tag, url string) string) (string, bool) {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	changed := false
+	// depth counts the open <a> tags; removed holds the depths of the
+	// removed ones, whose end tags are dropped too.
+	depth := 0
+	var removed []int
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				b.Write(z.Raw())
+			}
+			return b.String(), changed
+		}
+		raw := string(z.Raw())
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken && tt != html.EndTagToken {
+			b.WriteString(raw)
+			continue
+		}
+		token := z.Token()
+		if token.Data != "a" && token.Data != "img" {
+			b.WriteString(raw)
+			continue
+		}
+		if tt == html.EndTagToken {
+			if token.Data == "a" {
+				if n := len(removed); n > 0 && removed[n-1] == depth {
+					removed = removed[:n-1]
+				} else {
+					b.WriteString(raw)
+				}
+				depth = max(depth-1, 0)
+			} else {
+				b.WriteString(raw)
+			}
+			continue
+		}
+
+		key := "src"
+		if token.Data == "a" {
+			key = "href"
+			if tt == html.StartTagToken {
+				depth++
+			}
+		}
+		i := attrIndex(token, key)
+		if i < 0 {
+			b.WriteString(raw)
+			continue
+		}
+		url := fn(token.Data, token.Attr[i].Val)
+		switch {
+		case url == token.Attr[i].Val:
+			b.WriteString(raw)
+		case url == "":
+			changed = true
+			if token.Data == "a" && tt == html.StartTagToken {
+				removed = append(removed, depth)
+			}
+		default:
+			changed = true
+			token.Attr[i].Val = url
+			b.WriteString(token.String())
+		}
+	}
+}
+
+func attrIndex(token html.Token, key string) int {
+	for i, a := range token.Attr {
+		if a.Key == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,32 @@
+package content
+
+import "testing"
+
+func TestRewriteURLs(t *testing.T) {
+	in := `<p data-ke-size="size16">See <a href="https://old.test/1" target="_blank">one <b>bold</b></a>, ` +
+		`<a href="https://gone.test/x">gone</a> and <a href="https://keep.test/">kept</a>.<br>` +
+		`<img src="https://gone.test/a.png" alt="a"><img src="https://old.test/b.png"/></p>` +
+		`<p>[##_Image|kage@k/img.png|CDM|1.3|{"style":"alignCenter"}_##]</p>`
+	got, changed := RewriteURLs(in, func(tag, url string) string {
+		switch url {
+		case "https://old.test/1":
+			return "https://new.test/one?a=1&b=2"
+		case "https://old.test/b.png":
+			return "https://new.test/b.png"
+		case "https://gone.test/x", "https://gone.test/a.png":
+			return ""
+		}
+		return url
+	})
+	want := `<p data-ke-size="size16">See <a href="https://new.test/one?a=1&amp;b=2" target="_blank">one <b>bold</b></a>, ` +
+		`gone and <a href="https://keep.test/">kept</a>.<br>` +
+		`<img src="https://new.test/b.png"/></p>` +
+		`<p>[##_Image|kage@k/img.png|CDM|1.3|{"style":"alignCenter"}_##]</p>`
+	if got != want || !changed {
+		t.Errorf("RewriteURLs() = %q, %v\nwant %q, true", got, changed, want)
+	}
+
+	if got, changed := RewriteURLs(in, func(tag, url string) string { return url }); got != in || changed {
+		t.Errorf("RewriteURLs(identity) = %q, %v, want the input unchanged", got, changed)
+	}
+}
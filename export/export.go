@@ -0,0 +1,238 @@
+// Package export writes Tistory posts as Markdown files with a YAML front
+// matter (the watch draft format) and downloads their images next to them:
+//
+//	out/123/index.md
+//	out/123/img.png
+//
+//	e := export.New(blog, "out")
+//	e.Log = os.Stderr
+//	results, err := e.Export(ctx, nil) // all posts
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/LimJiAn/tistory-go/watch"
+	"github.com/pkg/errors"
+)
+
+// Result is the outcome of exporting one post.
+type Result struct {
+	PostId int
+	File   string // path of the Markdown file
+	Images int    // downloaded images
+	Err    error
+}
+
+// Exporter writes posts under Dir, one directory per post id.
+type Exporter struct {
+	Blog *tistory.Tistory
+	Dir  string
+	// KeepImageURLs links the images on Tistory instead of downloading them.
+	KeepImageURLs bool
+	// HTTPClient downloads images (default: Blog.HTTPClient or http.DefaultClient).
+	HTTPClient *http.Client
+	// Log receives one line per image that could not be downloaded (nil: no log).
+	Log io.Writer
+
+	// categories maps category ids to their labels ("부모/자식").
+	categories map[int]string
+}
+
+func New(blog *tistory.Tistory, dir string) *Exporter {
+	return &Exporter{Blog: blog, Dir: dir}
+}
+
+/*
+Export writes the posts postIds, or every post when postIds is empty. A
+post that fails is reported in its Result and the others are still
+exported; the error is only for listing the blog or a canceled ctx.
+*/
+func (e *Exporter) Export(ctx context.Context, postIds []int) ([]Result, error) {
+	categories, err := e.Blog.Categories()
+	if err != nil {
+		return nil, err
+	}
+	e.categories = make(map[int]string, len(categories))
+	for _, c := range categories {
+		e.categories[c.Id] = c.Label
+	}
+
+	if len(postIds) == 0 {
+		posts, err := e.Blog.GetAllPosts()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range posts {
+			postIds = append(postIds, p.Id)
+		}
+	}
+	var results []Result
+	for _, id := range postIds {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		results = append(results, e.ExportPost(ctx, id))
+	}
+	return results, nil
+}
+
+// ExportPost writes the post postId to Dir/postId/index.md.
+func (e *Exporter) ExportPost(ctx context.Context, postId int) Result {
+	result := Result{PostId: postId}
+	post, err := e.Blog.ReadPost(postId)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	dir := filepath.Join(e.Dir, strconv.Itoa(postId))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		result.Err = errors.Wrap(err, "Failed to create the export directory")
+		return result
+	}
+
+	images := map[string]string{}
+	names := map[string]bool{"index.md": true}
+	body := content.Markdown(post.Content, &content.MarkdownOptions{Image: func(src string) string {
+		if e.KeepImageURLs {
+			return src
+		}
+		if name, ok := images[src]; ok {
+			return name
+		}
+		name, err := e.download(ctx, src, dir, names)
+		if err != nil {
+			e.logf("post %d: %v", postId, err)
+			name = src
+		} else if name != src {
+			result.Images++
+		}
+		images[src] = name
+		return name
+	}})
+
+	draft := &watch.Draft{FrontMatter: frontMatter(post), Body: body}
+	if label := e.categories[post.CategoryId]; label != "" {
+		draft.Extra = map[string]interface{}{"category_name": label}
+	}
+	data, err := draft.Bytes()
+	if err != nil {
+		result.Err = errors.Wrap(err, "Failed to encode the front matter")
+		return result
+	}
+	result.File = filepath.Join(dir, "index.md")
+	if err := os.WriteFile(result.File, data, 0o644); err != nil {
+		result.Err = errors.Wrap(err, "Failed to write the export file")
+	}
+	return result
+}
+
+// frontMatter returns the front matter of an exported post. It has no
+// status, so that moving the file into a watched directory does not publish
+// it again.
+func frontMatter(post tistory.Post) watch.FrontMatter {
+	visibility := tistory.WriteVisibility(post.Visibility)
+	acceptComment := post.AcceptComment
+	return watch.FrontMatter{
+		Title:         post.Title,
+		Category:      post.CategoryId,
+		Tags:          post.Tags,
+		Visibility:    &visibility,
+		Published:     post.Date,
+		AcceptComment: &acceptComment,
+		PostId:        post.Id,
+		URL:           post.PostURL,
+	}
+}
+
+/*
+download saves the image src in dir and returns its file name. Names come
+from the image key (img.png for https://blog.kakaocdn.net/dn/.../img.png)
+and get a -2, -3, ... suffix when taken. Images that are not on the web
+(data: URIs, relative paths) are returned unchanged.
+*/
+func (e *Exporter) download(ctx context.Context, src, dir string, names map[string]bool) (string, error) {
+	u, err := url.Parse(src)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return src, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to download %s", src)
+	}
+	resp, err := e.httpClient().Do(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "Failed to download %s", src)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("Failed to download %s (%s)", src, resp.Status)
+	}
+
+	name := fileName(src, resp.Header.Get("Content-Type"))
+	ext := path.Ext(name)
+	for i, base := 2, strings.TrimSuffix(name, ext); names[name]; i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to create the image file")
+	}
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", errors.Wrapf(err, "Failed to download %s", src)
+	}
+	names[name] = true
+	return name, nil
+}
+
+// fileName returns a file name for an image URL, with an extension from an
+// image/* contentType when the URL has none.
+func fileName(src, contentType string) string {
+	key := content.ImageKey(src)
+	if u, err := url.Parse(key); err == nil {
+		key = u.Path
+	}
+	name := path.Base(key)
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		name = "image"
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if subtype := strings.TrimPrefix(mediaType, "image/"); path.Ext(name) == "" && subtype != mediaType {
+		// image/svg+xml → .svg
+		name += "." + strings.SplitN(subtype, "+", 2)[0]
+	}
+	return name
+}
+
+func (e *Exporter) httpClient() *http.Client {
+	switch {
+	case e.HTTPClient != nil:
+		return e.HTTPClient
+	case e.Blog.HTTPClient != nil:
+		return e.Blog.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (e *Exporter) logf(format string, args ...interface{}) {
+	if e.Log != nil {
+		fmt.Fprintf(e.Log, format+"\n", args...)
+	}
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+	"github.com/LimJiAn/tistory-go/watch"
+)
+
+func TestExport(t *testing.T) {
+	images := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dn/a/img.png", "/dn/b/img.png":
+			w.Write([]byte("png " + r.URL.Path))
+		case "/thumb":
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write([]byte("jpeg"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer images.Close()
+
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	dev := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "dev"})
+	published := time.Date(2026, 10, 1, 9, 0, 0, 0, time.FixedZone("KST", 9*60*60))
+	id := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{
+		Title: "내보내기", Visibility: 3, CategoryID: dev, Tags: []string{"go", "markdown"}, AcceptComment: true, Published: published,
+		Content: `<h2>제목</h2><p><img src="` + images.URL + `/dn/a/img.png" alt="a"><img src="` + images.URL + `/dn/b/img.png">` +
+			`<img src="` + images.URL + `/dn/a/img.png"></p><p><img src="` + images.URL + `/thumb"><img src="` + images.URL + `/missing.png"></p>`,
+	})
+	other := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "other", Content: "<p>x</p>"})
+
+	dir := t.TempDir()
+	var log strings.Builder
+	e := New(testblog.New(srv), dir)
+	e.HTTPClient = images.Client()
+	e.Log = &log
+	results, err := e.Export(context.Background(), []int{id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Err != nil || results[0].Images != 3 {
+		t.Fatalf("Exporter.Export() = %+v, want one post with 3 images", results)
+	}
+
+	data, err := os.ReadFile(results[0].File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, err := watch.ParseDraft(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if draft.Title != "내보내기" || draft.PostId != id || draft.Category != dev || *draft.Visibility != 3 ||
+		strings.Join(draft.Tags, ",") != "go,markdown" || !draft.Published.Equal(published) ||
+		draft.Extra["category_name"] != "dev" || draft.Status != "" {
+		t.Errorf("front matter = %+v", draft.FrontMatter)
+	}
+	wantBody := "## 제목\n\n![a](img.png)![](img-2.png)![](img.png)\n\n![](thumb.jpeg)![](" + images.URL + "/missing.png)\n"
+	if draft.Body != wantBody {
+		t.Errorf("body =\n%s\nwant\n%s", draft.Body, wantBody)
+	}
+	if got, _ := os.ReadFile(filepath.Join(filepath.Dir(results[0].File), "img-2.png")); string(got) != "png /dn/b/img.png" {
+		t.Errorf("img-2.png = %q", got)
+	}
+	if !strings.Contains(log.String(), "missing.png (404 Not Found)") {
+		t.Errorf("log = %q, want the failed download", log.String())
+	}
+
+	results, err = e.Export(context.Background(), nil)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Exporter.Export(all) = %+v, %v", results, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, strconv.Itoa(other), "index.md")); err != nil {
+		t.Errorf("post %d was not exported: %v", other, err)
+	}
+}
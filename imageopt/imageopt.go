@@ -0,0 +1,116 @@
+// Package imageopt shrinks images before they are attached to a post:
+// it scales them down to a maximum width, re-encodes opaque PNGs as JPEG
+// and strips EXIF (including GPS), XMP and text metadata. Only the standard
+// image packages are used.
+//
+//	blog.AttachProcessor = &imageopt.Optimizer{MaxWidth: 1600, Quality: 85, ConvertPNG: true}
+//	result, err := blog.AttachPost("screenshot.png") // uploads screenshot.jpg
+package imageopt
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultQuality is the JPEG quality used when Optimizer.Quality is 0.
+const DefaultQuality = 85
+
+/*
+Optimizer is a tistory.AttachProcessor for JPEG and PNG files; other files
+are uploaded unchanged. The metadata of every JPEG and PNG is removed, and
+one that fails to parse is an error rather than uploaded as is. Files that
+need no resizing, rotation or conversion are not re-encoded, so they lose
+no quality.
+*/
+type Optimizer struct {
+	// MaxWidth is the width wider images are scaled down to; 0 keeps the size.
+	MaxWidth int
+	// Quality is the JPEG quality, 1 to 100 (DefaultQuality when 0).
+	Quality int
+	// ConvertPNG re-encodes PNGs without transparency as JPEG (renamed
+	// .jpg) when that is smaller.
+	ConvertPNG bool
+}
+
+/*
+ProcessAttachment optimizes one file and returns its new name and content.
+JPEGs rotated by their EXIF orientation are turned upright, since the
+orientation is removed with the rest of the metadata.
+*/
+func (o *Optimizer) ProcessAttachment(name string, data []byte) (string, []byte, error) {
+	var format string
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8}):
+		format = "jpeg"
+	case bytes.HasPrefix(data, pngSignature):
+		format = "png"
+	default:
+		return name, data, nil
+	}
+
+	orientation := 1
+	var stripped []byte
+	var err error
+	if format == "jpeg" {
+		stripped, orientation, err = stripJPEG(data)
+	} else {
+		stripped, err = stripPNG(data)
+	}
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Failed to optimize %s", name)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Failed to decode %s", name)
+	}
+
+	width := config.Width
+	if orientation >= 5 {
+		width = config.Height
+	}
+	resized := o.MaxWidth > 0 && width > o.MaxWidth
+	convert := format == "png" && o.ConvertPNG
+	if !resized && orientation == 1 && !convert {
+		return name, stripped, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(stripped))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Failed to decode %s", name)
+	}
+	rgba := resize(orient(toRGBA(img), orientation), o.MaxWidth)
+
+	var buf bytes.Buffer
+	newName := name
+	if format == "png" && !(convert && rgba.Opaque()) {
+		if !resized {
+			// Transparent PNGs are never converted; nothing else changed.
+			return name, stripped, nil
+		}
+		err = png.Encode(&buf, rgba)
+	} else {
+		quality := o.Quality
+		if quality <= 0 {
+			quality = DefaultQuality
+		}
+		err = jpeg.Encode(&buf, rgba, &jpeg.Options{Quality: min(quality, 100)})
+		if format == "png" {
+			newName = strings.TrimSuffix(name, filepath.Ext(name)) + ".jpg"
+		}
+	}
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Failed to encode %s", name)
+	}
+
+	// A conversion alone is only worth it when it saves bytes.
+	if !resized && orientation == 1 && buf.Len() >= len(stripped) {
+		return name, stripped, nil
+	}
+	return newName, buf.Bytes(), nil
+}
@@ -0,0 +1,167 @@
+package imageopt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// halves returns a w×h image, red on the left half and blue on the right.
+func halves(w, h int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{255, 0, 0, alpha}
+			if x >= w/2 {
+				c = color.NRGBA{0, 0, 255, alpha}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+// pngWithText encodes img as PNG with a tEXt chunk before IEND.
+func pngWithText(t *testing.T, img image.Image, text string) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	iend := len(data) - 12
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(text)))
+	chunk = append(chunk, "tEXt"+text...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+	return append(append(append([]byte{}, data[:iend]...), chunk...), data[iend:]...)
+}
+
+// jpegWithExif encodes img as JPEG with an APP1 EXIF segment holding the
+// orientation and a fake GPS value.
+func jpegWithExif(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = append(tiff, 0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS 37.5665N 126.9780E"...)
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xFF, 0xE1}, binary.BigEndian.AppendUint16(nil, uint16(len(payload)+2))...)
+	app1 = append(app1, payload...)
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func near(c color.Color, want color.RGBA) bool {
+	r, g, b, _ := c.RGBA()
+	d := func(a uint32, b uint8) bool { return int(a>>8)-int(b) < 40 && int(b)-int(a>>8) < 40 }
+	return d(r, want.R) && d(g, want.G) && d(b, want.B)
+}
+
+func TestOptimizer_PNG(t *testing.T) {
+	o := &Optimizer{MaxWidth: 20, ConvertPNG: true}
+	name, data, err := o.ProcessAttachment("dir/shot.png", pngWithText(t, halves(40, 20, 255), "Author\x00me"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil || name != "dir/shot.jpg" || format != "jpeg" || img.Bounds() != image.Rect(0, 0, 20, 10) {
+		t.Fatalf("Optimizer.ProcessAttachment() = %q, %s %v, %v", name, format, img.Bounds(), err)
+	}
+	if !near(img.At(2, 5), color.RGBA{255, 0, 0, 255}) || !near(img.At(17, 5), color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("resized colors = %v %v", img.At(2, 5), img.At(17, 5))
+	}
+
+	// Transparent PNGs stay PNG and only lose their metadata.
+	transparent := pngWithText(t, halves(40, 20, 128), "Author\x00me")
+	name, data, err = o.ProcessAttachment("alpha.png", transparent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, format, _ := image.DecodeConfig(bytes.NewReader(data))
+	if name != "alpha.png" || format != "png" || config.Width != 20 || bytes.Contains(data, []byte("tEXt")) {
+		t.Errorf("transparent PNG = %q, %s %dx%d", name, format, config.Width, config.Height)
+	}
+
+	o = &Optimizer{}
+	if name, data, _ = o.ProcessAttachment("alpha.png", transparent); name != "alpha.png" ||
+		len(data) != len(transparent)-12-len("Author\x00me") || bytes.Contains(data, []byte("Author")) {
+		t.Errorf("stripped PNG = %q, %d bytes of %d", name, len(data), len(transparent))
+	}
+}
+
+func TestOptimizer_JPEG(t *testing.T) {
+	o := &Optimizer{Quality: 90}
+
+	// Orientation 6 is stored rotated 90° counterclockwise.
+	name, data, err := o.ProcessAttachment("photo.jpg", jpegWithExif(t, halves(32, 16, 255), 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil || name != "photo.jpg" || img.Bounds() != image.Rect(0, 0, 16, 32) {
+		t.Fatalf("Optimizer.ProcessAttachment() = %q, %v, %v", name, img.Bounds(), err)
+	}
+	if !near(img.At(8, 4), color.RGBA{255, 0, 0, 255}) || !near(img.At(8, 28), color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("rotated colors = %v %v", img.At(8, 4), img.At(8, 28))
+	}
+	if bytes.Contains(data, []byte("GPS")) || bytes.Contains(data, []byte("Exif")) {
+		t.Errorf("EXIF kept in rotated JPEG")
+	}
+
+	// Upright JPEGs within MaxWidth are not re-encoded.
+	original := jpegWithExif(t, halves(32, 16, 255), 1)
+	o.MaxWidth = 32
+	_, data, err = o.ProcessAttachment("photo.jpg", original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app1 := 2 + int(binary.BigEndian.Uint16(original[4:]))
+	if want := append(append([]byte{}, original[:2]...), original[2+app1:]...); !bytes.Equal(data, want) {
+		t.Errorf("stripped JPEG = %d bytes, want %d", len(data), len(want))
+	}
+
+	o.MaxWidth = 8
+	if _, data, _ = o.ProcessAttachment("photo.jpg", original); !bytes.Equal(data[:2], []byte{0xFF, 0xD8}) || bytes.Contains(data, []byte("GPS")) {
+		t.Errorf("resized JPEG kept EXIF")
+	} else if config, _ := jpeg.DecodeConfig(bytes.NewReader(data)); config.Width != 8 || config.Height != 4 {
+		t.Errorf("resized JPEG = %dx%d, want 8x4", config.Width, config.Height)
+	}
+}
+
+func TestOptimizer_Passthrough(t *testing.T) {
+	o := &Optimizer{MaxWidth: 10, ConvertPNG: true}
+	for _, data := range [][]byte{[]byte("%PDF-1.4"), []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;")} {
+		if name, got, err := o.ProcessAttachment("file", data); err != nil || name != "file" || !bytes.Equal(got, data) {
+			t.Errorf("Optimizer.ProcessAttachment(%q) = %q, %q, %v", data, name, got, err)
+		}
+	}
+}
+
+func TestOptimizer_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated JPEG", data: jpegWithExif(t, halves(32, 16, 255), 1)[:40]},
+		{name: "APP1 length 0", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x00, 0xFF, 0xD9}},
+		{name: "APP1 length 1", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xFF, 0xD9}},
+		{name: "truncated PNG", data: pngWithText(t, halves(4, 4, 255), "a")[:20]},
+	}
+	o := &Optimizer{MaxWidth: 10}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := o.ProcessAttachment("broken", tt.data); err == nil {
+				t.Errorf("Optimizer.ProcessAttachment() error = nil, want error")
+			}
+		})
+	}
+}
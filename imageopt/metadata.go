@@ -0,0 +1,145 @@
+package imageopt
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// JPEG markers without a length.
+func standalone(marker byte) bool {
+	return marker == 0xD8 || marker == 0x01 || marker >= 0xD0 && marker <= 0xD7
+}
+
+// metadataSegment reports whether a JPEG segment holds metadata: EXIF and
+// XMP (APP1), IPTC (APP13) and comments. JFIF (APP0), the ICC color profile
+// (APP2) and Adobe (APP14), which decoding needs, are kept.
+func metadataSegment(marker byte) bool {
+	return marker == 0xE1 || marker == 0xED || marker == 0xFE
+}
+
+/*
+stripJPEG removes the metadata segments of a JPEG without decoding it, and
+returns the EXIF orientation (1 to 8, 1 when absent).
+*/
+func stripJPEG(data []byte) ([]byte, int, error) {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, 0, errors.New("Failed to parse JPEG (no SOI marker)")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	orientation := 1
+	for i := 2; i < len(data); {
+		if data[i] != 0xFF {
+			return nil, 0, errors.Errorf("Failed to parse JPEG (no marker at %d)", i)
+		}
+		// Markers may be padded with any number of 0xFF.
+		j := i + 1
+		for j < len(data) && data[j] == 0xFF {
+			j++
+		}
+		if j >= len(data) {
+			return nil, 0, errors.New("Failed to parse JPEG (truncated)")
+		}
+		marker := data[j]
+		if standalone(marker) {
+			out.Write(data[i : j+1])
+			i = j + 1
+			continue
+		}
+		if j+3 > len(data) {
+			return nil, 0, errors.New("Failed to parse JPEG (truncated)")
+		}
+		// The length counts its own two bytes.
+		length := int(binary.BigEndian.Uint16(data[j+1:]))
+		end := j + 1 + length
+		if length < 2 || end > len(data) {
+			return nil, 0, errors.New("Failed to parse JPEG (truncated segment)")
+		}
+		if marker == 0xDA {
+			// Start of scan: the compressed data runs to the end.
+			out.Write(data[i:])
+			break
+		}
+		if marker == 0xE1 {
+			if o := exifOrientation(data[j+3 : end]); o != 0 {
+				orientation = o
+			}
+		}
+		if !metadataSegment(marker) {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), orientation, nil
+}
+
+// exifOrientation returns the Orientation tag (0x0112) of an APP1 EXIF
+// payload, or 0.
+func exifOrientation(app1 []byte) int {
+	if !bytes.HasPrefix(app1, []byte("Exif\x00\x00")) {
+		return 0
+	}
+	tiff := app1[6:]
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < entries; k++ {
+		entry := ifd + 2 + 12*k
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// pngSignature starts every PNG file.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// metadataChunks are the PNG chunks with text, EXIF or a timestamp.
+var metadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// stripPNG removes the metadata chunks of a PNG without decoding it.
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("Failed to parse PNG (no signature)")
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, errors.New("Failed to parse PNG (truncated)")
+		}
+		// length, type, data, CRC
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, errors.New("Failed to parse PNG (truncated chunk)")
+		}
+		if !metadataChunks[string(data[i+4:i+8])] {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,18 @@
+// Package testblog connects the packages built on tistory to a
+// tistorytest server in their tests.
+package testblog
+
+import (
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+// New returns a client of the default blog of srv.
+func New(srv *tistorytest.Server) *tistory.Tistory {
+	return &tistory.Tistory{
+		BlogName:    tistorytest.DefaultBlog,
+		AccessToken: srv.AccessToken,
+		BaseURL:     srv.URL,
+		HTTPClient:  srv.Client(),
+	}
+}
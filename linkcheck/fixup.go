@@ -0,0 +1,117 @@
+package linkcheck
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/pkg/errors"
+)
+
+/*
+Fixup is a line of a fixup file: what to do with a broken URL of a post.
+Fixups writes one per broken link with only the status filled in; set
+Replace to a new URL, or Unlink to remove the link (keeping its text) or
+the image. Fixups with neither are skipped.
+
+	{"postId":123,"url":"https://old.example.com/x","kind":"link","status":"404 Not Found","replace":"https://example.com/x"}
+*/
+type Fixup struct {
+	PostId  int    `json:"postId"`
+	URL     string `json:"url"`
+	Kind    string `json:"kind,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Replace string `json:"replace"`
+	Unlink  bool   `json:"unlink,omitempty"`
+}
+
+// Fixups returns a Fixup for every broken link of reports.
+func Fixups(reports []PostReport) []Fixup {
+	var fixups []Fixup
+	for _, r := range reports {
+		for _, b := range r.Broken {
+			fixups = append(fixups, Fixup{PostId: r.PostId, URL: b.URL, Kind: b.Kind, Status: b.Status()})
+		}
+	}
+	return fixups
+}
+
+// WriteFixups writes fixups as JSON lines.
+func WriteFixups(w io.Writer, fixups []Fixup) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, f := range fixups {
+		if err := enc.Encode(f); err != nil {
+			return errors.Wrap(err, "Failed to write fixups")
+		}
+	}
+	return nil
+}
+
+// ReadFixups reads a fixup file. Blank lines are skipped.
+func ReadFixups(r io.Reader) ([]Fixup, error) {
+	var fixups []Fixup
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var f Fixup
+		if err := json.Unmarshal([]byte(text), &f); err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse fixup (line %d)", line)
+		}
+		fixups = append(fixups, f)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Failed to read fixups")
+	}
+	return fixups, nil
+}
+
+/*
+Mutator returns a BulkModify mutator applying fixups to the links and
+images of each post, and a selector of the posts they name. A relative
+link is matched through its URL resolved against the post URL, as
+Extract reported it.
+*/
+func Mutator(fixups []Fixup) (tistory.PostSelector, tistory.PostMutator) {
+	byPost := map[int]map[string]Fixup{}
+	for _, f := range fixups {
+		if f.Replace == "" && !f.Unlink {
+			continue
+		}
+		if byPost[f.PostId] == nil {
+			byPost[f.PostId] = map[string]Fixup{}
+		}
+		byPost[f.PostId][f.URL] = f
+	}
+	selector := func(p tistory.PostSummary) bool {
+		return byPost[p.Id] != nil
+	}
+	mutator := func(p *tistory.Post) (bool, error) {
+		postFixups := byPost[p.Id]
+		base, _ := url.Parse(p.PostURL)
+		text, changed := content.RewriteURLs(p.Content, func(tag, u string) string {
+			f, ok := postFixups[u]
+			if !ok {
+				f, ok = postFixups[resolve(base, u)]
+			}
+			switch {
+			case !ok:
+				return u
+			case f.Unlink:
+				return ""
+			}
+			return f.Replace
+		})
+		p.Content = text
+		return changed, nil
+	}
+	return selector, mutator
+}
@@ -0,0 +1,220 @@
+// Package linkcheck finds the dead links and images of a blog's posts.
+//
+// A Checker requests each URL once (HEAD, then GET when HEAD fails), with
+// at most PerHost requests to the same host at a time. CheckBlog reads every
+// post through GetPostList and GetPost and reports its broken links, and
+// Fixups turns the reports into a fixup file that Mutator applies with
+// BulkModify.
+//
+//	c := &linkcheck.Checker{Workers: 8, PerHost: 2}
+//	reports, err := c.CheckBlog(ctx, blog, nil)
+//	for _, r := range reports {
+//		for _, b := range r.Broken {
+//			fmt.Println(r.PostId, b.URL, b.Status())
+//		}
+//	}
+package linkcheck
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Result is the outcome of checking one URL.
+type Result struct {
+	URL        string
+	StatusCode int    // 0 when the request failed
+	Final      string // URL after redirects, when different from URL
+	Err        error
+}
+
+// Broken reports whether the URL is dead: the request failed or the server
+// answered 4xx or 5xx. 429 Too Many Requests means the server is alive.
+func (r Result) Broken() bool {
+	return r.Err != nil || r.StatusCode >= 400 && r.StatusCode != http.StatusTooManyRequests
+}
+
+// Status describes the result, e.g. "404 Not Found" or the request error.
+func (r Result) Status() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	return strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode)
+}
+
+// Checker checks URLs. The zero value is usable; a Checker caches its
+// results, so reuse it to check each URL once.
+type Checker struct {
+	// Client sends the requests (default: http.DefaultClient).
+	Client *http.Client
+	// Workers is the number of URLs checked concurrently (default: 8).
+	Workers int
+	// PerHost is the number of concurrent requests to one host (default: 2).
+	PerHost int
+	// Timeout limits each request (default: 15s).
+	Timeout time.Duration
+	// UserAgent is sent with each request; some sites refuse Go's default.
+	UserAgent string
+
+	mu    sync.Mutex
+	cache map[string]*cached
+	hosts map[string]chan struct{}
+}
+
+type cached struct {
+	done   chan struct{}
+	result Result
+}
+
+// Check checks rawURL, or waits for and returns the result of an earlier check.
+func (c *Checker) Check(ctx context.Context, rawURL string) Result {
+	c.mu.Lock()
+	if c.cache == nil {
+		c.cache = map[string]*cached{}
+	}
+	if entry, ok := c.cache[rawURL]; ok {
+		c.mu.Unlock()
+		select {
+		case <-entry.done:
+			return entry.result
+		case <-ctx.Done():
+			return Result{URL: rawURL, Err: ctx.Err()}
+		}
+	}
+	entry := &cached{done: make(chan struct{})}
+	c.cache[rawURL] = entry
+	c.mu.Unlock()
+
+	entry.result = c.check(ctx, rawURL)
+	if ctx.Err() != nil {
+		// Do not remember results cut short by cancellation.
+		c.mu.Lock()
+		delete(c.cache, rawURL)
+		c.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.result
+}
+
+// CheckAll checks urls with Workers concurrent checks.
+func (c *Checker) CheckAll(ctx context.Context, urls []string) map[string]Result {
+	workers := c.Workers
+	if workers <= 0 {
+		workers = 8
+	}
+	results := make(map[string]Result, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for u := range jobs {
+				r := c.Check(ctx, u)
+				mu.Lock()
+				results[u] = r
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, u := range urls {
+		jobs <- u
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func (c *Checker) check(ctx context.Context, rawURL string) Result {
+	result := Result{URL: rawURL}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		result.Err = errors.Errorf("Invalid URL %q", rawURL)
+		return result
+	}
+
+	release, err := c.acquire(ctx, u.Host)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer release()
+
+	// Some servers do not implement HEAD or answer it differently; a failed
+	// HEAD is confirmed with GET.
+	result = c.request(ctx, http.MethodHead, rawURL)
+	if result.Broken() && ctx.Err() == nil {
+		result = c.request(ctx, http.MethodGet, rawURL)
+	}
+	return result
+}
+
+func (c *Checker) request(ctx context.Context, method, rawURL string) Result {
+	result := Result{URL: rawURL}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	// Drain a little of the body so that the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if final := resp.Request.URL.String(); final != rawURL {
+		result.Final = final
+	}
+	return result
+}
+
+// acquire waits for a free request slot of host.
+func (c *Checker) acquire(ctx context.Context, host string) (func(), error) {
+	c.mu.Lock()
+	if c.hosts == nil {
+		c.hosts = map[string]chan struct{}{}
+	}
+	slots, ok := c.hosts[host]
+	if !ok {
+		perHost := c.PerHost
+		if perHost <= 0 {
+			perHost = 2
+		}
+		slots = make(chan struct{}, perHost)
+		c.hosts[host] = slots
+	}
+	c.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
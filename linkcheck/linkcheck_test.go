@@ -0,0 +1,163 @@
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+// linkServer serves /ok, /gone (404), /nohead (405 for HEAD), /moved
+// (redirect to /ok) and /slow, counting requests and concurrent ones.
+type linkServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests map[string]int
+	active   int
+	peak     int
+}
+
+func newLinkServer() *linkServer {
+	s := &linkServer{requests: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.Method+" "+r.URL.Path]++
+		s.active++
+		s.peak = max(s.peak, s.active)
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			s.active--
+			s.mu.Unlock()
+		}()
+
+		switch r.URL.Path {
+		case "/ok", "/img.png":
+		case "/slow":
+			time.Sleep(20 * time.Millisecond)
+		case "/nohead":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		case "/moved":
+			http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+func TestChecker(t *testing.T) {
+	links := newLinkServer()
+	defer links.Close()
+
+	c := &Checker{Client: links.Client(), Workers: 8, PerHost: 2}
+	var urls []string
+	for i := 0; i < 4; i++ {
+		urls = append(urls, links.URL+"/slow?"+string(rune('a'+i)))
+	}
+	urls = append(urls, links.URL+"/ok", links.URL+"/gone", links.URL+"/nohead", links.URL+"/moved", "ftp://example.com/x")
+	results := c.CheckAll(context.Background(), urls)
+
+	broken := map[string]bool{}
+	for u, r := range results {
+		broken[u] = r.Broken()
+	}
+	want := map[string]bool{links.URL + "/ok": false, links.URL + "/gone": true, links.URL + "/nohead": false,
+		links.URL + "/moved": false, "ftp://example.com/x": true}
+	for _, u := range urls[:4] {
+		want[u] = false
+	}
+	if !reflect.DeepEqual(broken, want) {
+		t.Errorf("Checker.CheckAll() broken = %v, want %v", broken, want)
+	}
+	if r := results[links.URL+"/moved"]; r.Final != links.URL+"/ok" || r.StatusCode != 200 {
+		t.Errorf("redirect result = %+v", r)
+	}
+	if r := results[links.URL+"/gone"]; r.Status() != "404 Not Found" {
+		t.Errorf("Result.Status() = %q", r.Status())
+	}
+	if links.peak > 2 {
+		t.Errorf("peak concurrent requests = %d, want at most PerHost 2", links.peak)
+	}
+
+	c.Check(context.Background(), links.URL+"/gone")
+	if n := links.requests["HEAD /gone"] + links.requests["GET /gone"]; n != 2 {
+		t.Errorf("requests for /gone = %d, want 2 (HEAD and GET, then cached)", n)
+	}
+}
+
+func TestCheckBlog(t *testing.T) {
+	links := newLinkServer()
+	defer links.Close()
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+
+	first := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "first", Visibility: 3,
+		Content: `<p><a href="` + links.URL + `/ok">ok</a> <a href="` + links.URL + `/gone#top">gone</a> <a href="#x">anchor</a>` +
+			` <a href="mailto:a@b.test">mail</a></p><p><img src="` + links.URL + `/missing.png">[##_Image|kage@k/img.png|CDM|1.3|{}_##]</p>`})
+	second := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "second", Visibility: 3,
+		Content: `<p><a href="` + links.URL + `/gone">again</a><img src="` + links.URL + `/img.png"></p>`})
+	srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "clean", Content: "<p>no links</p>"})
+
+	c := &Checker{Client: links.Client()}
+	reports, err := c.CheckBlog(context.Background(), blog, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[int][]string{}
+	for _, r := range reports {
+		for _, b := range r.Broken {
+			got[r.PostId] = append(got[r.PostId], b.Kind+" "+b.URL+" "+b.Status())
+		}
+	}
+	want := map[int][]string{
+		first:  {"link " + links.URL + "/gone 404 Not Found", "image " + links.URL + "/missing.png 404 Not Found"},
+		second: {"link " + links.URL + "/gone 404 Not Found"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Checker.CheckBlog() broken = %q, want %q", got, want)
+	}
+	if n := links.requests["GET /gone"]; n != 1 {
+		t.Errorf("GET /gone requests = %d, want 1 for both posts", n)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteFixups(&buf, Fixups(reports)); err != nil {
+		t.Fatal(err)
+	}
+	fixups, err := ReadFixups(&buf)
+	if err != nil || len(fixups) != 3 || fixups[0].Status != "404 Not Found" {
+		t.Fatalf("ReadFixups() = %+v, %v", fixups, err)
+	}
+	for i, f := range fixups {
+		switch {
+		case f.PostId == first && f.Kind == "link":
+			fixups[i].Replace = "https://example.com/new"
+		case f.Kind == "image":
+			fixups[i].Unlink = true
+		}
+	}
+
+	// The fixup of the second post has no action and is skipped.
+	selector, mutator := Mutator(fixups)
+	results, err := blog.BulkModify(context.Background(), selector, mutator, nil)
+	if err != nil || len(results) != 1 || results[0].PostId != first || !results[0].Changed {
+		t.Fatalf("BulkModify(Mutator) = %+v, %v", results, err)
+	}
+	post, _ := srv.Post(tistorytest.DefaultBlog, first)
+	wantContent := `<p><a href="` + links.URL + `/ok">ok</a> <a href="https://example.com/new">gone</a> <a href="#x">anchor</a>` +
+		` <a href="mailto:a@b.test">mail</a></p><p>[##_Image|kage@k/img.png|CDM|1.3|{}_##]</p>`
+	if post.Content != wantContent {
+		t.Errorf("fixed content = %q, want %q", post.Content, wantContent)
+	}
+}
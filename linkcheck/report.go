@@ -0,0 +1,154 @@
+package linkcheck
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/LimJiAn/tistory-go"
+	"golang.org/x/net/html"
+)
+
+// Link is a link or image of a post body.
+type Link struct {
+	URL  string
+	Kind string // "link" or "image"
+}
+
+/*
+Extract returns the http(s) links and image sources of an HTML body, each
+once, in order. Relative URLs are resolved against base, the post URL;
+fragments, mailto: and other schemes are skipped. Uploaded images written
+as replacers are not included: they are served by Tistory.
+*/
+func Extract(body, base string) []Link {
+	baseURL, _ := url.Parse(base)
+	seen := map[string]bool{}
+	var links []Link
+	z := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF, or a read error a string cannot have.
+			return links
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		token := z.Token()
+		kind, key := "link", "href"
+		switch token.Data {
+		case "a":
+		case "img":
+			kind, key = "image", "src"
+		default:
+			continue
+		}
+		for _, a := range token.Attr {
+			if a.Key != key {
+				continue
+			}
+			u := resolve(baseURL, a.Val)
+			if u != "" && !seen[u] {
+				seen[u] = true
+				links = append(links, Link{URL: u, Kind: kind})
+			}
+		}
+	}
+}
+
+// resolve returns the absolute http(s) URL of ref without its fragment, or "".
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return ""
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// Broken is a dead link of a post.
+type Broken struct {
+	Link
+	StatusCode int
+	Err        error
+}
+
+// Status describes why the link is broken, e.g. "404 Not Found".
+func (b Broken) Status() string {
+	return Result{StatusCode: b.StatusCode, Err: b.Err}.Status()
+}
+
+// PostReport lists the broken links of a post.
+type PostReport struct {
+	PostId  int
+	Title   string
+	PostURL string
+	Links   int // links and images checked
+	Broken  []Broken
+	Err     error // reading the post failed
+}
+
+/*
+CheckBlog reads the posts chosen by selector (nil: all) and checks their
+links. Posts are read one at a time; the links of all posts are then
+checked together, so a URL used by many posts is requested once. Reports
+follow the post/list order. The error is for listing posts or a canceled
+ctx.
+*/
+func (c *Checker) CheckBlog(ctx context.Context, blog *tistory.Tistory, selector tistory.PostSelector) ([]PostReport, error) {
+	posts, err := blog.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+	var reports []PostReport
+	var links [][]Link
+	var urls []string
+	seen := map[string]bool{}
+	for _, p := range posts {
+		if selector != nil && !selector(p) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return reports, err
+		}
+		report := PostReport{PostId: p.Id, Title: p.Title, PostURL: p.PostURL}
+		post, err := blog.ReadPost(p.Id)
+		if err != nil {
+			report.Err = err
+		}
+		postLinks := Extract(post.Content, p.PostURL)
+		for _, l := range postLinks {
+			if !seen[l.URL] {
+				seen[l.URL] = true
+				urls = append(urls, l.URL)
+			}
+		}
+		report.Links = len(postLinks)
+		reports = append(reports, report)
+		links = append(links, postLinks)
+	}
+
+	results := c.CheckAll(ctx, urls)
+	if err := ctx.Err(); err != nil {
+		return reports, err
+	}
+	for i := range reports {
+		for _, l := range links[i] {
+			if r := results[l.URL]; r.Broken() {
+				reports[i].Broken = append(reports[i].Broken, Broken{Link: l, StatusCode: r.StatusCode, Err: r.Err})
+			}
+		}
+	}
+	return reports, nil
+}
@@ -0,0 +1,352 @@
+// Package mirror keeps a local SQLite copy of a Tistory blog: posts with
+// their tags, categories and comments. Sync only reads the posts whose
+// post/list metadata changed since the last sync, so that listing and
+// querying the blog works offline and without a GetPost per post.
+//
+//	m, err := mirror.Open("blog.db")
+//	defer m.Close()
+//	result, err := m.Sync(ctx, blog, nil)
+//	posts, err := m.Posts(ctx, mirror.Query{Category: "Go", Tag: "api", Since: since})
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+
+	// Pure-Go SQLite driver registered as "sqlite".
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS posts (
+	id             INTEGER PRIMARY KEY,
+	title          TEXT    NOT NULL,
+	content        TEXT    NOT NULL,
+	post_url       TEXT    NOT NULL,
+	visibility     TEXT    NOT NULL,
+	category_id    INTEGER NOT NULL,
+	accept_comment INTEGER NOT NULL,
+	comments       INTEGER NOT NULL,
+	date           INTEGER NOT NULL,
+	synced_at      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS posts_category ON posts (category_id);
+CREATE INDEX IF NOT EXISTS posts_date ON posts (date);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+	post_id  INTEGER NOT NULL,
+	tag      TEXT    NOT NULL,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (post_id, tag)
+);
+CREATE INDEX IF NOT EXISTS post_tags_tag ON post_tags (tag);
+
+CREATE TABLE IF NOT EXISTS categories (
+	id      INTEGER PRIMARY KEY,
+	name    TEXT    NOT NULL,
+	parent  INTEGER NOT NULL,
+	label   TEXT    NOT NULL,
+	entries INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS comments (
+	id        INTEGER PRIMARY KEY,
+	post_id   INTEGER NOT NULL,
+	parent_id INTEGER NOT NULL,
+	name      TEXT    NOT NULL,
+	homepage  TEXT    NOT NULL,
+	content   TEXT    NOT NULL,
+	secret    INTEGER NOT NULL,
+	date      INTEGER NOT NULL,
+	link      TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS comments_post ON comments (post_id);
+
+CREATE TABLE IF NOT EXISTS meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+`
+
+// Mirror is a local SQLite copy of a blog.
+type Mirror struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the mirror database at path.
+func Open(path string) (*Mirror, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, errors.Wrap(err, "Failed to create mirror directory")
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to open mirror (%s)", path)
+	}
+	// SQLite allows one writer; a single connection avoids "database is locked".
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrapf(err, "Failed to create mirror schema (%s)", path)
+	}
+	return &Mirror{db: db}, nil
+}
+
+// Close closes the database.
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+// SyncOptions tunes Sync. A nil *SyncOptions uses the defaults.
+type SyncOptions struct {
+	// Full reads every post again, not only those whose metadata changed.
+	// post/list does not report content edits that keep the publish date.
+	Full bool
+	// Progress, if set, is called after each post that was read.
+	Progress func(post tistory.PostSummary)
+}
+
+// SyncResult counts what Sync did.
+type SyncResult struct {
+	Posts      int // posts of the blog
+	Fetched    int // posts read with GetPost
+	Deleted    int // posts removed from the mirror
+	Comments   int // posts whose comments were read again
+	Categories int
+}
+
+// summary is the post/list metadata stored for a post.
+type summary struct {
+	title, postURL, visibility string
+	categoryId, comments       int
+	date                       int64
+}
+
+/*
+Sync updates the mirror from blog. It lists every post with GetPostList and
+reads with GetPost only new posts and posts whose title, URL, visibility,
+category or date changed; comments are read again when the comment count
+changed. Posts no longer listed are removed. Each post is stored in its own
+transaction, so an interrupted sync keeps the posts done so far.
+*/
+func (m *Mirror) Sync(ctx context.Context, blog *tistory.Tistory, options *SyncOptions) (*SyncResult, error) {
+	if options == nil {
+		options = &SyncOptions{}
+	}
+	result := &SyncResult{}
+
+	categories, err := blog.Categories()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.saveCategories(ctx, categories); err != nil {
+		return nil, err
+	}
+	result.Categories = len(categories)
+
+	posts, err := blog.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+	result.Posts = len(posts)
+
+	stored, err := m.summaries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range posts {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		old, ok := stored[p.Id]
+		delete(stored, p.Id)
+		current := summary{
+			title:      p.Title,
+			postURL:    p.PostURL,
+			visibility: p.Visibility,
+			categoryId: p.CategoryId,
+			comments:   p.Comments,
+			date:       p.Date.Unix(),
+		}
+		postChanged := !ok || options.Full || old.title != current.title || old.postURL != current.postURL ||
+			old.visibility != current.visibility || old.categoryId != current.categoryId || old.date != current.date
+		commentsChanged := !ok && p.Comments > 0 || ok && old.comments != p.Comments || options.Full && p.Comments > 0
+		if !postChanged && !commentsChanged {
+			continue
+		}
+
+		var post *tistory.Post
+		if postChanged {
+			read, err := blog.ReadPost(p.Id)
+			if err != nil {
+				return result, err
+			}
+			// post/read has no comment count on every blog; keep the listed one.
+			read.Comments = p.Comments
+			post = &read
+			result.Fetched++
+		}
+		var comments []tistory.Comment
+		if commentsChanged {
+			list, err := blog.GetCommentList(p.Id)
+			if err != nil {
+				return result, err
+			}
+			if comments, err = tistory.ParseComments(list); err != nil {
+				return result, err
+			}
+			result.Comments++
+		}
+		if err := m.savePost(ctx, p, post, comments, commentsChanged); err != nil {
+			return result, err
+		}
+		if options.Progress != nil && postChanged {
+			options.Progress(p)
+		}
+	}
+
+	for id := range stored {
+		if err := m.deletePost(ctx, id); err != nil {
+			return result, err
+		}
+		result.Deleted++
+	}
+	return result, m.setMeta(ctx, "synced_at", strconv.FormatInt(time.Now().Unix(), 10))
+}
+
+// SyncedAt returns the time of the last complete Sync (zero if none).
+func (m *Mirror) SyncedAt(ctx context.Context) (time.Time, error) {
+	var value string
+	err := m.db.QueryRowContext(ctx, `SELECT value FROM meta WHERE key = 'synced_at'`).Scan(&value)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "Failed to read mirror meta")
+	}
+	ts, _ := strconv.ParseInt(value, 10, 64)
+	return time.Unix(ts, 0), nil
+}
+
+func (m *Mirror) setMeta(ctx context.Context, key, value string) error {
+	_, err := m.db.ExecContext(ctx, `INSERT INTO meta (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+	return errors.Wrap(err, "Failed to write mirror meta")
+}
+
+func (m *Mirror) summaries(ctx context.Context) (map[int]summary, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT id, title, post_url, visibility, category_id, comments, date FROM posts`)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read mirrored posts")
+	}
+	defer rows.Close()
+
+	summaries := map[int]summary{}
+	for rows.Next() {
+		var id int
+		var s summary
+		if err := rows.Scan(&id, &s.title, &s.postURL, &s.visibility, &s.categoryId, &s.comments, &s.date); err != nil {
+			return nil, errors.Wrap(err, "Failed to read mirrored posts")
+		}
+		summaries[id] = s
+	}
+	return summaries, errors.Wrap(rows.Err(), "Failed to read mirrored posts")
+}
+
+func (m *Mirror) saveCategories(ctx context.Context, categories []tistory.Category) error {
+	return m.tx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM categories`); err != nil {
+			return err
+		}
+		for _, c := range categories {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO categories (id, name, parent, label, entries) VALUES (?, ?, ?, ?, ?)`,
+				c.Id, c.Name, c.Parent, c.Label, c.Entries); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// savePost stores the listed metadata of p, the post read with GetPost
+// (nil to keep the stored content) and, if replaceComments, its comments.
+func (m *Mirror) savePost(ctx context.Context, p tistory.PostSummary, post *tistory.Post, comments []tistory.Comment, replaceComments bool) error {
+	return m.tx(ctx, func(tx *sql.Tx) error {
+		now := time.Now().Unix()
+		if post != nil {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO posts
+				(id, title, content, post_url, visibility, category_id, accept_comment, comments, date, synced_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET title = excluded.title, content = excluded.content,
+					post_url = excluded.post_url, visibility = excluded.visibility, category_id = excluded.category_id,
+					accept_comment = excluded.accept_comment, comments = excluded.comments, date = excluded.date,
+					synced_at = excluded.synced_at`,
+				p.Id, p.Title, post.Content, p.PostURL, p.Visibility, p.CategoryId, post.AcceptComment,
+				p.Comments, p.Date.Unix(), now); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `DELETE FROM post_tags WHERE post_id = ?`, p.Id); err != nil {
+				return err
+			}
+			for i, tag := range post.Tags {
+				if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO post_tags (post_id, tag, position) VALUES (?, ?, ?)`, p.Id, tag, i); err != nil {
+					return err
+				}
+			}
+		} else if _, err := tx.ExecContext(ctx, `UPDATE posts SET comments = ?, synced_at = ? WHERE id = ?`,
+			p.Comments, now, p.Id); err != nil {
+			return err
+		}
+
+		if !replaceComments {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM comments WHERE post_id = ?`, p.Id); err != nil {
+			return err
+		}
+		for _, c := range comments {
+			if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO comments
+				(id, post_id, parent_id, name, homepage, content, secret, date, link)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				c.Id, p.Id, c.ParentId, c.Name, c.Homepage, c.Content, c.Secret, c.Date.Unix(), c.Link); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (m *Mirror) deletePost(ctx context.Context, postId int) error {
+	return m.tx(ctx, func(tx *sql.Tx) error {
+		for _, query := range []string{
+			`DELETE FROM posts WHERE id = ?`,
+			`DELETE FROM post_tags WHERE post_id = ?`,
+			`DELETE FROM comments WHERE post_id = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, postId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// tx runs fn in a transaction, committing if it returns nil.
+func (m *Mirror) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "Failed to begin mirror transaction")
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "Failed to write mirror")
+	}
+	return errors.Wrap(tx.Commit(), "Failed to commit mirror transaction")
+}
@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestMirror_Sync(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	blog := testblog.New(srv)
+
+	dev := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "dev"})
+	golang := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "Go", Parent: dev})
+	life := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "life"})
+	base := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+	api := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "Go API", Content: "<p>api</p>",
+		CategoryID: golang, Tags: []string{"go", "api"}, Visibility: 3, Published: base})
+	srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "Go CLI", CategoryID: golang,
+		Tags: []string{"go", "cli"}, Visibility: 3, Published: base.Add(48 * time.Hour)})
+	srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "Walk", CategoryID: life,
+		Tags: []string{"api"}, Visibility: 3, Published: base.Add(24 * time.Hour)})
+	srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: api, Name: "reader", Content: "question", Date: base})
+
+	m, err := Open(filepath.Join(t.TempDir(), "mirror", "blog.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer m.Close()
+
+	result, err := m.Sync(ctx, blog, nil)
+	if err != nil {
+		t.Fatalf("Mirror.Sync() error = %v", err)
+	}
+	if result.Posts != 3 || result.Fetched != 3 || result.Comments != 1 || result.Categories != 3 {
+		t.Errorf("Mirror.Sync() = %+v", result)
+	}
+	if result, err = m.Sync(ctx, blog, nil); err != nil || result.Fetched != 0 || result.Comments != 0 {
+		t.Errorf("Mirror.Sync() unchanged = %+v, %v, want nothing read", result, err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "all", query: Query{}, want: []string{"Go CLI", "Walk", "Go API"}},
+		{name: "category name", query: Query{Category: "Go"}, want: []string{"Go CLI", "Go API"}},
+		{name: "parent category", query: Query{Category: "dev"}, want: []string{"Go CLI", "Go API"}},
+		{name: "category and tag", query: Query{Category: "dev/Go", Tag: "api"}, want: []string{"Go API"}},
+		{name: "tag since", query: Query{Tag: "api", Since: base.Add(time.Hour)}, want: []string{"Walk"}},
+		{name: "title", query: Query{Title: "go", Until: base.Add(time.Hour)}, want: []string{"Go API"}},
+		{name: "limit", query: Query{Limit: 1}, want: []string{"Go CLI"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts, err := m.Posts(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("Mirror.Posts() error = %v", err)
+			}
+			var got []string
+			for _, p := range posts {
+				got = append(got, p.Title)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Mirror.Posts() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	post, ok, err := m.Post(ctx, api)
+	if err != nil || !ok || post.Content != "<p>api</p>" || strings.Join(post.Tags, ",") != "go,api" || post.Comments != 1 {
+		t.Errorf("Mirror.Post() = %+v, %v, %v", post, ok, err)
+	}
+	if comments, err := m.Comments(ctx, api); err != nil || len(comments) != 1 || comments[0].Content != "question" {
+		t.Errorf("Mirror.Comments() = %+v, %v", comments, err)
+	}
+
+	// An edit moves the date, a comment changes the count, a stale row is removed.
+	if _, err := blog.EditPost(api, map[string]interface{}{"title": "Go API v2"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.db.Exec(`INSERT INTO posts (id, title, content, post_url, visibility, category_id, accept_comment, comments, date, synced_at)
+		VALUES (999, 'gone', '', '', '20', 0, 1, 0, 0, 0)`); err != nil {
+		t.Fatal(err)
+	}
+	srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: api, Name: "reader", Content: "again", Date: base.Add(time.Hour)})
+	if result, err = m.Sync(ctx, blog, nil); err != nil || result.Fetched != 1 || result.Comments != 1 || result.Deleted != 1 {
+		t.Errorf("Mirror.Sync() after edit = %+v, %v", result, err)
+	}
+	if post, _, _ := m.Post(ctx, api); post.Title != "Go API v2" || post.Comments != 2 {
+		t.Errorf("Mirror.Post() after edit = %+v", post)
+	}
+	if _, ok, _ := m.Post(ctx, 999); ok {
+		t.Errorf("Mirror.Post(999) ok = true, want the stale post removed")
+	}
+	if at, err := m.SyncedAt(ctx); err != nil || at.IsZero() {
+		t.Errorf("Mirror.SyncedAt() = %v, %v", at, err)
+	}
+}
@@ -0,0 +1,163 @@
+package mirror
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// Query selects mirrored posts. Zero fields match every post.
+type Query struct {
+	// Category is a category ID, name or label ("parent/child"); a parent
+	// category also matches the posts of its children.
+	Category   string
+	Tag        string
+	Title      string // case-insensitive substring of the title
+	Visibility string // 0: 비공개, 15: 보호, 20: 발행
+	Since      time.Time
+	Until      time.Time // exclusive
+	Limit      int
+}
+
+// Posts returns the posts matching q, newest first.
+func (m *Mirror) Posts(ctx context.Context, q Query) ([]tistory.Post, error) {
+	var where []string
+	var args []interface{}
+	if q.Category != "" {
+		where = append(where, `p.category_id IN (SELECT id FROM categories
+			WHERE CAST(id AS TEXT) = ? OR name = ? OR label = ? OR substr(label, 1, ?) = ?)`)
+		args = append(args, q.Category, q.Category, q.Category, len(q.Category)+1, q.Category+"/")
+	}
+	if q.Tag != "" {
+		where = append(where, `p.id IN (SELECT post_id FROM post_tags WHERE tag = ?)`)
+		args = append(args, q.Tag)
+	}
+	if q.Title != "" {
+		where = append(where, `instr(lower(p.title), lower(?)) > 0`)
+		args = append(args, q.Title)
+	}
+	if q.Visibility != "" {
+		where = append(where, `p.visibility = ?`)
+		args = append(args, q.Visibility)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, `p.date >= ?`)
+		args = append(args, q.Since.Unix())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, `p.date < ?`)
+		args = append(args, q.Until.Unix())
+	}
+
+	query := `SELECT p.id, p.title, p.content, p.post_url, p.visibility, p.category_id, p.accept_comment,
+		p.comments, p.date, ` + tagsColumn + `
+		FROM posts p`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY p.date DESC, p.id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(q.Limit)
+	}
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to query mirrored posts")
+	}
+	defer rows.Close()
+
+	var posts []tistory.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to query mirrored posts")
+		}
+		posts = append(posts, post)
+	}
+	return posts, errors.Wrap(rows.Err(), "Failed to query mirrored posts")
+}
+
+// Post returns a mirrored post; ok is false if it is not in the mirror.
+func (m *Mirror) Post(ctx context.Context, postId int) (post tistory.Post, ok bool, err error) {
+	row := m.db.QueryRowContext(ctx, `SELECT p.id, p.title, p.content, p.post_url, p.visibility, p.category_id,
+		p.accept_comment, p.comments, p.date, `+tagsColumn+`
+		FROM posts p WHERE p.id = ?`, postId)
+	post, err = scanPost(row)
+	if err == sql.ErrNoRows {
+		return tistory.Post{}, false, nil
+	}
+	if err != nil {
+		return tistory.Post{}, false, errors.Wrapf(err, "Failed to read mirrored post (postId: %d)", postId)
+	}
+	return post, true, nil
+}
+
+// Comments returns the mirrored comments of a post, oldest first.
+func (m *Mirror) Comments(ctx context.Context, postId int) ([]tistory.Comment, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT id, post_id, parent_id, name, homepage, content, secret, date, link
+		FROM comments WHERE post_id = ? ORDER BY date, id`, postId)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to query mirrored comments")
+	}
+	defer rows.Close()
+
+	var comments []tistory.Comment
+	for rows.Next() {
+		var c tistory.Comment
+		var date int64
+		if err := rows.Scan(&c.Id, &c.PostId, &c.ParentId, &c.Name, &c.Homepage, &c.Content, &c.Secret, &date, &c.Link); err != nil {
+			return nil, errors.Wrap(err, "Failed to query mirrored comments")
+		}
+		c.Date = time.Unix(date, 0)
+		comments = append(comments, c)
+	}
+	return comments, errors.Wrap(rows.Err(), "Failed to query mirrored comments")
+}
+
+// Categories returns the mirrored categories ordered by label.
+func (m *Mirror) Categories(ctx context.Context) ([]tistory.Category, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT id, name, parent, label, entries FROM categories ORDER BY label`)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to query mirrored categories")
+	}
+	defer rows.Close()
+
+	var categories []tistory.Category
+	for rows.Next() {
+		var c tistory.Category
+		if err := rows.Scan(&c.Id, &c.Name, &c.Parent, &c.Label, &c.Entries); err != nil {
+			return nil, errors.Wrap(err, "Failed to query mirrored categories")
+		}
+		categories = append(categories, c)
+	}
+	return categories, errors.Wrap(rows.Err(), "Failed to query mirrored categories")
+}
+
+// tagsColumn selects the tags of post p in order, separated by newlines.
+const tagsColumn = `(SELECT group_concat(tag, char(10)) FROM
+	(SELECT tag FROM post_tags WHERE post_id = p.id ORDER BY position))`
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPost(row scanner) (tistory.Post, error) {
+	var p tistory.Post
+	var date int64
+	var tags sql.NullString
+	if err := row.Scan(&p.Id, &p.Title, &p.Content, &p.PostURL, &p.Visibility, &p.CategoryId,
+		&p.AcceptComment, &p.Comments, &date, &tags); err != nil {
+		return tistory.Post{}, err
+	}
+	p.Date = time.Unix(date, 0)
+	if tags.String != "" {
+		p.Tags = strings.Split(tags.String, "\n")
+	}
+	return p, nil
+}
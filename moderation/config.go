@@ -0,0 +1,92 @@
+package moderation
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleConfig is one rule of a rules file. Exactly one matcher field is set.
+//
+//	rules:
+//	  - name: casino
+//	    blocklist: ["카지노", "(?i)viagra"]
+//	    action: delete
+//	  - name: links
+//	    max_links: 2
+//	    action: mark
+//	  - name: repeated
+//	    repeated: 3
+//	    action: delete
+//	  - name: foreign
+//	    min_hangul_ratio: 0.2
+//	    min_letters: 20
+//	    action: mark
+type RuleConfig struct {
+	Name           string   `yaml:"name"`
+	Action         string   `yaml:"action"`
+	Blocklist      []string `yaml:"blocklist,omitempty"`
+	MaxLinks       *int     `yaml:"max_links,omitempty"`
+	Repeated       int      `yaml:"repeated,omitempty"`
+	MinHangulRatio float64  `yaml:"min_hangul_ratio,omitempty"`
+	MinLetters     int      `yaml:"min_letters,omitempty"`
+}
+
+// RulesConfig is the content of a rules file (YAML or JSON).
+type RulesConfig struct {
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// LoadRules reads a rules file.
+func LoadRules(r io.Reader) ([]Rule, error) {
+	var cfg RulesConfig
+	if err := yaml.NewDecoder(r).Decode(&cfg); err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Failed to parse rules")
+	}
+	return cfg.Build()
+}
+
+// Build converts the configuration into rules.
+func (c RulesConfig) Build() ([]Rule, error) {
+	rules := make([]Rule, 0, len(c.Rules))
+	for i, rc := range c.Rules {
+		if rc.Name == "" {
+			return nil, errors.Errorf("rule %d has no name", i+1)
+		}
+		action, err := ParseAction(rc.Action)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %q", rc.Name)
+		}
+
+		var rule Rule
+		matchers := 0
+		if len(rc.Blocklist) > 0 {
+			matchers++
+			if rule, err = Blocklist(rc.Name, action, rc.Blocklist...); err != nil {
+				return nil, err
+			}
+		}
+		if rc.MaxLinks != nil {
+			matchers++
+			rule = MaxLinks(rc.Name, action, *rc.MaxLinks)
+		}
+		if rc.Repeated > 0 {
+			matchers++
+			rule = Repeated(rc.Name, action, rc.Repeated)
+		}
+		if rc.MinHangulRatio > 0 {
+			matchers++
+			minLetters := rc.MinLetters
+			if minLetters == 0 {
+				minLetters = 10
+			}
+			rule = NonKorean(rc.Name, action, rc.MinHangulRatio, minLetters)
+		}
+		if matchers != 1 {
+			return nil, errors.Errorf("rule %q needs exactly one of blocklist, max_links, repeated, min_hangul_ratio", rc.Name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,216 @@
+// Package moderation deletes or marks spam comments of a Tistory blog.
+//
+// A Moderator polls GetRecentCommentList, evaluates each new comment against
+// its rules and applies the strongest matching action through DeleteComment
+// or ModifyComment. Every decision is written to an audit log; with DryRun
+// the decisions are logged without touching the blog.
+//
+//	rules, err := moderation.LoadRules(file)
+//	m := moderation.New(blog, rules)
+//	m.DryRun = true
+//	m.Audit = os.Stdout
+//	err = m.Run(ctx, time.Minute)
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+)
+
+// DefaultMarkPrefix is prepended to the content of marked comments.
+const DefaultMarkPrefix = "[스팸 의심] "
+
+// Decision is the outcome of evaluating one comment.
+type Decision struct {
+	Time    time.Time       `json:"time"`
+	Comment tistory.Comment `json:"comment"`
+	Rules   []string        `json:"rules"`
+	Reasons []string        `json:"reasons"`
+	Action  Action          `json:"action"`
+	DryRun  bool            `json:"dryRun"`
+	Applied bool            `json:"applied"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// Moderator applies rules to the newest comments of a blog.
+type Moderator struct {
+	Blog  *tistory.Tistory
+	Rules []Rule
+
+	// DryRun logs decisions without deleting or modifying comments.
+	DryRun bool
+	// Audit receives one JSON line per matched comment (nil: no log).
+	Audit io.Writer
+	// Pages is the number of comment/newest pages (10 comments each) read per poll.
+	Pages int
+	// Owner is the blog nickname; the owner's comments are never moderated.
+	Owner string
+	// MarkPrefix is prepended to marked comments (default: DefaultMarkPrefix).
+	MarkPrefix string
+	// ErrorLog receives the poll errors Run keeps going after (nil: no log).
+	ErrorLog io.Writer
+
+	history *History
+	seen    map[int]bool
+}
+
+func New(blog *tistory.Tistory, rules []Rule) *Moderator {
+	return &Moderator{Blog: blog, Rules: rules, Pages: 1}
+}
+
+/*
+Run polls every interval until ctx is done. A zero interval polls once and
+returns its error; otherwise a failed poll is written to ErrorLog and retried
+at the next interval.
+*/
+func (m *Moderator) Run(ctx context.Context, interval time.Duration) error {
+	for {
+		_, err := m.Poll()
+		if interval <= 0 {
+			return err
+		}
+		if err != nil && m.ErrorLog != nil {
+			fmt.Fprintf(m.ErrorLog, "moderate: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Poll evaluates the comments not seen by earlier polls and returns the
+// decisions for the ones matched by a rule.
+func (m *Moderator) Poll() ([]Decision, error) {
+	if m.history == nil {
+		m.history = newHistory()
+		m.seen = map[int]bool{}
+	}
+	comments, err := m.recentComments()
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first, so repeated content is detected on the later copies.
+	var decisions []Decision
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if m.seen[c.Id] {
+			continue
+		}
+		m.seen[c.Id] = true
+		m.history.add(c.Content)
+
+		d, ok := m.Evaluate(c)
+		if !ok {
+			continue
+		}
+		m.apply(&d)
+		decisions = append(decisions, d)
+		if err := m.audit(d); err != nil {
+			return decisions, err
+		}
+	}
+	return decisions, nil
+}
+
+func (m *Moderator) recentComments() ([]tistory.Comment, error) {
+	pages := m.Pages
+	if pages < 1 {
+		pages = 1
+	}
+	var comments []tistory.Comment
+	for page := 1; page <= pages; page++ {
+		result, err := m.Blog.GetRecentCommentList(page, 10)
+		if err != nil {
+			return nil, err
+		}
+		list, err := tistory.ParseComments(result)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, list...)
+		if len(list) < 10 {
+			break
+		}
+	}
+	return comments, nil
+}
+
+// Evaluate runs every rule on c. The decision carries the strongest action
+// of the matching rules; ok is false when no rule matched.
+func (m *Moderator) Evaluate(c tistory.Comment) (Decision, bool) {
+	d := Decision{Time: time.Now(), Comment: c, DryRun: m.DryRun}
+	if m.Owner != "" && c.Name == m.Owner {
+		return d, false
+	}
+	if strings.HasPrefix(c.Content, m.markPrefix()) {
+		// Already marked by an earlier run.
+		return d, false
+	}
+
+	for _, rule := range m.Rules {
+		matched, reason := rule.Match(c, m.history)
+		if !matched {
+			continue
+		}
+		d.Rules = append(d.Rules, rule.Name)
+		d.Reasons = append(d.Reasons, reason)
+		if rule.Action > d.Action {
+			d.Action = rule.Action
+		}
+	}
+	return d, len(d.Rules) > 0
+}
+
+func (m *Moderator) apply(d *Decision) {
+	if m.DryRun || d.Action == ActionNone {
+		return
+	}
+
+	var err error
+	switch d.Action {
+	case ActionDelete:
+		_, err = m.Blog.DeleteComment(map[string]interface{}{
+			"postId":    d.Comment.PostId,
+			"commentId": d.Comment.Id,
+		})
+	case ActionMark:
+		option := map[string]interface{}{
+			"postId":    d.Comment.PostId,
+			"commentId": d.Comment.Id,
+			"content":   m.markPrefix() + d.Comment.Content,
+			"secret":    1,
+		}
+		if d.Comment.ParentId != 0 {
+			option["parentId"] = d.Comment.ParentId
+		}
+		_, err = m.Blog.ModifyComment(option)
+	}
+	if err != nil {
+		d.Error = err.Error()
+		return
+	}
+	d.Applied = true
+}
+
+func (m *Moderator) audit(d Decision) error {
+	if m.Audit == nil {
+		return nil
+	}
+	return json.NewEncoder(m.Audit).Encode(d)
+}
+
+func (m *Moderator) markPrefix() string {
+	if m.MarkPrefix == "" {
+		return DefaultMarkPrefix
+	}
+	return m.MarkPrefix
+}
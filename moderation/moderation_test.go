@@ -0,0 +1,165 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+const testRules = `
+rules:
+  - name: casino
+    blocklist: ["카지노", "(?i)viagra"]
+    action: delete
+  - name: links
+    max_links: 1
+    action: mark
+  - name: repeated
+    repeated: 2
+    action: delete
+  - name: foreign
+    min_hangul_ratio: 0.3
+    min_letters: 10
+    action: mark
+`
+
+func TestRules(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("LoadRules() error = %v", err)
+	}
+	m := New(nil, rules)
+	m.history = newHistory()
+
+	tests := []struct {
+		name    string
+		content string
+		want    Action
+		rules   []string
+	}{
+		{name: "clean korean", content: "좋은 글 감사합니다! 많은 도움이 되었어요.", want: ActionNone},
+		{name: "short english", content: "thanks!", want: ActionNone},
+		{name: "blocklist", content: "최고의 카지노 사이트", want: ActionDelete, rules: []string{"casino"}},
+		{name: "strongest action", content: "cheap VIAGRA pills", want: ActionDelete, rules: []string{"casino", "foreign"}},
+		{name: "links", content: "참고 https://a.example 그리고 https://b.example 입니다", want: ActionMark, rules: []string{"links"}},
+		{name: "foreign script", content: "Buy cheap watches online today best price", want: ActionMark, rules: []string{"foreign"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, ok := m.Evaluate(tistory.Comment{Content: tt.content})
+			if ok != (len(tt.rules) > 0) || d.Action != tt.want || strings.Join(d.Rules, ",") != strings.Join(tt.rules, ",") {
+				t.Errorf("Evaluate(%q) = %v %v %v, want %v %v", tt.content, ok, d.Action, d.Rules, tt.want, tt.rules)
+			}
+		})
+	}
+
+	for _, bad := range []string{
+		"rules:\n  - name: x\n    action: explode\n    repeated: 2\n",
+		"rules:\n  - name: x\n    action: mark\n",
+		"rules:\n  - name: x\n    blocklist: ['(']\n",
+		"rules:\n  - action: mark\n    repeated: 2\n",
+	} {
+		if _, err := LoadRules(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadRules(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestModerator_Poll(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "title"})
+	add := func(name, content string) int {
+		return srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: postId, Name: name, Content: content})
+	}
+	clean := add("reader", "좋은 글 감사합니다")
+	casino := add("spammer", "카지노 바로가기")
+	linky := add("spammer", "여기 https://a.example https://b.example")
+	add(srv.Owner, "https://a.example https://b.example 공지 링크")
+
+	rules, err := LoadRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Dry run logs without changing the blog.
+	var audit bytes.Buffer
+	dry := New(testblog.New(srv), rules)
+	dry.DryRun = true
+	dry.Owner = srv.Owner
+	dry.Audit = &audit
+	decisions, err := dry.Poll()
+	if err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+	if len(decisions) != 2 || len(srv.Comments(tistorytest.DefaultBlog, postId)) != 4 {
+		t.Fatalf("dry run decisions = %+v", decisions)
+	}
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	var logged map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &logged); err != nil || len(lines) != 2 ||
+		logged["dryRun"] != true || logged["action"] != "delete" || logged["applied"] != false {
+		t.Errorf("audit log = %q (%v)", audit.String(), err)
+	}
+
+	m := New(testblog.New(srv), rules)
+	m.Owner = srv.Owner
+	if _, err := m.Poll(); err != nil {
+		t.Fatalf("Poll() error = %v", err)
+	}
+
+	got := map[int]tistorytest.Comment{}
+	for _, c := range srv.Comments(tistorytest.DefaultBlog, postId) {
+		got[c.ID] = c
+	}
+	if _, ok := got[casino]; ok {
+		t.Errorf("blocklisted comment was not deleted")
+	}
+	if c := got[linky]; !c.Secret || !strings.HasPrefix(c.Content, DefaultMarkPrefix) {
+		t.Errorf("linky comment = %+v, want marked", c)
+	}
+	if c := got[clean]; c.Secret || c.Content != "좋은 글 감사합니다" {
+		t.Errorf("clean comment = %+v, want untouched", c)
+	}
+
+	// Seen comments are skipped; a repeat of earlier content is caught.
+	repeat := add("reader2", "좋은 글 감사합니다")
+	decisions, err = m.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 || decisions[0].Comment.Id != repeat || decisions[0].Action != ActionDelete || !decisions[0].Applied {
+		t.Errorf("second poll decisions = %+v", decisions)
+	}
+}
+
+func TestModerator_Run(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+	blog.AccessToken = "expired"
+	var log bytes.Buffer
+	m := New(blog, nil)
+	m.Owner = "owner"
+	m.ErrorLog = &log
+
+	// A zero interval returns the error; a daemon logs it and keeps polling.
+	if err := m.Run(context.Background(), 0); err == nil {
+		t.Errorf("Moderator.Run() once error = nil, want error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Run(ctx, 10*time.Millisecond); err != nil {
+		t.Errorf("Moderator.Run() error = %v", err)
+	}
+	if n := strings.Count(log.String(), "moderate: "); n < 2 {
+		t.Errorf("ErrorLog = %q, want several errors", log.String())
+	}
+}
@@ -0,0 +1,185 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// Action is what the Moderator does with a comment matched by a rule.
+type Action int
+
+const (
+	// ActionNone only records the match in the audit log.
+	ActionNone Action = iota
+	// ActionMark hides the comment (secret) and prefixes its content with Moderator.MarkPrefix.
+	ActionMark
+	// ActionDelete deletes the comment.
+	ActionDelete
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionMark:
+		return "mark"
+	case ActionDelete:
+		return "delete"
+	default:
+		return "none"
+	}
+}
+
+// MarshalText encodes the action by name in audit logs.
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// ParseAction parses "none", "mark" or "delete".
+func ParseAction(s string) (Action, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none", "log":
+		return ActionNone, nil
+	case "mark":
+		return ActionMark, nil
+	case "delete":
+		return ActionDelete, nil
+	default:
+		return ActionNone, errors.Errorf("unknown action %q (want none, mark or delete)", s)
+	}
+}
+
+// Rule matches spam comments.
+type Rule struct {
+	Name   string
+	Action Action
+	// Match reports whether c is spam and why. h holds the comments seen so far.
+	Match func(c tistory.Comment, h *History) (bool, string)
+}
+
+// Blocklist matches comments whose content, name or homepage matches any of
+// the regular expressions.
+func Blocklist(name string, action Action, patterns ...string) (Rule, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return Rule{}, errors.Wrapf(err, "rule %q", name)
+		}
+		regexps = append(regexps, re)
+	}
+	return Rule{
+		Name:   name,
+		Action: action,
+		Match: func(c tistory.Comment, _ *History) (bool, string) {
+			for _, re := range regexps {
+				for _, field := range []string{c.Content, c.Name, c.Homepage} {
+					if m := re.FindString(field); m != "" {
+						return true, fmt.Sprintf("matches %q (%q)", re.String(), m)
+					}
+				}
+			}
+			return false, ""
+		},
+	}, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)[^\s"'<>]+|<a\s`)
+
+// CountLinks returns the number of URLs and <a> tags in s.
+func CountLinks(s string) int {
+	return len(linkPattern.FindAllString(s, -1))
+}
+
+// MaxLinks matches comments with more than max links.
+func MaxLinks(name string, action Action, max int) Rule {
+	return Rule{
+		Name:   name,
+		Action: action,
+		Match: func(c tistory.Comment, _ *History) (bool, string) {
+			if n := CountLinks(c.Content); n > max {
+				return true, fmt.Sprintf("%d links (max %d)", n, max)
+			}
+			return false, ""
+		},
+	}
+}
+
+// Repeated matches a comment once the same content has been seen times times,
+// counting this comment, across posts and polls.
+func Repeated(name string, action Action, times int) Rule {
+	return Rule{
+		Name:   name,
+		Action: action,
+		Match: func(c tistory.Comment, h *History) (bool, string) {
+			if n := h.Count(c.Content); n >= times {
+				return true, fmt.Sprintf("same content seen %d times", n)
+			}
+			return false, ""
+		},
+	}
+}
+
+// HangulRatio returns the share of Hangul among the letters of s and the
+// number of letters.
+func HangulRatio(s string) (float64, int) {
+	letters, hangul := 0, 0
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Hangul, r) {
+			hangul++
+		}
+	}
+	if letters == 0 {
+		return 0, 0
+	}
+	return float64(hangul) / float64(letters), letters
+}
+
+// NonKorean matches comments with at least minLetters letters of which less
+// than minRatio are Hangul. Short comments ("good!", "^^") never match.
+func NonKorean(name string, action Action, minRatio float64, minLetters int) Rule {
+	return Rule{
+		Name:   name,
+		Action: action,
+		Match: func(c tistory.Comment, _ *History) (bool, string) {
+			ratio, letters := HangulRatio(stripLinks(c.Content))
+			if letters >= minLetters && ratio < minRatio {
+				return true, fmt.Sprintf("hangul ratio %.2f (min %.2f)", ratio, minRatio)
+			}
+			return false, ""
+		},
+	}
+}
+
+func stripLinks(s string) string {
+	return linkPattern.ReplaceAllString(s, " ")
+}
+
+// History counts normalized comment contents seen by a Moderator.
+type History struct {
+	counts map[string]int
+}
+
+func newHistory() *History {
+	return &History{counts: map[string]int{}}
+}
+
+func (h *History) add(content string) {
+	h.counts[normalize(content)]++
+}
+
+// Count returns how many times content (ignoring case and whitespace) was seen.
+func (h *History) Count(content string) int {
+	return h.counts[normalize(content)]
+}
+
+func normalize(content string) string {
+	return strings.ToLower(strings.Join(strings.Fields(content), " "))
+}
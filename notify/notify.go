@@ -0,0 +1,245 @@
+// Package notify reports new comments of a Tistory blog to pluggable sinks.
+//
+// A Watcher polls GetRecentCommentList, remembers the newest comment id in a
+// state file and sends the comments written since the last poll to its sinks
+// in batches: a generic JSON webhook, a Slack-compatible incoming webhook,
+// SMTP mail or any io.Writer. Messages are rendered with text/template.
+//
+//	w := notify.New(blog, "notify-state.json",
+//		notify.NewWriterSink(os.Stdout),
+//		notify.NewSlackSink("https://hooks.slack.com/services/..."))
+//	err := w.Run(ctx, time.Minute)
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// Batch is the set of new comments delivered to a sink in one message.
+type Batch struct {
+	Blog     string            `json:"blog"`
+	BlogURL  string            `json:"blogUrl"`
+	Comments []tistory.Comment `json:"comments"`
+}
+
+// Sink delivers batches of new comments.
+type Sink interface {
+	Send(ctx context.Context, b Batch) error
+}
+
+// State is the content of the state file.
+type State struct {
+	LastCommentId int       `json:"lastCommentId"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	// Sinks holds, by index in Watcher.Sinks, the last comment id delivered
+	// to each sink beyond LastCommentId while another sink was failing.
+	Sinks map[int]int `json:"sinks,omitempty"`
+}
+
+// Watcher sends the comments written since its last poll to Sinks.
+type Watcher struct {
+	Blog  *tistory.Tistory
+	Sinks []Sink
+
+	// StatePath is the JSON file holding the last seen comment id ("": in memory only).
+	StatePath string
+	// BatchSize is the maximum number of comments per message (0: one message per poll).
+	BatchSize int
+	// MaxPages limits the comment/newest pages (10 comments each) read per poll.
+	MaxPages int
+	// Backfill sends the comments found by the very first poll. Without it the
+	// first poll only records the newest comment id.
+	Backfill bool
+	// ErrorLog receives the poll errors Run keeps going after (nil: no log).
+	ErrorLog io.Writer
+
+	state  State
+	loaded bool
+}
+
+func New(blog *tistory.Tistory, statePath string, sinks ...Sink) *Watcher {
+	return &Watcher{Blog: blog, Sinks: sinks, StatePath: statePath, MaxPages: 5}
+}
+
+/*
+Run polls every interval until ctx is done. A zero interval polls once and
+returns its error; otherwise a failed poll is written to ErrorLog and retried
+at the next interval.
+*/
+func (w *Watcher) Run(ctx context.Context, interval time.Duration) error {
+	for {
+		_, err := w.Poll(ctx)
+		if interval <= 0 {
+			return err
+		}
+		if err != nil && w.ErrorLog != nil {
+			fmt.Fprintf(w.ErrorLog, "notify: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+/*
+Poll sends the comments newer than the last seen id, oldest first, and
+returns them. The last seen id only moves once every sink accepted every
+batch; until then the state records what each sink received, so the next
+poll retries a failed sink without sending the others the same comments.
+*/
+func (w *Watcher) Poll(ctx context.Context) ([]tistory.Comment, error) {
+	first, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := w.newComments()
+	if err != nil {
+		return nil, err
+	}
+	if len(comments) == 0 {
+		return nil, nil
+	}
+
+	if !first || w.Backfill {
+		if err := w.send(ctx, comments); err != nil {
+			if saveErr := w.save(); saveErr != nil {
+				return nil, saveErr
+			}
+			return nil, err
+		}
+	}
+	w.state.LastCommentId = comments[len(comments)-1].Id
+	w.state.Sinks = nil
+	w.state.UpdatedAt = time.Now()
+	if err := w.save(); err != nil {
+		return comments, err
+	}
+	if first && !w.Backfill {
+		return nil, nil
+	}
+	return comments, nil
+}
+
+// newComments returns the comments newer than the state, oldest first.
+func (w *Watcher) newComments() ([]tistory.Comment, error) {
+	pages := w.MaxPages
+	if pages < 1 {
+		pages = 1
+	}
+
+	var comments []tistory.Comment
+	for page := 1; page <= pages; page++ {
+		result, err := w.Blog.GetRecentCommentList(page, 10)
+		if err != nil {
+			return nil, err
+		}
+		list, err := tistory.ParseComments(result)
+		if err != nil {
+			return nil, err
+		}
+		done := len(list) < 10
+		for _, c := range list {
+			if c.Id <= w.state.LastCommentId {
+				done = true
+				break
+			}
+			comments = append(comments, c)
+		}
+		if done {
+			break
+		}
+	}
+
+	// comment/newest is newest first.
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	return comments, nil
+}
+
+// send delivers comments to each sink, skipping the ones it already
+// received, and returns the first error. A failing sink does not stop the
+// others.
+func (w *Watcher) send(ctx context.Context, comments []tistory.Comment) error {
+	if w.state.Sinks == nil {
+		w.state.Sinks = map[int]int{}
+	}
+	var firstErr error
+	for i, sink := range w.Sinks {
+		pending := comments
+		for len(pending) > 0 && pending[0].Id <= w.state.Sinks[i] {
+			pending = pending[1:]
+		}
+		if err := w.sendTo(ctx, i, sink, pending); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sendTo delivers comments to sink in batches, recording each delivered one.
+func (w *Watcher) sendTo(ctx context.Context, i int, sink Sink, comments []tistory.Comment) error {
+	size := w.BatchSize
+	if size <= 0 {
+		size = len(comments)
+	}
+	for start := 0; start < len(comments); start += size {
+		end := min(start+size, len(comments))
+		b := Batch{Blog: w.Blog.BlogName, BlogURL: w.Blog.BlogURL, Comments: comments[start:end]}
+		if err := sink.Send(ctx, b); err != nil {
+			return err
+		}
+		w.state.Sinks[i] = comments[end-1].Id
+	}
+	return nil
+}
+
+// load reads the state file once and reports whether no state existed yet.
+func (w *Watcher) load() (bool, error) {
+	if w.loaded {
+		return false, nil
+	}
+	w.loaded = true
+	if w.StatePath == "" {
+		return true, nil
+	}
+	data, err := os.ReadFile(w.StatePath)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to read notify state")
+	}
+	if err := json.Unmarshal(data, &w.state); err != nil {
+		return false, errors.Wrapf(err, "Failed to parse notify state (%s)", w.StatePath)
+	}
+	return false, nil
+}
+
+func (w *Watcher) save() error {
+	if w.StatePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(w.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.StatePath), 0o700); err != nil {
+		return errors.Wrap(err, "Failed to save notify state")
+	}
+	if err := os.WriteFile(w.StatePath, append(data, '\n'), 0o600); err != nil {
+		return errors.Wrap(err, "Failed to save notify state")
+	}
+	return nil
+}
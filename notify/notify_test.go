@@ -0,0 +1,205 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+type recordSink struct {
+	batches []Batch
+}
+
+func (s *recordSink) Send(_ context.Context, b Batch) error {
+	s.batches = append(s.batches, b)
+	return nil
+}
+
+func TestWatcher_Poll(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "post", Visibility: 3})
+	addComment := func(content string) int {
+		return srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: postId, Name: "reader", Content: content})
+	}
+	addComment("old")
+
+	state := filepath.Join(t.TempDir(), "state", "notify.json")
+	sink := &recordSink{}
+	w := New(testblog.New(srv), state, sink)
+	w.BatchSize = 2
+	ctx := context.Background()
+
+	// The first poll only records the newest comment.
+	if got, err := w.Poll(ctx); err != nil || len(got) != 0 || len(sink.batches) != 0 {
+		t.Fatalf("Watcher.Poll() first = %v, %v, batches %d", got, err, len(sink.batches))
+	}
+
+	for _, content := range []string{"one", "two", "three"} {
+		addComment(content)
+	}
+	got, err := w.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Watcher.Poll() error = %v", err)
+	}
+	if len(got) != 3 || got[0].Content != "one" || got[2].Content != "three" {
+		t.Errorf("Watcher.Poll() = %+v, want one, two, three", got)
+	}
+	if len(sink.batches) != 2 || len(sink.batches[0].Comments) != 2 || len(sink.batches[1].Comments) != 1 {
+		t.Errorf("Watcher.Poll() batches = %+v, want 2 and 1 comments", sink.batches)
+	}
+	if got, _ := w.Poll(ctx); len(got) != 0 {
+		t.Errorf("Watcher.Poll() repeated = %+v, want none", got)
+	}
+
+	// A new watcher continues from the saved state.
+	last := addComment("four")
+	sink2 := &recordSink{}
+	w2 := New(testblog.New(srv), state, sink2)
+	if got, err := w2.Poll(ctx); err != nil || len(got) != 1 || got[0].Id != last {
+		t.Errorf("Watcher.Poll() after restart = %+v, %v, want comment %d", got, err, last)
+	}
+}
+
+// flakySink fails once it has accepted limit batches while limit >= 0.
+type flakySink struct {
+	recordSink
+	limit int
+}
+
+func (s *flakySink) Send(ctx context.Context, b Batch) error {
+	if s.limit >= 0 && len(s.batches) >= s.limit {
+		return errors.New("sink unavailable")
+	}
+	return s.recordSink.Send(ctx, b)
+}
+
+func TestWatcher_PollFailedSink(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "post", Visibility: 3})
+	state := filepath.Join(t.TempDir(), "notify.json")
+	ok, flaky := &recordSink{}, &flakySink{limit: 1}
+	w := New(testblog.New(srv), state, flaky, ok)
+	w.BatchSize = 2
+	ctx := context.Background()
+	w.Poll(ctx)
+
+	for _, content := range []string{"one", "two", "three"} {
+		srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: postId, Name: "reader", Content: content})
+	}
+	if _, err := w.Poll(ctx); err == nil {
+		t.Fatalf("Watcher.Poll() with failing sink error = nil, want error")
+	}
+	if len(ok.batches) != 2 || len(flaky.batches) != 1 {
+		t.Fatalf("batches = %d and %d, want 2 and 1", len(ok.batches), len(flaky.batches))
+	}
+
+	// A new watcher resumes from the state: only the failed sink gets the rest.
+	flaky.limit = -1
+	w = New(testblog.New(srv), state, flaky, ok)
+	w.BatchSize = 2
+	if got, err := w.Poll(ctx); err != nil || len(got) != 3 {
+		t.Fatalf("Watcher.Poll() retry = %+v, %v", got, err)
+	}
+	if len(ok.batches) != 2 || len(flaky.batches) != 2 || flaky.batches[1].Comments[0].Content != "three" {
+		t.Errorf("retried batches = %+v and %+v", ok.batches, flaky.batches)
+	}
+	if got, err := w.Poll(ctx); err != nil || len(got) != 0 || len(ok.batches) != 2 || len(flaky.batches) != 2 {
+		t.Errorf("Watcher.Poll() after retry = %+v, %v", got, err)
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+	blog.AccessToken = "expired"
+	var log bytes.Buffer
+	w := New(blog, "", &recordSink{})
+	w.ErrorLog = &log
+
+	// A zero interval returns the error; a daemon logs it and keeps polling.
+	if err := w.Run(context.Background(), 0); err == nil {
+		t.Errorf("Watcher.Run() once error = nil, want error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := w.Run(ctx, 10*time.Millisecond); err != nil {
+		t.Errorf("Watcher.Run() error = %v", err)
+	}
+	if n := strings.Count(log.String(), "notify: "); n < 2 {
+		t.Errorf("ErrorLog = %q, want several errors", log.String())
+	}
+}
+
+func TestSinks(t *testing.T) {
+	b := Batch{Blog: "test", Comments: []tistory.Comment{{Id: 1, PostId: 2, Name: "reader", Content: "좋은 글\n감사합니다"}}}
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	if err := NewWriterSink(&out).Send(ctx, b); err != nil {
+		t.Fatalf("WriterSink.Send() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "[test] 새 댓글 1개") || !strings.Contains(out.String(), "reader (post 2, ") || !strings.Contains(out.String(), "좋은 글 감사합니다") {
+		t.Errorf("WriterSink.Send() = %q", out.String())
+	}
+
+	var bodies []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	if err := NewWebhookSink(srv.URL+"/hook").Send(ctx, b); err != nil {
+		t.Fatalf("WebhookSink.Send() error = %v", err)
+	}
+	if bodies[0]["blog"] != "test" || len(bodies[0]["comments"].([]interface{})) != 1 {
+		t.Errorf("WebhookSink.Send() body = %v", bodies[0])
+	}
+	tmpl, err := ParseTemplate("{{range .Comments}}{{.Name}}: {{oneline .Content}}{{end}}")
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	slack := NewSlackSink(srv.URL + "/slack")
+	slack.Template = tmpl
+	if err := slack.Send(ctx, b); err != nil {
+		t.Fatalf("SlackSink.Send() error = %v", err)
+	}
+	if bodies[1]["text"] != "reader: 좋은 글 감사합니다" {
+		t.Errorf("SlackSink.Send() body = %v", bodies[1])
+	}
+	if err := NewWebhookSink(srv.URL+"/fail").Send(ctx, b); err == nil {
+		t.Errorf("WebhookSink.Send() error = nil, want 500 error")
+	}
+
+	var sent []byte
+	mail := NewMailSink("smtp.example.com:587", "bot@example.com", "me@example.com")
+	mail.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		sent = msg
+		return nil
+	}
+	if err := mail.Send(ctx, b); err != nil {
+		t.Fatalf("MailSink.Send() error = %v", err)
+	}
+	if !bytes.Contains(sent, []byte("Subject: =?UTF-8?b?")) || !bytes.Contains(sent, []byte("To: me@example.com\r\n")) || !bytes.Contains(sent, []byte("좋은 글 감사합니다")) {
+		t.Errorf("MailSink.Send() message = %q", sent)
+	}
+}
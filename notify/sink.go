@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTemplate renders a batch as a short plain text message.
+const DefaultTemplate = `[{{.Blog}}] 새 댓글 {{len .Comments}}개
+{{range .Comments}}- {{.Name}} (post {{.PostId}}, {{date .Date}}): {{oneline .Content | truncate 200}}
+{{if .Link}}  {{.Link}}
+{{end}}{{end}}`
+
+// DefaultSubject is the mail subject template of a MailSink.
+const DefaultSubject = `[{{.Blog}}] 새 댓글 {{len .Comments}}개`
+
+var templateFuncs = template.FuncMap{
+	"oneline": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+	"truncate": func(n int, s string) string {
+		r := []rune(s)
+		if len(r) <= n {
+			return s
+		}
+		return string(r[:n]) + "…"
+	},
+	"date": func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+}
+
+/*
+ParseTemplate parses a message template executed with a Batch.
+An empty text returns DefaultTemplate. Besides the text/template builtins,
+oneline (collapse whitespace), truncate N and date are available.
+*/
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	tmpl, err := template.New("notify").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse notify template")
+	}
+	return tmpl, nil
+}
+
+func mustTemplate(text string) *template.Template {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
+}
+
+// Render executes tmpl (nil: DefaultTemplate) with b.
+func (b Batch) Render(tmpl *template.Template) (string, error) {
+	if tmpl == nil {
+		tmpl = mustTemplate(DefaultTemplate)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, b); err != nil {
+		return "", errors.Wrap(err, "Failed to render notify template")
+	}
+	return buf.String(), nil
+}
+
+// WriterSink writes rendered messages to W, e.g. os.Stdout.
+type WriterSink struct {
+	W        io.Writer
+	Template *template.Template
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{W: w}
+}
+
+func (s *WriterSink) Send(_ context.Context, b Batch) error {
+	text, err := b.Render(s.Template)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(s.W, text)
+	return err
+}
+
+// WebhookSink POSTs each Batch as JSON to URL.
+type WebhookSink struct {
+	URL        string
+	Header     http.Header
+	HTTPClient *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url}
+}
+
+func (s *WebhookSink) Send(ctx context.Context, b Batch) error {
+	return postJSON(ctx, s.HTTPClient, s.URL, s.Header, b)
+}
+
+// SlackSink posts rendered messages to a Slack-compatible incoming webhook
+// ({"text": "..."}), which Mattermost, Discord (/slack) and others accept.
+type SlackSink struct {
+	URL        string
+	Template   *template.Template
+	HTTPClient *http.Client
+}
+
+func NewSlackSink(url string) *SlackSink {
+	return &SlackSink{URL: url}
+}
+
+func (s *SlackSink) Send(ctx context.Context, b Batch) error {
+	text, err := b.Render(s.Template)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, s.HTTPClient, s.URL, nil, map[string]string{"text": text})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "Failed to create webhook request")
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Failed to send webhook")
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("Failed to send webhook (%s: %s)", url, resp.Status)
+	}
+	return nil
+}
+
+// MailSink sends rendered messages by SMTP.
+type MailSink struct {
+	// Addr is the SMTP server as host:port.
+	Addr string
+	// Auth is used when the server supports it (nil: no authentication).
+	Auth     smtp.Auth
+	From     string
+	To       []string
+	Subject  *template.Template
+	Template *template.Template
+
+	// sendMail is smtp.SendMail, replaced in tests.
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewMailSink(addr, from string, to ...string) *MailSink {
+	return &MailSink{Addr: addr, From: from, To: to}
+}
+
+func (s *MailSink) Send(_ context.Context, b Batch) error {
+	subjectTmpl := s.Subject
+	if subjectTmpl == nil {
+		subjectTmpl = mustTemplate(DefaultSubject)
+	}
+	subject, err := b.Render(subjectTmpl)
+	if err != nil {
+		return err
+	}
+	text, err := b.Render(s.Template)
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", strings.TrimSpace(subject)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n"))
+
+	send := s.sendMail
+	if send == nil {
+		send = smtp.SendMail
+	}
+	if err := send(s.Addr, s.Auth, s.From, s.To, msg.Bytes()); err != nil {
+		return errors.Wrap(err, "Failed to send mail")
+	}
+	return nil
+}
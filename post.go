@@ -0,0 +1,222 @@
+package tistory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// kst is the time zone of the dates returned by the Tistory API.
+var kst = time.FixedZone("KST", 9*60*60)
+
+const dateLayout = "2006-01-02 15:04:05"
+
+// PostSummary is one entry of post/list.
+type PostSummary struct {
+	Id         int
+	Title      string
+	PostURL    string
+	Visibility string // 0: 비공개, 15: 보호, 20: 발행
+	CategoryId int
+	Comments   int
+	Date       time.Time
+}
+
+// ParsePostList converts a post/list response into PostSummary values and
+// reports the total number of posts of the blog.
+func ParsePostList(result map[string]interface{}) ([]PostSummary, int, error) {
+	item, err := responseItem(result)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Failed to parse post/list")
+	}
+	list, _ := item["posts"].([]interface{})
+
+	posts := make([]PostSummary, 0, len(list))
+	for _, entry := range list {
+		p, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		posts = append(posts, PostSummary{
+			Id:         intField(p, "id"),
+			Title:      stringField(p, "title"),
+			PostURL:    stringField(p, "postUrl"),
+			Visibility: stringField(p, "visibility"),
+			CategoryId: intField(p, "categoryId"),
+			Comments:   intField(p, "comments"),
+			Date:       parseDate(stringField(p, "date")),
+		})
+	}
+	return posts, intField(item, "totalCount"), nil
+}
+
+/*
+GetAllPosts 전체 글 목록
+GetPostList 를 마지막 페이지까지 호출합니다.
+*/
+func (t *Tistory) GetAllPosts() ([]PostSummary, error) {
+	var all []PostSummary
+	for page := 1; ; page++ {
+		result, err := t.GetPostList(page)
+		if err != nil {
+			return nil, err
+		}
+		posts, total, err := ParsePostList(result)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, posts...)
+		if len(posts) == 0 || len(all) >= total {
+			return all, nil
+		}
+	}
+}
+
+// Post is a post returned by post/read.
+type Post struct {
+	Id            int
+	Title         string
+	Content       string
+	PostURL       string
+	Visibility    string // 0: 비공개, 15: 보호, 20: 발행
+	CategoryId    int
+	Tags          []string
+	AcceptComment bool
+	Comments      int
+	Date          time.Time
+}
+
+// ParsePost converts a post/read response into a Post.
+func ParsePost(result map[string]interface{}) (Post, error) {
+	item, err := responseItem(result)
+	if err != nil {
+		return Post{}, errors.Wrap(err, "Failed to parse post/read")
+	}
+
+	var tags []string
+	if wrapper, ok := item["tags"].(map[string]interface{}); ok {
+		switch tag := wrapper["tag"].(type) {
+		case []interface{}:
+			for _, name := range tag {
+				tags = append(tags, fmt.Sprintf("%v", name))
+			}
+		case string:
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return Post{
+		Id:            intField(item, "id"),
+		Title:         stringField(item, "title"),
+		Content:       stringField(item, "content"),
+		PostURL:       stringField(item, "postUrl"),
+		Visibility:    stringField(item, "visibility"),
+		CategoryId:    intField(item, "categoryId"),
+		Tags:          tags,
+		AcceptComment: stringField(item, "acceptComment") != "0",
+		Comments:      intField(item, "comments"),
+		Date:          parseDate(stringField(item, "date")),
+	}, nil
+}
+
+/*
+ReadPost 글 읽기
+GetPost 결과를 Post 로 반환합니다.
+*/
+func (t *Tistory) ReadPost(postId int) (Post, error) {
+	result, err := t.GetPost(postId)
+	if err != nil {
+		return Post{}, err
+	}
+	return ParsePost(result)
+}
+
+/*
+ModifyOption returns the post/modify parameters that keep p unchanged.
+The publish time is always sent: post/modify without it dates the post now,
+which would publish a reserved post immediately and move an old post to today.
+*/
+func (p Post) ModifyOption() map[string]interface{} {
+	acceptComment := 0
+	if p.AcceptComment {
+		acceptComment = 1
+	}
+	option := map[string]interface{}{
+		"postId":        p.Id,
+		"title":         p.Title,
+		"content":       p.Content,
+		"category":      p.CategoryId,
+		"visibility":    WriteVisibility(p.Visibility),
+		"tag":           strings.Join(p.Tags, ","),
+		"acceptComment": acceptComment,
+	}
+	if !p.Date.IsZero() {
+		option["published"] = p.Date.Unix()
+	}
+	return option
+}
+
+// WriteVisibility maps the visibility returned by post/read and post/list
+// (0, 15, 20) to the value accepted by post/write and post/modify (0, 1, 3).
+func WriteVisibility(v string) int {
+	switch v {
+	case "15", "1":
+		return 1
+	case "20", "3":
+		return 3
+	default:
+		return 0
+	}
+}
+
+/*
+EditPost 글 부분 수정
+현재 글을 읽어 changes 에 없는 값은 유지한 채 ModifyPost 를 호출합니다.
+*/
+func (t *Tistory) EditPost(postId int, changes map[string]interface{}) (map[string]interface{}, error) {
+	post, err := t.ReadPost(postId)
+	if err != nil {
+		return nil, err
+	}
+	option := post.ModifyOption()
+	for key, value := range changes {
+		option[key] = value
+	}
+	option["postId"] = postId
+	return t.modifyPost(option, &post)
+}
+
+// responseItem returns result["tistory"]["item"].
+func responseItem(result map[string]interface{}) (map[string]interface{}, error) {
+	body, ok := result["tistory"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("missing tistory object")
+	}
+	item, ok := body["item"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("missing tistory.item object")
+	}
+	return item, nil
+}
+
+// intField returns m[key] as an int; missing or malformed values are 0.
+func intField(m map[string]interface{}, key string) int {
+	n, _ := strconv.Atoi(stringField(m, key))
+	return n
+}
+
+// parseDate parses "2006-01-02 15:04:05" (KST) or a unix timestamp.
+func parseDate(s string) time.Time {
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(ts, 0).In(kst)
+	}
+	if tm, err := time.ParseInLocation(dateLayout, s, kst); err == nil {
+		return tm
+	}
+	return time.Time{}
+}
@@ -0,0 +1,52 @@
+package tistory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestTistory_EditPost(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	reserved := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{
+		Title: "title", Content: "<p>content</p>", Visibility: 3, Tags: []string{"go", "api"},
+		AcceptComment: true, Published: reserved})
+
+	post, err := tst.ReadPost(postId)
+	if err != nil {
+		t.Fatalf("Tistory.ReadPost() error = %v", err)
+	}
+	if post.Title != "title" || post.Visibility != "20" || !reflect.DeepEqual(post.Tags, []string{"go", "api"}) ||
+		!post.AcceptComment || !post.Date.Equal(reserved) {
+		t.Errorf("Tistory.ReadPost() = %+v", post)
+	}
+
+	if _, err := tst.EditPost(postId, map[string]interface{}{"title": "new title"}); err != nil {
+		t.Fatalf("Tistory.EditPost() error = %v", err)
+	}
+	got, _ := srv.Post(tistorytest.DefaultBlog, postId)
+	if got.Title != "new title" || got.Content != "<p>content</p>" || got.Visibility != 3 ||
+		!reflect.DeepEqual(got.Tags, []string{"go", "api"}) || !got.Published.Equal(reserved) {
+		t.Errorf("Tistory.EditPost() stored = %+v", got)
+	}
+
+	// Editing an old post keeps its date.
+	past := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	oldId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "old", Visibility: 3, Tags: []string{"go"}, Published: past})
+	if _, err := tst.EditPost(oldId, map[string]interface{}{"tag": "golang"}); err != nil {
+		t.Fatalf("Tistory.EditPost() error = %v", err)
+	}
+	if got, _ := srv.Post(tistorytest.DefaultBlog, oldId); !got.Published.Equal(past) || !reflect.DeepEqual(got.Tags, []string{"golang"}) {
+		t.Errorf("Tistory.EditPost() on past post stored published = %v, tags = %v, want %v", got.Published, got.Tags, past)
+	}
+
+	if _, err := tst.EditPost(999, map[string]interface{}{"title": "x"}); err == nil {
+		t.Errorf("Tistory.EditPost() on missing post error = nil, want error")
+	}
+}
@@ -0,0 +1,92 @@
+package tistory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/LimJiAn/tistory-go/internal/diff"
+)
+
+// FieldChange is a changed post field.
+type FieldChange struct {
+	Field  string // title, tags, category or visibility
+	Remote string
+	Local  string
+}
+
+// PostDiff is the difference between a post on the blog and a local version.
+type PostDiff struct {
+	PostId int
+	Fields []FieldChange
+	// Content is the unified diff of the normalized HTML bodies ("" if equal).
+	Content string
+}
+
+// Changed reports whether anything differs.
+func (d *PostDiff) Changed() bool {
+	return len(d.Fields) > 0 || d.Content != ""
+}
+
+// Unified renders the field changes and the content diff as text.
+func (d *PostDiff) Unified() string {
+	var b strings.Builder
+	for _, f := range d.Fields {
+		fmt.Fprintf(&b, "%s:\n- %s\n+ %s\n", f.Field, f.Remote, f.Local)
+	}
+	b.WriteString(d.Content)
+	return b.String()
+}
+
+/*
+DiffPost 글 비교
+GetPost 로 읽은 글의 본문과 localContent 를 content.Normalize 로 정규화한 뒤
+diff 합니다. 제목, 태그, 카테고리, 공개 설정까지 비교하려면 읽은 글과 local Post 로
+DiffPosts 를 사용합니다.
+*/
+func (t *Tistory) DiffPost(ctx context.Context, postId int, localContent string) (*PostDiff, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	remote, err := t.ReadPost(postId)
+	if err != nil {
+		return nil, err
+	}
+	local := remote
+	local.Content = localContent
+	return DiffPosts(remote, local), nil
+}
+
+/*
+DiffPosts compares two versions of a post: the title, the tags (in any
+order), the category, the visibility and the normalized content.
+local.Visibility accepts both the read (0, 15, 20) and write (0, 1, 3) values.
+*/
+func DiffPosts(remote, local Post) *PostDiff {
+	d := &PostDiff{PostId: remote.Id}
+	change := func(field, a, b string) {
+		if a != b {
+			d.Fields = append(d.Fields, FieldChange{Field: field, Remote: a, Local: b})
+		}
+	}
+	change("title", remote.Title, local.Title)
+	change("tags", sortedTags(remote.Tags), sortedTags(local.Tags))
+	change("category", fmt.Sprint(remote.CategoryId), fmt.Sprint(local.CategoryId))
+	change("visibility", fmt.Sprint(WriteVisibility(remote.Visibility)), fmt.Sprint(WriteVisibility(local.Visibility)))
+
+	d.Content = diff.Unified("remote", "local", content.Normalize(remote.Content), content.Normalize(local.Content))
+	return d
+}
+
+func sortedTags(tags []string) string {
+	sorted := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			sorted = append(sorted, tag)
+		}
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
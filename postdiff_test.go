@@ -0,0 +1,61 @@
+package tistory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestTistory_DiffPost(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{
+		Title: "title", Visibility: 3, Tags: []string{"go", "api"},
+		Content: `<p class="a" id="b">first</p><p>[##_Image|kage@key/img.png|CDM|1.3|{}_##]</p><p>last</p>`})
+
+	same := Post{Title: "title", Visibility: "3", Tags: []string{"api", "go"},
+		Content: "<p id=\"b\" class=\"a\">\n  first\n</p>\n<p><img src=\"https://blog.kakaocdn.net/dn/key/img.png\"></p>\n<p>last</p>\n"}
+	d, err := tst.DiffPost(context.Background(), postId, same.Content)
+	if err != nil {
+		t.Fatalf("Tistory.DiffPost() error = %v", err)
+	}
+	if d.Changed() {
+		t.Errorf("Tistory.DiffPost() of an equivalent body =\n%s", d.Unified())
+	}
+	remote, err := tst.ReadPost(postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := DiffPosts(remote, same); d.Changed() {
+		t.Errorf("DiffPosts() of an equivalent post =\n%s", d.Unified())
+	}
+
+	changed := same
+	changed.Title = "new title"
+	changed.Visibility = "0"
+	changed.Content = strings.Replace(same.Content, "last", "changed", 1)
+	d, err = tst.DiffPost(context.Background(), postId, changed.Content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Fields) != 0 || !strings.Contains(d.Content, "-<p>last</p>\n+<p>changed</p>\n") {
+		t.Errorf("Tistory.DiffPost() = %+v, content =\n%s", d.Fields, d.Content)
+	}
+	d = DiffPosts(remote, changed)
+	if len(d.Fields) != 2 || d.Fields[0].Field != "title" || d.Fields[1] != (FieldChange{Field: "visibility", Remote: "3", Local: "0"}) {
+		t.Errorf("DiffPosts() fields = %+v", d.Fields)
+	}
+	if u := d.Unified(); !strings.HasPrefix(u, "title:\n- title\n+ new title\n") || !strings.Contains(u, "+<p>changed</p>\n") {
+		t.Errorf("PostDiff.Unified() =\n%s", u)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tst.DiffPost(ctx, postId, same.Content); err == nil {
+		t.Errorf("Tistory.DiffPost() with canceled context error = nil, want error")
+	}
+}
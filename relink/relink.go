@@ -0,0 +1,189 @@
+// Package relink rewrites the internal links of posts after a blog moves to
+// another domain or switches to slogan (title) URLs.
+//
+//	posts, err := relink.PostURLs(blog) // post id → URL of post/list
+//	r := &relink.Rewriter{Domains: map[string]string{"myblog.tistory.com": "blog.example.com"}, Posts: posts}
+//	options := &tistory.BulkOptions{DryRun: true}
+//	results, err := blog.BulkModify(ctx, nil, r.Mutator(func(c relink.Change) {
+//		fmt.Print(c.Diff)
+//	}), options)
+package relink
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/LimJiAn/tistory-go/internal/diff"
+)
+
+// postPath matches the numeric URL of a post, /123 or the mobile /m/123.
+var postPath = regexp.MustCompile(`^/(?:m/)?(\d+)/?$`)
+
+// Rewriter rewrites links to the blog. A link is internal when its host is
+// a key or value of Domains, the host of a Posts URL or one of Hosts;
+// relative links are internal too.
+type Rewriter struct {
+	// Domains maps old hosts to new ones, e.g. "myblog.tistory.com" to
+	// "blog.example.com". The path, query and fragment are kept.
+	Domains map[string]string
+	// Posts maps post ids to their URLs; internal links to /123 or /m/123
+	// become Posts[123], keeping the fragment.
+	Posts map[int]string
+	// Hosts are more hosts of the blog whose links are internal.
+	Hosts []string
+
+	once     sync.Once
+	domains  map[string]string
+	internal map[string]bool
+}
+
+/*
+PostURLs returns the URL of every post from post/list, which is the slogan
+URL (/entry/...) when the blog uses them.
+*/
+func PostURLs(blog *tistory.Tistory) (map[int]string, error) {
+	posts, err := blog.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+	urls := make(map[int]string, len(posts))
+	for _, p := range posts {
+		if p.PostURL != "" {
+			urls[p.Id] = p.PostURL
+		}
+	}
+	return urls, nil
+}
+
+// init lowercases the hosts once; hosts are case insensitive.
+func (r *Rewriter) init() {
+	r.once.Do(func() {
+		r.domains = map[string]string{}
+		r.internal = map[string]bool{}
+		for from, to := range r.Domains {
+			r.domains[strings.ToLower(from)] = to
+			r.internal[strings.ToLower(from)] = true
+			r.internal[strings.ToLower(to)] = true
+		}
+		for _, u := range r.Posts {
+			if parsed, err := url.Parse(u); err == nil {
+				r.internal[strings.ToLower(parsed.Host)] = true
+			}
+		}
+		for _, h := range r.Hosts {
+			r.internal[strings.ToLower(h)] = true
+		}
+	})
+}
+
+func (r *Rewriter) isInternal(host string) bool {
+	r.init()
+	return host == "" || r.internal[strings.ToLower(host)]
+}
+
+// RewriteURL returns the new URL of an internal link, or u unchanged.
+func (r *Rewriter) RewriteURL(u string) string {
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil || parsed.Scheme != "" && parsed.Scheme != "http" && parsed.Scheme != "https" ||
+		parsed.Host == "" && parsed.Path == "" || !r.isInternal(parsed.Host) {
+		return u
+	}
+
+	if m := postPath.FindStringSubmatch(parsed.Path); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		if target, ok := r.Posts[id]; ok {
+			if t, err := url.Parse(target); err == nil {
+				t.Host = r.newHost(t.Host)
+				if t.Fragment == "" {
+					t.Fragment, t.RawFragment = parsed.Fragment, parsed.RawFragment
+				}
+				if parsed.Host == "" {
+					// Keep relative links relative.
+					t.Scheme, t.Host = "", ""
+				}
+				return sameURL(u, t.String())
+			}
+		}
+	}
+	if parsed.Host != "" {
+		parsed.Host = r.newHost(parsed.Host)
+	}
+	return sameURL(u, parsed.String())
+}
+
+func (r *Rewriter) newHost(host string) string {
+	r.init()
+	if to, ok := r.domains[strings.ToLower(host)]; ok {
+		return to
+	}
+	return host
+}
+
+// sameURL returns u when rewritten only differs from it by encoding.
+func sameURL(u, rewritten string) string {
+	if parsed, err := url.Parse(strings.TrimSpace(u)); err == nil && parsed.String() == rewritten {
+		return u
+	}
+	return rewritten
+}
+
+// LinkChange is a rewritten link.
+type LinkChange struct {
+	From string
+	To   string
+}
+
+// Change is the rewrite of one post.
+type Change struct {
+	PostId int
+	Title  string
+	Links  []LinkChange
+	// Diff is the unified diff of the normalized content (content.Normalize).
+	Diff string
+}
+
+/*
+Rewrite rewrites the internal links and images of an HTML body and
+returns the changed links. The rest of body is kept byte for byte.
+*/
+func (r *Rewriter) Rewrite(body string) (string, []LinkChange) {
+	var changes []LinkChange
+	text, _ := content.RewriteURLs(body, func(tag, u string) string {
+		to := r.RewriteURL(u)
+		if to != u {
+			changes = append(changes, LinkChange{From: u, To: to})
+		}
+		return to
+	})
+	return text, changes
+}
+
+/*
+Mutator returns a BulkModify mutator rewriting the links of each post.
+report, if not nil, receives each changed post with its diff, one call at
+a time; with BulkOptions.DryRun it shows what would be saved.
+*/
+func (r *Rewriter) Mutator(report func(Change)) tistory.PostMutator {
+	var mu sync.Mutex
+	return func(p *tistory.Post) (bool, error) {
+		text, links := r.Rewrite(p.Content)
+		if len(links) == 0 {
+			return false, nil
+		}
+		if report != nil {
+			change := Change{PostId: p.Id, Title: p.Title, Links: links,
+				Diff: diff.Unified(fmt.Sprintf("post %d", p.Id), "rewritten", content.Normalize(p.Content), content.Normalize(text))}
+			mu.Lock()
+			report(change)
+			mu.Unlock()
+		}
+		p.Content = text
+		return true, nil
+	}
+}
@@ -0,0 +1,78 @@
+package relink
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestRewriteURL(t *testing.T) {
+	r := &Rewriter{
+		Domains: map[string]string{"myblog.tistory.com": "blog.example.com"},
+		Posts:   map[int]string{123: "https://blog.example.com/entry/Go-시작하기", 7: "https://myblog.tistory.com/entry/old"},
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "https://myblog.tistory.com/123", want: "https://blog.example.com/entry/Go-%EC%8B%9C%EC%9E%91%ED%95%98%EA%B8%B0"},
+		{in: "http://MyBlog.tistory.com/m/123#comments", want: "https://blog.example.com/entry/Go-%EC%8B%9C%EC%9E%91%ED%95%98%EA%B8%B0#comments"},
+		{in: "https://blog.example.com/7", want: "https://blog.example.com/entry/old"},
+		{in: "/123", want: "/entry/Go-%EC%8B%9C%EC%9E%91%ED%95%98%EA%B8%B0"},
+		{in: "https://myblog.tistory.com/category/Go?page=2", want: "https://blog.example.com/category/Go?page=2"},
+		{in: "https://myblog.tistory.com/999", want: "https://blog.example.com/999"},
+		{in: "https://other.tistory.com/123", want: "https://other.tistory.com/123"},
+		{in: "#top", want: "#top"},
+		{in: "mailto:a@myblog.tistory.com", want: "mailto:a@myblog.tistory.com"},
+		{in: "https://blog.example.com/entry/Go", want: "https://blog.example.com/entry/Go"},
+	}
+	for _, tt := range tests {
+		if got := r.RewriteURL(tt.in); got != tt.want {
+			t.Errorf("Rewriter.RewriteURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMutator(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+
+	target := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "target", Slogan: "target-slug", Content: "<p>t</p>"})
+	body := `<p data-ke-size="size16">See <a href="https://myblog.tistory.com/` + strconv.Itoa(target) + `">target</a> ` +
+		`and <a href="https://example.com/x">outside</a>.</p><p>[##_Image|kage@k/img.png|CDM|1.3|{}_##]</p>`
+	linking := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "linking", Content: body})
+
+	posts, err := PostURLs(blog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Rewriter{Domains: map[string]string{"myblog.tistory.com": "blog.example.com"}, Posts: posts}
+	var changes []Change
+	mutator := r.Mutator(func(c Change) { changes = append(changes, c) })
+
+	results, err := blog.BulkModify(context.Background(), nil, mutator, &tistory.BulkOptions{DryRun: true})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("BulkModify(DryRun) = %+v, %v", results, err)
+	}
+	if post, _ := srv.Post(tistorytest.DefaultBlog, linking); post.Content != body {
+		t.Errorf("dry run changed the post: %q", post.Content)
+	}
+	if len(changes) != 1 || changes[0].PostId != linking || len(changes[0].Links) != 1 ||
+		changes[0].Links[0].To != posts[target] || !strings.Contains(changes[0].Diff, `+<p data-ke-size="size16">See <a href="`+posts[target]+`">`) {
+		t.Fatalf("changes = %+v", changes)
+	}
+
+	if _, err := blog.BulkModify(context.Background(), nil, r.Mutator(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(body, "https://myblog.tistory.com/"+strconv.Itoa(target), posts[target], 1)
+	if post, _ := srv.Post(tistorytest.DefaultBlog, linking); post.Content != want {
+		t.Errorf("rewritten content = %q, want %q", post.Content, want)
+	}
+}
@@ -0,0 +1,228 @@
+package tistory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/diff"
+	"github.com/pkg/errors"
+)
+
+// Revision is a snapshot of a post taken before it was modified.
+type Revision struct {
+	PostId int
+	Number int // 1 for the oldest snapshot of the post
+	Time   time.Time
+	Post   Post
+}
+
+// RevisionStore keeps post snapshots. When Tistory.Revisions is set,
+// ModifyPost saves the current post to it before every modification.
+type RevisionStore interface {
+	// SaveRevision stores r and returns it with its Number assigned.
+	SaveRevision(r Revision) (Revision, error)
+	// Revisions returns the revisions of a post, oldest first.
+	Revisions(postId int) ([]Revision, error)
+	// Revision returns one revision of a post.
+	Revision(postId, number int) (Revision, error)
+}
+
+// FileRevisionStore stores each revision as Dir/<postId>/<number>.json.
+type FileRevisionStore struct {
+	Dir string
+}
+
+func NewFileRevisionStore(dir string) *FileRevisionStore {
+	return &FileRevisionStore{Dir: dir}
+}
+
+/*
+SaveRevision writes r under the number after the last revision of the post.
+The file is created with O_EXCL, so a concurrent save that took the number
+first makes it try the next one instead of overwriting it.
+*/
+func (s *FileRevisionStore) SaveRevision(r Revision) (Revision, error) {
+	numbers, err := s.numbers(r.PostId)
+	if err != nil {
+		return Revision{}, err
+	}
+	r.Number = 1
+	if len(numbers) > 0 {
+		r.Number = numbers[len(numbers)-1] + 1
+	}
+
+	dir := filepath.Join(s.Dir, strconv.Itoa(r.PostId))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return Revision{}, errors.Wrap(err, "Failed to save revision")
+	}
+	for {
+		f, err := os.OpenFile(filepath.Join(dir, strconv.Itoa(r.Number)+".json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if os.IsExist(err) {
+			r.Number++
+			continue
+		}
+		if err != nil {
+			return Revision{}, errors.Wrap(err, "Failed to save revision")
+		}
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err == nil {
+			_, err = f.Write(append(data, '\n'))
+		}
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return Revision{}, errors.Wrap(err, "Failed to save revision")
+		}
+		return r, nil
+	}
+}
+
+func (s *FileRevisionStore) Revisions(postId int) ([]Revision, error) {
+	numbers, err := s.numbers(postId)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []Revision
+	for _, number := range numbers {
+		r, err := s.Revision(postId, number)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, nil
+}
+
+// numbers returns the revision numbers of a post in order, from the file
+// names alone.
+func (s *FileRevisionStore) numbers(postId int) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, strconv.Itoa(postId)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read revisions")
+	}
+
+	var numbers []int
+	for _, entry := range entries {
+		number, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+func (s *FileRevisionStore) Revision(postId, number int) (Revision, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, strconv.Itoa(postId), strconv.Itoa(number)+".json"))
+	if os.IsNotExist(err) {
+		return Revision{}, errors.Errorf("revision %d of post %d not found", number, postId)
+	}
+	if err != nil {
+		return Revision{}, errors.Wrap(err, "Failed to read revision")
+	}
+	var r Revision
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Revision{}, errors.Wrapf(err, "Failed to parse revision %d of post %d", number, postId)
+	}
+	return r, nil
+}
+
+// snapshot returns the current state of the post modified by option, which
+// is read unless before is given. modifyPost saves it once the modification
+// succeeds.
+func (t *Tistory) snapshot(option map[string]interface{}, before *Post) (Revision, error) {
+	postId, err := strconv.Atoi(fmt.Sprintf("%v", option["postId"]))
+	if err != nil {
+		return Revision{}, errors.Errorf("Failed to ModifyPost (invalid postId %v)", option["postId"])
+	}
+	var post Post
+	if before != nil && before.Id == postId {
+		post = *before
+	} else if post, err = t.ReadPost(postId); err != nil {
+		return Revision{}, errors.Wrap(err, "Failed to snapshot post")
+	}
+	return Revision{PostId: postId, Time: time.Now(), Post: post}, nil
+}
+
+/*
+PostRevisions 글 수정 기록
+Revisions 에 저장된 글의 revision 목록 (오래된 순)
+*/
+func (t *Tistory) PostRevisions(postId int) ([]Revision, error) {
+	if t.Revisions == nil {
+		return nil, errors.New("Failed to PostRevisions (Revisions is not set)")
+	}
+	return t.Revisions.Revisions(postId)
+}
+
+/*
+DiffRevisions 두 revision 의 unified diff
+to 가 0 이면 현재 글과 비교합니다.
+*/
+func (t *Tistory) DiffRevisions(postId, from, to int) (string, error) {
+	if t.Revisions == nil {
+		return "", errors.New("Failed to DiffRevisions (Revisions is not set)")
+	}
+	a, err := t.Revisions.Revision(postId, from)
+	if err != nil {
+		return "", err
+	}
+	toName := "current"
+	var b Post
+	if to == 0 {
+		if b, err = t.ReadPost(postId); err != nil {
+			return "", err
+		}
+	} else {
+		r, err := t.Revisions.Revision(postId, to)
+		if err != nil {
+			return "", err
+		}
+		b, toName = r.Post, fmt.Sprintf("revision %d", to)
+	}
+	return diff.Unified(fmt.Sprintf("revision %d", from), toName, a.Post.Text(), b.Text()), nil
+}
+
+/*
+RestoreRevision 글 되돌리기
+revision 의 글로 ModifyPost 합니다. 현재 글도 새 revision 으로 저장되므로 되돌리기를 다시 되돌릴 수 있습니다.
+*/
+func (t *Tistory) RestoreRevision(postId, number int) (map[string]interface{}, error) {
+	if t.Revisions == nil {
+		return nil, errors.New("Failed to RestoreRevision (Revisions is not set)")
+	}
+	r, err := t.Revisions.Revision(postId, number)
+	if err != nil {
+		return nil, err
+	}
+	option := r.Post.ModifyOption()
+	option["postId"] = postId
+	return t.ModifyPost(option)
+}
+
+// Text renders the post's fields and content for diffs.
+func (p Post) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "title: %s\n", p.Title)
+	fmt.Fprintf(&b, "category: %d\n", p.CategoryId)
+	fmt.Fprintf(&b, "visibility: %s\n", p.Visibility)
+	fmt.Fprintf(&b, "tags: %s\n", strings.Join(p.Tags, ", "))
+	fmt.Fprintf(&b, "acceptComment: %t\n", p.AcceptComment)
+	b.WriteString("\n")
+	b.WriteString(p.Content)
+	if p.Content != "" && !strings.HasSuffix(p.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
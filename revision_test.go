@@ -0,0 +1,130 @@
+package tistory
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestTistory_Revisions(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+	tst.Revisions = NewFileRevisionStore(t.TempDir())
+
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{
+		Title: "v1", Content: "<p>one</p>\n<p>two</p>\n", Visibility: 3, Tags: []string{"go"}})
+
+	if _, err := tst.EditPost(postId, map[string]interface{}{"content": "<p>one</p>\n<p>2</p>\n"}); err != nil {
+		t.Fatalf("Tistory.EditPost() error = %v", err)
+	}
+	if _, err := tst.EditPost(postId, map[string]interface{}{"title": "v3"}); err != nil {
+		t.Fatalf("Tistory.EditPost() error = %v", err)
+	}
+
+	revisions, err := tst.PostRevisions(postId)
+	if err != nil {
+		t.Fatalf("Tistory.PostRevisions() error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Number != 1 || revisions[0].Post.Title != "v1" ||
+		revisions[1].Post.Content != "<p>one</p>\n<p>2</p>\n" {
+		t.Fatalf("Tistory.PostRevisions() = %+v", revisions)
+	}
+
+	d, err := tst.DiffRevisions(postId, 1, 2)
+	if err != nil {
+		t.Fatalf("Tistory.DiffRevisions() error = %v", err)
+	}
+	if !strings.Contains(d, "-<p>two</p>\n+<p>2</p>\n") || strings.Contains(d, "title") {
+		t.Errorf("Tistory.DiffRevisions(1, 2) =\n%s", d)
+	}
+	if d, _ := tst.DiffRevisions(postId, 2, 0); !strings.Contains(d, "-title: v1\n+title: v3\n") || !strings.Contains(d, "+++ current") {
+		t.Errorf("Tistory.DiffRevisions(2, current) =\n%s", d)
+	}
+
+	if _, err := tst.RestoreRevision(postId, 1); err != nil {
+		t.Fatalf("Tistory.RestoreRevision() error = %v", err)
+	}
+	post, _ := srv.Post(tistorytest.DefaultBlog, postId)
+	if post.Title != "v1" || post.Content != "<p>one</p>\n<p>two</p>\n" || len(post.Tags) != 1 {
+		t.Errorf("restored post = %+v", post)
+	}
+	if revisions, _ := tst.PostRevisions(postId); len(revisions) != 3 || revisions[2].Post.Title != "v3" {
+		t.Errorf("Tistory.PostRevisions() after restore = %+v, want the replaced post saved", revisions)
+	}
+	if _, err := tst.RestoreRevision(postId, 9); err == nil {
+		t.Errorf("Tistory.RestoreRevision() of a missing revision error = nil, want error")
+	}
+}
+
+// failPath fails every request to path.
+type failPath struct {
+	http.RoundTripper
+	path string
+}
+
+func (f failPath) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == f.path {
+		return nil, errors.New("connection reset")
+	}
+	return f.RoundTripper.RoundTrip(req)
+}
+
+func TestTistory_RevisionsFailedModify(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+	tst.Revisions = NewFileRevisionStore(t.TempDir())
+	tst.HTTPClient = &http.Client{Transport: failPath{srv.Client().Transport, "/apis/post/modify"}}
+
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "v1", Visibility: 3})
+	if _, err := tst.EditPost(postId, map[string]interface{}{"title": "v2"}); err == nil {
+		t.Fatalf("Tistory.EditPost() error = nil, want error")
+	}
+	if revisions, err := tst.PostRevisions(postId); err != nil || len(revisions) != 0 {
+		t.Errorf("Tistory.PostRevisions() after a failed modify = %+v, %v, want none", revisions, err)
+	}
+}
+
+func TestFileRevisionStore_Concurrent(t *testing.T) {
+	store := NewFileRevisionStore(t.TempDir())
+	var wg sync.WaitGroup
+	numbers := make([]int, 8)
+	for i := range numbers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := store.SaveRevision(Revision{PostId: 1, Time: time.Now(), Post: Post{Title: fmt.Sprint(i)}})
+			if err != nil {
+				t.Errorf("FileRevisionStore.SaveRevision() error = %v", err)
+			}
+			numbers[i] = r.Number
+		}(i)
+	}
+	wg.Wait()
+
+	revisions, err := store.Revisions(1)
+	if err != nil || len(revisions) != len(numbers) {
+		t.Fatalf("FileRevisionStore.Revisions() = %d revisions, %v, want %d", len(revisions), err, len(numbers))
+	}
+	for i, r := range revisions {
+		if r.Number != i+1 || r.Post.Title != fmt.Sprint(indexOf(numbers, r.Number)) {
+			t.Errorf("revision %d = %+v, saved as %v", i+1, r, numbers)
+		}
+	}
+}
+
+func indexOf(numbers []int, n int) int {
+	for i, m := range numbers {
+		if m == n {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,194 @@
+// Package schedule manages a local queue of reserved (future) posts.
+//
+// Tistory publishes a post written with a future `published` time by itself;
+// the queue remembers those reservations so they can be listed, moved to
+// another time with ModifyPost, or canceled. Two reservations less than a
+// slot apart are rejected with a *ConflictError.
+//
+//	store, err := schedule.Open("queue.json")
+//	s := schedule.New(blog, store)
+//	s.Slot = time.Hour
+//	entry, err := s.Add(map[string]interface{}{"title": "t", "content": "c"}, at)
+//	err = s.Move(entry.Id, at.Add(24*time.Hour))
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// DefaultSlot is the default length of a publishing slot.
+const DefaultSlot = time.Minute
+
+// ConflictError reports a reservation less than a slot from another one.
+type ConflictError struct {
+	At    time.Time
+	Slot  time.Duration
+	Entry *Entry
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s is within %s of queue entry %d (post %d, %q at %s)",
+		e.At.Format("2006-01-02 15:04"), e.Slot, e.Entry.Id, e.Entry.PostId, e.Entry.Title,
+		e.Entry.Published.Format("2006-01-02 15:04"))
+}
+
+// Scheduler reserves posts of Blog and records them in Store.
+type Scheduler struct {
+	Blog  *tistory.Tistory
+	Store *Store
+	// Slot is the length of a publishing slot; two reservations whose
+	// publish times are less than Slot apart conflict (default: DefaultSlot).
+	Slot time.Duration
+
+	now func() time.Time
+}
+
+func New(blog *tistory.Tistory, store *Store) *Scheduler {
+	return &Scheduler{Blog: blog, Store: store, Slot: DefaultSlot}
+}
+
+/*
+Add writes a post reserved for at and adds it to the queue.
+option holds the post/write parameters; visibility defaults to 3 (발행)
+so that the post goes public at the reserved time.
+*/
+func (s *Scheduler) Add(option map[string]interface{}, at time.Time) (*Entry, error) {
+	title, _ := option["title"].(string)
+	if title == "" {
+		return nil, errors.New("Failed to Add (title is required)")
+	}
+	if err := s.checkSlot(0, at); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]interface{}, len(option)+2)
+	for key, value := range option {
+		params[key] = value
+	}
+	if _, ok := params["visibility"]; !ok {
+		params["visibility"] = 3
+	}
+	params["published"] = at.Unix()
+
+	result, err := s.Blog.WritePost(params)
+	if err != nil {
+		return nil, err
+	}
+	body, _ := result["tistory"].(map[string]interface{})
+	postId, _ := strconv.Atoi(fmt.Sprintf("%v", body["postId"]))
+	if postId == 0 {
+		return nil, errors.New("Failed to Add (post/write returned no postId)")
+	}
+
+	now := s.clock()
+	e := &Entry{
+		PostId:    postId,
+		PostURL:   fmt.Sprintf("%v", body["url"]),
+		Title:     title,
+		Published: at,
+		Status:    StatusReserved,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.Store.add(e)
+	return e, s.Store.Save()
+}
+
+// Move reschedules the reservation id to at with ModifyPost.
+func (s *Scheduler) Move(id int, at time.Time) (*Entry, error) {
+	e, err := s.reserved(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSlot(id, at); err != nil {
+		return nil, err
+	}
+	if _, err := s.Blog.EditPost(e.PostId, map[string]interface{}{"published": at.Unix()}); err != nil {
+		return nil, err
+	}
+	e.Published = at
+	e.UpdatedAt = s.clock()
+	return e, s.Store.Save()
+}
+
+/*
+Cancel turns the reservation id into a private post (visibility 0), so it is
+not published; the post itself is kept, as the Open API cannot delete posts.
+*/
+func (s *Scheduler) Cancel(id int) (*Entry, error) {
+	e, err := s.reserved(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.Blog.EditPost(e.PostId, map[string]interface{}{"visibility": 0}); err != nil {
+		return nil, err
+	}
+	e.Status = StatusCanceled
+	e.UpdatedAt = s.clock()
+	return e, s.Store.Save()
+}
+
+// List returns the queue ordered by publish time. Reservations whose time
+// has passed are marked published.
+func (s *Scheduler) List() ([]*Entry, error) {
+	now := s.clock()
+	changed := false
+	for _, e := range s.Store.Entries {
+		if e.Status == StatusReserved && !e.Published.After(now) {
+			e.Status = StatusPublished
+			e.UpdatedAt = now
+			changed = true
+		}
+	}
+	if changed {
+		if err := s.Store.Save(); err != nil {
+			return nil, err
+		}
+	}
+	return s.Store.List(), nil
+}
+
+// reserved returns the entry id if it is still waiting to be published.
+func (s *Scheduler) reserved(id int) (*Entry, error) {
+	e, ok := s.Store.Get(id)
+	if !ok {
+		return nil, errors.Errorf("queue entry %d not found", id)
+	}
+	if e.Status != StatusReserved || !e.Published.After(s.clock()) {
+		return nil, errors.Errorf("queue entry %d is not reserved (%s)", id, e.Status)
+	}
+	return e, nil
+}
+
+// checkSlot rejects past times and times less than a slot from another
+// reservation than the entry skip.
+func (s *Scheduler) checkSlot(skip int, at time.Time) error {
+	if !at.After(s.clock()) {
+		return errors.Errorf("publish time %s is not in the future", at.Format(time.RFC3339))
+	}
+	slot := s.Slot
+	if slot <= 0 {
+		slot = DefaultSlot
+	}
+	for _, e := range s.Store.Entries {
+		if e.Id == skip || e.Status != StatusReserved {
+			continue
+		}
+		if d := at.Sub(e.Published); d < slot && d > -slot {
+			return &ConflictError{At: at, Slot: slot, Entry: e}
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,89 @@
+package schedule
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestScheduler(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+
+	path := filepath.Join(t.TempDir(), "queue.json")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	s := New(blog, store)
+	s.Slot = time.Hour
+	at := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+
+	first, err := s.Add(map[string]interface{}{"title": "first", "content": "<p>1</p>", "tag": "go"}, at)
+	if err != nil {
+		t.Fatalf("Scheduler.Add() error = %v", err)
+	}
+	post, _ := srv.Post(tistorytest.DefaultBlog, first.PostId)
+	if !post.Published.Equal(at) || post.Visibility != 3 {
+		t.Errorf("Scheduler.Add() stored = %+v, want public at %v", post, at)
+	}
+
+	if _, err := s.Add(map[string]interface{}{"title": "clash"}, at.Add(30*time.Minute)); err == nil {
+		t.Errorf("Scheduler.Add() in a taken slot error = nil, want *ConflictError")
+	} else if _, ok := err.(*ConflictError); !ok {
+		t.Errorf("Scheduler.Add() error = %T %v, want *ConflictError", err, err)
+	}
+	// Slots are measured from each reservation, not from the hour.
+	if _, err := s.Add(map[string]interface{}{"title": "clash"}, at.Add(-time.Minute)); err == nil {
+		t.Errorf("Scheduler.Add() a minute before a reservation error = nil, want *ConflictError")
+	}
+	if _, err := s.Add(map[string]interface{}{"title": "past"}, time.Now().Add(-time.Hour)); err == nil {
+		t.Errorf("Scheduler.Add() in the past error = nil, want error")
+	}
+	second, err := s.Add(map[string]interface{}{"title": "second"}, at.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Scheduler.Add() error = %v", err)
+	}
+
+	if _, err := s.Move(second.Id, at.Add(10*time.Minute)); err == nil {
+		t.Errorf("Scheduler.Move() into a taken slot error = nil, want error")
+	}
+	later := at.Add(48 * time.Hour)
+	if _, err := s.Move(first.Id, later); err != nil {
+		t.Fatalf("Scheduler.Move() error = %v", err)
+	}
+	post, _ = srv.Post(tistorytest.DefaultBlog, first.PostId)
+	if !post.Published.Equal(later) || post.Content != "<p>1</p>" || len(post.Tags) != 1 {
+		t.Errorf("Scheduler.Move() stored = %+v, want content kept and published %v", post, later)
+	}
+
+	if _, err := s.Cancel(second.Id); err != nil {
+		t.Fatalf("Scheduler.Cancel() error = %v", err)
+	}
+	if post, _ := srv.Post(tistorytest.DefaultBlog, second.PostId); post.Visibility != 0 {
+		t.Errorf("Scheduler.Cancel() visibility = %d, want 0", post.Visibility)
+	}
+	if _, err := s.Move(second.Id, later.Add(time.Hour)); err == nil {
+		t.Errorf("Scheduler.Move() on a canceled entry error = nil, want error")
+	}
+
+	// The queue persists; passed reservations are listed as published.
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2 := New(blog, reopened)
+	s2.now = func() time.Time { return later.Add(time.Minute) }
+	entries, err := s2.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Id != second.Id || entries[0].Status != StatusCanceled ||
+		entries[1].Id != first.Id || entries[1].Status != StatusPublished {
+		t.Errorf("Scheduler.List() = %+v", entries)
+	}
+}
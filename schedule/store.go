@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Status is the state of a queue entry.
+type Status string
+
+const (
+	// StatusReserved is a post written with a future publish time.
+	StatusReserved Status = "reserved"
+	// StatusPublished is a reserved post whose publish time has passed.
+	StatusPublished Status = "published"
+	// StatusCanceled is a reservation turned back into a private post.
+	StatusCanceled Status = "canceled"
+)
+
+// Entry is one scheduled post.
+type Entry struct {
+	Id        int       `json:"id"`
+	PostId    int       `json:"postId"`
+	PostURL   string    `json:"postUrl,omitempty"`
+	Title     string    `json:"title"`
+	Published time.Time `json:"published"`
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store keeps the queue in a JSON file.
+type Store struct {
+	path    string
+	NextId  int      `json:"nextId"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Open reads the queue at path. A missing file is an empty queue.
+func Open(path string) (*Store, error) {
+	s := &Store{path: path, NextId: 1}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read queue")
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse queue (%s)", path)
+	}
+	return s, nil
+}
+
+// Path returns the file the store was opened from.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Save writes the queue atomically.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return errors.Wrap(err, "Failed to save queue")
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return errors.Wrap(err, "Failed to save queue")
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return errors.Wrap(err, "Failed to save queue")
+	}
+	return nil
+}
+
+// add assigns an id to e and appends it.
+func (s *Store) add(e *Entry) {
+	if s.NextId < 1 {
+		s.NextId = 1
+	}
+	e.Id = s.NextId
+	s.NextId++
+	s.Entries = append(s.Entries, e)
+}
+
+// Get returns the entry with the queue id id.
+func (s *Store) Get(id int) (*Entry, bool) {
+	for _, e := range s.Entries {
+		if e.Id == id {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// List returns the entries ordered by publish time.
+func (s *Store) List() []*Entry {
+	entries := append([]*Entry(nil), s.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Published.Before(entries[j].Published)
+	})
+	return entries
+}
@@ -0,0 +1,213 @@
+// Package search is a local full-text index of a Tistory blog, since the
+// Open API has no search endpoint. Posts are read with GetPost, reduced to
+// text and kept in an inverted index with term positions, which supports
+// phrase queries, tag and category filters and BM25 ranking.
+//
+//	idx, err := search.Open("index.json")
+//	result, err := idx.Update(ctx, blog, false)
+//	err = idx.Save()
+//	results := idx.Search(search.ParseQuery(`"검색 엔진" tag:go`), 10)
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/content"
+	"github.com/pkg/errors"
+)
+
+// Document is an indexed post.
+type Document struct {
+	Id         int
+	Title      string
+	PostURL    string
+	CategoryId int
+	Tags       []string
+	Date       time.Time
+	Text       string // body text, for snippets
+	Length     int    // number of terms in the title, tags and body
+}
+
+// Posting holds the positions of a term in the title (with tags) and in
+// the body of a document.
+type Posting struct {
+	Title []int `json:",omitempty"`
+	Body  []int `json:",omitempty"`
+}
+
+// Index is an inverted index of posts.
+type Index struct {
+	Docs       map[int]*Document
+	Terms      map[string]map[int]*Posting
+	Categories []tistory.Category
+	UpdatedAt  time.Time
+
+	path string
+}
+
+// New returns an empty index saved to path.
+func New(path string) *Index {
+	return &Index{Docs: map[int]*Document{}, Terms: map[string]map[int]*Posting{}, path: path}
+}
+
+// Open reads the index at path; a missing file gives an empty index.
+func Open(path string) (*Index, error) {
+	idx := New(path)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read search index")
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse search index (%s)", path)
+	}
+	if idx.Docs == nil {
+		idx.Docs = map[int]*Document{}
+	}
+	if idx.Terms == nil {
+		idx.Terms = map[string]map[int]*Posting{}
+	}
+	return idx, nil
+}
+
+// Path returns the file of the index.
+func (idx *Index) Path() string {
+	return idx.path
+}
+
+// Save writes the index atomically.
+func (idx *Index) Save() error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0o700); err != nil {
+		return errors.Wrap(err, "Failed to save search index")
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return errors.Wrap(err, "Failed to save search index")
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		return errors.Wrap(err, "Failed to save search index")
+	}
+	return nil
+}
+
+// Add indexes post, replacing a previous version of it.
+func (idx *Index) Add(post tistory.Post) {
+	idx.Remove(post.Id)
+
+	text := content.Text(post.Content)
+	title := Tokenize(post.Title + " " + strings.Join(post.Tags, " "))
+	body := Tokenize(text)
+	idx.Docs[post.Id] = &Document{
+		Id:         post.Id,
+		Title:      post.Title,
+		PostURL:    post.PostURL,
+		CategoryId: post.CategoryId,
+		Tags:       post.Tags,
+		Date:       post.Date,
+		Text:       text,
+		Length:     len(title) + len(body),
+	}
+	posting := func(term string) *Posting {
+		docs, ok := idx.Terms[term]
+		if !ok {
+			docs = map[int]*Posting{}
+			idx.Terms[term] = docs
+		}
+		p, ok := docs[post.Id]
+		if !ok {
+			p = &Posting{}
+			docs[post.Id] = p
+		}
+		return p
+	}
+	for _, t := range title {
+		p := posting(t.Term)
+		p.Title = append(p.Title, t.Pos)
+	}
+	for _, t := range body {
+		p := posting(t.Term)
+		p.Body = append(p.Body, t.Pos)
+	}
+}
+
+// Remove drops a post from the index.
+func (idx *Index) Remove(postId int) {
+	if _, ok := idx.Docs[postId]; !ok {
+		return
+	}
+	delete(idx.Docs, postId)
+	for term, docs := range idx.Terms {
+		if _, ok := docs[postId]; ok {
+			delete(docs, postId)
+			if len(docs) == 0 {
+				delete(idx.Terms, term)
+			}
+		}
+	}
+}
+
+// UpdateResult counts what Update did.
+type UpdateResult struct {
+	Posts   int // posts of the blog
+	Indexed int // posts read with GetPost and indexed
+	Removed int // posts no longer on the blog
+}
+
+/*
+Update brings the index up to date with blog. Every post is listed with
+GetPostList; only new posts and posts whose title, URL, category or date
+changed are read with GetPost, unless full is set. Posts no longer listed
+are removed. The index is not saved.
+*/
+func (idx *Index) Update(ctx context.Context, blog *tistory.Tistory, full bool) (*UpdateResult, error) {
+	categories, err := blog.Categories()
+	if err != nil {
+		return nil, err
+	}
+	idx.Categories = categories
+
+	posts, err := blog.GetAllPosts()
+	if err != nil {
+		return nil, err
+	}
+	result := &UpdateResult{Posts: len(posts)}
+	listed := make(map[int]bool, len(posts))
+	for _, p := range posts {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		listed[p.Id] = true
+		if doc, ok := idx.Docs[p.Id]; ok && !full && doc.Title == p.Title && doc.PostURL == p.PostURL &&
+			doc.CategoryId == p.CategoryId && doc.Date.Equal(p.Date) {
+			continue
+		}
+		post, err := blog.ReadPost(p.Id)
+		if err != nil {
+			return result, err
+		}
+		// Keep the listed metadata so that the next Update compares like with like.
+		post.Title, post.PostURL, post.CategoryId, post.Date = p.Title, p.PostURL, p.CategoryId, p.Date
+		idx.Add(post)
+		result.Indexed++
+	}
+	for id := range idx.Docs {
+		if !listed[id] {
+			idx.Remove(id)
+			result.Removed++
+		}
+	}
+	idx.UpdatedAt = time.Now()
+	return result, nil
+}
@@ -0,0 +1,277 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Query is a parsed search query.
+type Query struct {
+	// Phrases must all appear; the terms of a phrase (or of a single word
+	// split into bigrams) must be adjacent.
+	Phrases  []string
+	Tags     []string
+	Category string // category ID, name or label; includes subcategories
+}
+
+/*
+ParseQuery parses a query string. Words and "quoted phrases" must all
+match; tag:NAME and category:NAME filter the posts.
+
+	"검색 엔진" golang tag:go category:dev
+*/
+func ParseQuery(s string) Query {
+	var q Query
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			break
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				end = len(s) - 1
+			}
+			if phrase := strings.TrimSpace(s[1 : end+1]); phrase != "" {
+				q.Phrases = append(q.Phrases, phrase)
+			}
+			s = s[min(end+2, len(s)):]
+			continue
+		}
+		word := s
+		if i := strings.IndexAny(s, " \t\r\n"); i >= 0 {
+			word, s = s[:i], s[i:]
+		} else {
+			s = ""
+		}
+		switch {
+		case strings.HasPrefix(word, "tag:") && len(word) > len("tag:"):
+			q.Tags = append(q.Tags, word[len("tag:"):])
+		case strings.HasPrefix(word, "category:") && len(word) > len("category:"):
+			q.Category = word[len("category:"):]
+		default:
+			q.Phrases = append(q.Phrases, word)
+		}
+	}
+	return q
+}
+
+// Result is a matching post.
+type Result struct {
+	*Document
+	Score   float64
+	Snippet string
+}
+
+// titleBoost weighs a match in the title or tags against one in the body.
+const titleBoost = 3
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Search returns up to limit (0: all) posts matching q, best first.
+func (idx *Index) Search(q Query, limit int) []Result {
+	var phrases [][]Token
+	for _, phrase := range q.Phrases {
+		if tokens := Tokenize(phrase); len(tokens) > 0 {
+			phrases = append(phrases, tokens)
+		}
+	}
+	categories := idx.categoryIds(q.Category)
+
+	var avgLength float64
+	for _, doc := range idx.Docs {
+		avgLength += float64(doc.Length)
+	}
+	if len(idx.Docs) > 0 {
+		avgLength /= float64(len(idx.Docs))
+	}
+
+	var results []Result
+	for _, doc := range idx.Docs {
+		if !hasTags(doc, q.Tags) || categories != nil && !categories[doc.CategoryId] {
+			continue
+		}
+		score, ok := 0.0, true
+		for _, phrase := range phrases {
+			s, matched := idx.matchPhrase(doc, phrase, avgLength)
+			if !matched {
+				ok = false
+				break
+			}
+			score += s
+		}
+		if !ok {
+			continue
+		}
+		results = append(results, Result{Document: doc, Score: score, Snippet: snippet(doc.Text, q.Phrases)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Date.After(results[j].Date)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// matchPhrase reports whether the terms of phrase appear at consecutive
+// positions of the title or the body of doc, and scores the match.
+func (idx *Index) matchPhrase(doc *Document, phrase []Token, avgLength float64) (float64, bool) {
+	postings := make([]*Posting, len(phrase))
+	for i, t := range phrase {
+		p := idx.posting(t.Term, doc.Id)
+		if p == nil {
+			return 0, false
+		}
+		postings[i] = p
+	}
+	titleHits := adjacent(postings, func(p *Posting) []int { return p.Title })
+	bodyHits := adjacent(postings, func(p *Posting) []int { return p.Body })
+	if titleHits+bodyHits == 0 {
+		return 0, false
+	}
+
+	// BM25 of the phrase, with the rarest term's document frequency as idf.
+	df := len(idx.Docs)
+	for _, t := range phrase {
+		df = min(df, idx.docFreq(t.Term))
+	}
+	n := float64(len(idx.Docs))
+	idf := math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+	tf := float64(bodyHits + titleBoost*titleHits)
+	norm := 1.0
+	if avgLength > 0 {
+		norm = 1 - b + b*float64(doc.Length)/avgLength
+	}
+	return idf * tf * (k1 + 1) / (tf + k1*norm) * float64(len(phrase)), true
+}
+
+// posting returns the positions of term in doc. A single CJK syllable also
+// matches the bigrams that start or end with it.
+func (idx *Index) posting(term string, docId int) *Posting {
+	if p := idx.Terms[term][docId]; p != nil {
+		return p
+	}
+	if r, size := utf8.DecodeRuneInString(term); size != len(term) || !isCJK(r) {
+		return nil
+	}
+	merged := &Posting{}
+	for t, docs := range idx.Terms {
+		if p := docs[docId]; p != nil && utf8.RuneCountInString(t) == 2 && strings.Contains(t, term) {
+			merged.Title = append(merged.Title, p.Title...)
+			merged.Body = append(merged.Body, p.Body...)
+		}
+	}
+	if len(merged.Title)+len(merged.Body) == 0 {
+		return nil
+	}
+	sort.Ints(merged.Title)
+	sort.Ints(merged.Body)
+	return merged
+}
+
+// docFreq is the number of documents containing term.
+func (idx *Index) docFreq(term string) int {
+	if docs, ok := idx.Terms[term]; ok {
+		return len(docs)
+	}
+	return 1
+}
+
+// adjacent counts the positions where the terms of postings follow each other.
+func adjacent(postings []*Posting, field func(*Posting) []int) int {
+	positions := make([]map[int]bool, len(postings))
+	for i, p := range postings[1:] {
+		positions[i+1] = map[int]bool{}
+		for _, pos := range field(p) {
+			positions[i+1][pos] = true
+		}
+	}
+	hits := 0
+	for _, start := range field(postings[0]) {
+		ok := true
+		for i := 1; i < len(postings) && ok; i++ {
+			ok = positions[i][start+i]
+		}
+		if ok {
+			hits++
+		}
+	}
+	return hits
+}
+
+func hasTags(doc *Document, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range doc.Tags {
+			if strings.EqualFold(tag, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// categoryIds returns the ids of the categories matching name and their
+// subcategories, or nil when name is empty.
+func (idx *Index) categoryIds(name string) map[int]bool {
+	if name == "" {
+		return nil
+	}
+	ids := map[int]bool{}
+	if id, err := strconv.Atoi(name); err == nil {
+		ids[id] = true
+	}
+	for _, c := range idx.Categories {
+		if c.Name == name || c.Label == name || strings.HasPrefix(c.Label, name+"/") {
+			ids[c.Id] = true
+		}
+	}
+	// Children of a category matched by id.
+	for _, c := range idx.Categories {
+		if ids[c.Parent] {
+			ids[c.Id] = true
+		}
+	}
+	return ids
+}
+
+// snippetRunes is the length of a snippet.
+const snippetRunes = 80
+
+// snippet returns the part of text around the first phrase found in it.
+func snippet(text string, phrases []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	start := 0
+	for _, phrase := range phrases {
+		if i := strings.Index(string(lower), strings.ToLower(phrase)); i >= 0 {
+			start = max(utf8.RuneCountInString(string(lower)[:i])-snippetRunes/4, 0)
+			break
+		}
+	}
+	end := min(start+snippetRunes, len(runes))
+	s := string(runes[start:end])
+	if start > 0 {
+		s = "…" + s
+	}
+	if end < len(runes) {
+		s += "…"
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "Hello, World 2026", want: []string{"hello", "world", "2026"}},
+		{in: "검색엔진을 만들자", want: []string{"검색", "색엔", "엔진", "진을", "만들", "들자"}},
+		{in: "Go언어 책", want: []string{"go", "언어", "책"}},
+	}
+	for _, tt := range tests {
+		if got := terms(Tokenize(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	got := ParseQuery(`"검색 엔진" golang tag:go category:dev/Go "unterminated`)
+	want := Query{Phrases: []string{"검색 엔진", "golang", "unterminated"}, Tags: []string{"go"}, Category: "dev/Go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseQuery() = %+v, want %+v", got, want)
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+	base := time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	dev := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "dev"})
+	golang := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "Go", Parent: dev})
+	engine := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "검색엔진 만들기", CategoryID: golang,
+		Content: "<p>Go 언어로 역색인을 만들어 검색엔진을 구현합니다.</p>", Tags: []string{"go"}, Published: base})
+	travel := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "여행기",
+		Content: "<p>검색해 보니 엔진 소리가 좋은 배를 탔다.</p>", Tags: []string{"travel"}, Published: base.Add(time.Hour)})
+	cli := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "CLI tips", CategoryID: dev,
+		Content: "<p>flag package, 검색 옵션</p>", Tags: []string{"go", "cli"}, Published: base.Add(2 * time.Hour)})
+
+	path := filepath.Join(t.TempDir(), "index.json")
+	idx, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	result, err := idx.Update(context.Background(), blog, false)
+	if err != nil || result.Indexed != 3 {
+		t.Fatalf("Index.Update() = %+v, %v", result, err)
+	}
+	if err := idx.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if idx, err = Open(path); err != nil || len(idx.Docs) != 3 {
+		t.Fatalf("Open() saved index = %d docs, %v", len(idx.Docs), err)
+	}
+	if result, err = idx.Update(context.Background(), blog, false); err != nil || result.Indexed != 0 {
+		t.Errorf("Index.Update() unchanged = %+v, %v, want nothing indexed", result, err)
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "검색엔진", want: []int{engine}},
+		{query: `"역색인을 만들어"`, want: []int{engine}},
+		{query: "검색 tag:go", want: []int{engine, cli}},
+		{query: "category:dev 검색", want: []int{engine, cli}},
+		{query: "category:Go 검색", want: []int{engine}},
+		{query: "FLAG", want: []int{cli}},
+		{query: "배", want: []int{travel}},
+		{query: "없는말", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got []int
+			for _, r := range idx.Search(ParseQuery(tt.query), 0) {
+				got = append(got, r.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Index.Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	results := idx.Search(ParseQuery("역색인"), 1)
+	if len(results) != 1 || results[0].Snippet == "" || results[0].Score <= 0 {
+		t.Errorf("Index.Search() = %+v", results)
+	}
+	idx.Remove(engine)
+	if got := idx.Search(ParseQuery("역색인"), 0); len(got) != 0 {
+		t.Errorf("Index.Search() after Remove = %v", got)
+	}
+}
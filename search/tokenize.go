@@ -0,0 +1,75 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is a term and its position in the text.
+type Token struct {
+	Term string
+	Pos  int
+}
+
+/*
+Tokenize splits text into terms. Runs of Latin letters and digits become
+lower-cased words. Korean (and other CJK) text has no reliable word
+boundaries once particles are attached ("검색엔진을"), so each run is split
+into overlapping bigrams ("검색", "색엔", "엔진", "진을"); a single syllable
+is kept as is. Positions increase by one per term, so a phrase matches terms
+at consecutive positions.
+*/
+func Tokenize(text string) []Token {
+	var tokens []Token
+	var run []rune
+	cjk := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if !cjk {
+			tokens = append(tokens, Token{Term: strings.ToLower(string(run)), Pos: len(tokens)})
+		} else if len(run) == 1 {
+			tokens = append(tokens, Token{Term: string(run), Pos: len(tokens)})
+		} else {
+			for i := 0; i+1 < len(run); i++ {
+				tokens = append(tokens, Token{Term: string(run[i : i+2]), Pos: len(tokens)})
+			}
+		}
+		run = run[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			if !cjk {
+				flush()
+				cjk = true
+			}
+			run = append(run, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if cjk {
+				flush()
+				cjk = false
+			}
+			run = append(run, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Hangul, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// terms returns the terms of tokens.
+func terms(tokens []Token) []string {
+	out := make([]string, len(tokens))
+	for i, t := range tokens {
+		out[i] = t.Term
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package tags
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+/*
+DefaultAliases maps folded spellings (see Key) to a common key, mostly
+Korean transliterations and short forms of technical tags.
+*/
+var DefaultAliases = map[string]string{
+	"고랭":         "go",
+	"고언어":        "go",
+	"golang":     "go",
+	"자바":         "java",
+	"자바스크립트":     "javascript",
+	"js":         "javascript",
+	"타입스크립트":     "typescript",
+	"ts":         "typescript",
+	"파이썬":        "python",
+	"py":         "python",
+	"러스트":        "rust",
+	"코틀린":        "kotlin",
+	"스프링":        "spring",
+	"리액트":        "react",
+	"reactjs":    "react",
+	"vuejs":      "vue",
+	"노드":         "nodejs",
+	"node":       "nodejs",
+	"도커":         "docker",
+	"쿠버네티스":      "kubernetes",
+	"k8s":        "kubernetes",
+	"리눅스":        "linux",
+	"깃":          "git",
+	"깃허브":        "github",
+	"알고리즘":       "algorithm",
+	"algorithms": "algorithm",
+	"자료구조":       "datastructure",
+	"데이터베이스":     "database",
+	"db":         "database",
+}
+
+/*
+Key folds a tag name for comparison: it is lower-cased, everything but
+letters and digits is dropped ("Node.js" → "nodejs") and the result is
+looked up in aliases.
+*/
+func Key(name string, aliases map[string]string) string {
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+	if alias, ok := aliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// Group is a set of tags that are probably the same tag.
+type Group struct {
+	// Canonical is the most used name of the group, the suggested merge target.
+	Canonical string
+	Tags      []Tag // most used first
+}
+
+// Names returns the names of the tags of g.
+func (g Group) Names() []string {
+	names := make([]string, len(g.Tags))
+	for i, t := range g.Tags {
+		names[i] = t.Name
+	}
+	return names
+}
+
+/*
+Duplicates groups tags whose Key is equal, or whose keys of at least five
+letters differ by one edit ("kubernets", "kubernetes"); keys with digits
+are only grouped when equal, so "python2" and "python3" stay apart. Only
+groups of two or more tags are returned, largest total count first.
+*/
+func Duplicates(tags []Tag, aliases map[string]string) []Group {
+	keys := make([]string, len(tags))
+	parent := make([]int, len(tags))
+	for i, t := range tags {
+		keys[i] = Key(t.Name, aliases)
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range tags {
+		for j := i + 1; j < len(tags); j++ {
+			if keys[i] == keys[j] || similar(keys[i], keys[j]) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	members := map[int][]Tag{}
+	for i, t := range tags {
+		root := find(i)
+		members[root] = append(members[root], t)
+	}
+	var groups []Group
+	total := map[string]int{}
+	for _, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool {
+			if group[i].Count != group[j].Count {
+				return group[i].Count > group[j].Count
+			}
+			return group[i].Name < group[j].Name
+		})
+		g := Group{Canonical: group[0].Name, Tags: group}
+		for _, t := range group {
+			total[g.Canonical] += t.Count
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if total[groups[i].Canonical] != total[groups[j].Canonical] {
+			return total[groups[i].Canonical] > total[groups[j].Canonical]
+		}
+		return groups[i].Canonical < groups[j].Canonical
+	})
+	return groups
+}
+
+// similar reports whether two keys of at least five letters, without
+// digits, are one insertion, deletion or substitution apart.
+func similar(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < 5 || len(rb) < 5 || strings.ContainsAny(a+b, "0123456789") {
+		return false
+	}
+	if len(ra) > len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(rb)-len(ra) > 1 {
+		return false
+	}
+	i := 0
+	for i < len(ra) && ra[i] == rb[i] {
+		i++
+	}
+	if i == len(ra) {
+		return true
+	}
+	if len(ra) == len(rb) {
+		return string(ra[i+1:]) == string(rb[i+1:])
+	}
+	return string(ra[i:]) == string(rb[i+1:])
+}
@@ -0,0 +1,73 @@
+package tags
+
+import (
+	"context"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+/*
+Replace replaces every tag named in from with into, keeping the first
+position of into; an empty into deletes the tags. Names are compared
+exactly. It reports whether tags changed.
+*/
+func Replace(tags []string, from []string, into string) ([]string, bool) {
+	replaced := map[string]bool{}
+	for _, name := range from {
+		replaced[name] = true
+	}
+	out := make([]string, 0, len(tags))
+	seenInto := false
+	for _, tag := range tags {
+		if replaced[tag] {
+			tag = into
+		}
+		if tag == "" {
+			continue
+		}
+		if tag == into {
+			if seenInto {
+				continue
+			}
+			seenInto = true
+		}
+		out = append(out, tag)
+	}
+
+	changed := len(out) != len(tags)
+	for i := 0; !changed && i < len(out); i++ {
+		changed = out[i] != tags[i]
+	}
+	return out, changed
+}
+
+/*
+Merge replaces the tags named in from with into on every post that has one
+of them, writing the posts back with ModifyPost through BulkModify. Every
+post is read, since post/list has no tags.
+*/
+func Merge(ctx context.Context, blog *tistory.Tistory, from []string, into string, options *tistory.BulkOptions) ([]tistory.BulkResult, error) {
+	if len(from) == 0 {
+		return nil, errors.New("Failed to merge tags (no tag given)")
+	}
+	mutator := func(p *tistory.Post) (bool, error) {
+		var changed bool
+		p.Tags, changed = Replace(p.Tags, from, into)
+		return changed, nil
+	}
+	return blog.BulkModify(ctx, func(tistory.PostSummary) bool { return true }, mutator, options)
+}
+
+// Rename renames the tag from to to on every post, like Merge.
+func Rename(ctx context.Context, blog *tistory.Tistory, from, to string, options *tistory.BulkOptions) ([]tistory.BulkResult, error) {
+	if to == "" {
+		return nil, errors.New("Failed to rename tag (empty new name)")
+	}
+	return Merge(ctx, blog, []string{from}, to, options)
+}
+
+// Delete removes the tags names from every post, like Merge.
+func Delete(ctx context.Context, blog *tistory.Tistory, names []string, options *tistory.BulkOptions) ([]tistory.BulkResult, error) {
+	return Merge(ctx, blog, names, "", options)
+}
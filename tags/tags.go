@@ -0,0 +1,91 @@
+// Package tags manages the tags of a whole blog. Tistory keeps tags only as
+// the comma separated tag option of each post, so Collect reads every post,
+// Duplicates groups near-duplicate spellings ("golang", "Go", "고랭") and
+// Rename, Merge and Delete rewrite the affected posts with ModifyPost
+// through BulkModify, with its rate limit, dry run and rollback file.
+//
+//	counts, err := tags.Collect(ctx, blog, nil)
+//	for _, g := range tags.Duplicates(counts, tags.DefaultAliases) {
+//		results, err := tags.Merge(ctx, blog, g.Names(), g.Canonical, options)
+//	}
+package tags
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/LimJiAn/tistory-go"
+)
+
+// Tag is a tag and the posts using it.
+type Tag struct {
+	Name    string
+	Count   int
+	PostIds []int
+}
+
+/*
+Collect reads every post and counts its tags. Posts are read with
+BulkModify (never writing anything back), so options set the number of
+workers and the rate limit; DryRun and Rollback are ignored.
+*/
+func Collect(ctx context.Context, blog *tistory.Tistory, options *tistory.BulkOptions) ([]Tag, error) {
+	read := tistory.BulkOptions{}
+	if options != nil {
+		read.Workers, read.Interval, read.Progress = options.Workers, options.Interval, options.Progress
+	}
+	var mu sync.Mutex
+	var posts []tistory.Post
+	collect := func(p *tistory.Post) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		posts = append(posts, *p)
+		return false, nil
+	}
+	results, err := blog.BulkModify(ctx, func(tistory.PostSummary) bool { return true }, collect, &read)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+	}
+	return Count(posts), nil
+}
+
+// Count aggregates the tags of posts, most used first. Names are compared
+// exactly; Duplicates finds spellings of the same tag.
+func Count(posts []tistory.Post) []Tag {
+	byName := map[string]*Tag{}
+	for _, p := range posts {
+		seen := map[string]bool{}
+		for _, name := range p.Tags {
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			tag, ok := byName[name]
+			if !ok {
+				tag = &Tag{Name: name}
+				byName[name] = tag
+			}
+			tag.Count++
+			tag.PostIds = append(tag.PostIds, p.Id)
+		}
+	}
+
+	counts := make([]Tag, 0, len(byName))
+	for _, tag := range byName {
+		sort.Ints(tag.PostIds)
+		counts = append(counts, *tag)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
@@ -0,0 +1,105 @@
+package tags
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestDuplicates(t *testing.T) {
+	tags := []Tag{
+		{Name: "Go", Count: 5}, {Name: "golang", Count: 3}, {Name: "고랭", Count: 1},
+		{Name: "kubernetes", Count: 2}, {Name: "kubernets", Count: 1},
+		{Name: "python2", Count: 1}, {Name: "python3", Count: 1},
+		{Name: "Node.js", Count: 1}, {Name: "nodejs", Count: 2},
+		{Name: "travel", Count: 4},
+	}
+	var got [][]string
+	for _, g := range Duplicates(tags, DefaultAliases) {
+		got = append(got, append([]string{g.Canonical}, g.Names()...))
+	}
+	want := [][]string{
+		{"Go", "Go", "golang", "고랭"},
+		{"kubernetes", "kubernetes", "kubernets"},
+		{"nodejs", "nodejs", "Node.js"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Duplicates() = %q, want %q", got, want)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        []string
+		from        []string
+		into        string
+		want        []string
+		wantChanged bool
+	}{
+		{name: "rename", tags: []string{"a", "golang", "b"}, from: []string{"golang"}, into: "Go", want: []string{"a", "Go", "b"}, wantChanged: true},
+		{name: "merge", tags: []string{"golang", "a", "Go", "고랭"}, from: []string{"golang", "고랭"}, into: "Go", want: []string{"Go", "a"}, wantChanged: true},
+		{name: "delete", tags: []string{"a", "spam"}, from: []string{"spam"}, into: "", want: []string{"a"}, wantChanged: true},
+		{name: "untouched", tags: []string{"a", "b"}, from: []string{"c"}, into: "d", want: []string{"a", "b"}, wantChanged: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := Replace(tt.tags, tt.from, tt.into)
+			if !reflect.DeepEqual(got, tt.want) || changed != tt.wantChanged {
+				t.Errorf("Replace() = %q, %v, want %q, %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+	ctx := context.Background()
+	one := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "one", Tags: []string{"golang", "api"}})
+	two := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "two", Tags: []string{"Go", "고랭"}})
+	three := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "three", Tags: []string{"travel"}})
+
+	counts, err := Collect(ctx, blog, nil)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(counts) != 5 || counts[0].Name != "Go" || counts[0].PostIds[0] != two {
+		t.Fatalf("Collect() = %+v", counts)
+	}
+
+	results, err := Merge(ctx, blog, []string{"golang", "고랭"}, "Go", &tistory.BulkOptions{Interval: 0})
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	changed := 0
+	for _, r := range results {
+		if r.Changed {
+			changed++
+		}
+	}
+	if changed != 2 {
+		t.Errorf("Merge() changed %d posts, want 2", changed)
+	}
+	for id, want := range map[int]string{one: "Go,api", two: "Go", three: "travel"} {
+		if post, _ := srv.Post(tistorytest.DefaultBlog, id); strings.Join(post.Tags, ",") != want {
+			t.Errorf("post %d tags = %v, want %s", id, post.Tags, want)
+		}
+	}
+
+	if _, err := Delete(ctx, blog, []string{"travel"}, nil); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if post, _ := srv.Post(tistorytest.DefaultBlog, three); len(post.Tags) != 0 {
+		t.Errorf("post tags after Delete = %v", post.Tags)
+	}
+	if _, err := Rename(ctx, blog, "api", "", nil); err == nil {
+		t.Errorf("Rename() to an empty name error = nil, want error")
+	}
+}
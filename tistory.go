@@ -10,7 +10,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -39,6 +38,8 @@ const (
 
 	loginAfterURL = `https://www.tistory.com/`
 	output        = `json`
+
+	defaultBaseURL = `https://www.tistory.com`
 )
 
 type Tistory struct {
@@ -50,31 +51,72 @@ type Tistory struct {
 	AuthenticationURL  string
 	RedirectAuthURL    string
 	AuthenticationCode string
+
+	// BaseURL overrides the Tistory API host (default: https://www.tistory.com).
+	BaseURL string
+	// HTTPClient is used for API requests (default: http.DefaultClient).
+	HTTPClient *http.Client
+	// Revisions, when set, receives a snapshot of each post before ModifyPost.
+	Revisions RevisionStore
+	// AttachProcessor, when set, rewrites each file before AttachPost uploads
+	// it, e.g. imageopt.Optimizer resizing images.
+	AttachProcessor AttachProcessor
+}
+
+// AttachProcessor rewrites a file before AttachPost uploads it. It returns
+// the name and content to upload; a file it does not handle is returned
+// unchanged.
+type AttachProcessor interface {
+	ProcessAttachment(name string, data []byte) (string, []byte, error)
 }
 
 func NewTistory(blogURL, clientId, clientSecret string) (*Tistory, error) {
+	return NewTistoryWithBlogName(blogURL, "", clientId, clientSecret)
+}
+
+/*
+NewTistoryWithBlogName creates a Tistory for blogURL with an explicit blog name.
+When blogName is empty it is taken from a *.tistory.com URL, or resolved through
+blog/info on the first API call for a custom domain (e.g. https://blog.example.com).
+*/
+func NewTistoryWithBlogName(blogURL, blogName, clientId, clientSecret string) (*Tistory, error) {
 	if blogURL == "" || clientId == "" || clientSecret == "" {
 		return nil, errors.New("blogURL or clientId or clientSecret is empty")
 	}
 
-	if len(strings.Split(blogURL, "//")) < 2 {
-		return nil, errors.New("blogURL is invalid")
+	origin, name, err := parseBlogURL(blogURL)
+	if err != nil {
+		return nil, err
 	}
-
-	if !strings.HasPrefix(blogURL, "https://") {
-		return nil, errors.New("blogURL is invalid")
+	if blogName == "" {
+		blogName = name
 	}
 
 	return &Tistory{
-		BlogURL:      blogURL,
-		BlogName:     strings.Split(strings.Split(blogURL, "//")[1], ".")[0],
+		BlogURL:      origin,
+		BlogName:     blogName,
 		ClientId:     clientId,
 		ClientSecret: clientSecret,
 		AuthenticationURL: fmt.Sprintf(
-			"https://www.tistory.com/oauth/authorize?client_id=%s&redirect_uri=%s&response_type=code", clientId, blogURL),
+			"https://www.tistory.com/oauth/authorize?client_id=%s&redirect_uri=%s&response_type=code", clientId, origin),
 	}, nil
 }
 
+// endpoint returns the absolute URL of an API path such as "/apis/post/list".
+func (t *Tistory) endpoint(path string) string {
+	if t.BaseURL == "" {
+		return defaultBaseURL + path
+	}
+	return strings.TrimSuffix(t.BaseURL, "/") + path
+}
+
+func (t *Tistory) httpClient() *http.Client {
+	if t.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return t.HTTPClient
+}
+
 /*
 Login & Get AuthorizationCode
 https://tistory.github.io/document-tistory-apis/auth/authorization_code.html
@@ -168,17 +210,16 @@ func (t *Tistory) GetAccessToken() (string, error) {
 		"grant_type":    {"authorization_code"},
 	}
 
-	accessTokenURL := fmt.Sprintf(
-		"https://www.tistory.com/oauth/access_token?%s", params.Encode())
-	resp, err := http.Get(accessTokenURL)
+	accessTokenURL := t.endpoint("/oauth/access_token") + "?" + params.Encode()
+	resp, err := t.httpClient().Get(accessTokenURL)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
 
 	bodyString := string(respBytes)
@@ -203,17 +244,15 @@ output: 출력방식
 https://tistory.github.io/document-tistory-apis/apis/v1/blog/list.html
 */
 func (t *Tistory) GetBlogInfo() (map[string]interface{}, error) {
-	t.AccessToken = "ce196a9e476dd617519f2074286aa5c9_6a5d731ffc6022608f75393a7ea87cf4"
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
 	}
 
-	blogInfoURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/blog/info?%s", params.Encode())
-	resp, err := http.Get(blogInfoURL)
+	blogInfoURL := t.endpoint("/apis/blog/info") + "?" + params.Encode()
+	resp, err := t.httpClient().Get(blogInfoURL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -237,6 +276,10 @@ page: 불러올 페이지 번호
 https://tistory.github.io/document-tistory-apis/apis/v1/post/list.html
 */
 func (t *Tistory) GetPostList(pageNumber int) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -244,9 +287,8 @@ func (t *Tistory) GetPostList(pageNumber int) (map[string]interface{}, error) {
 		"page":         {fmt.Sprintf("%d", pageNumber)},
 	}
 
-	postListURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/post/list?%s", params.Encode())
-	resp, err := http.Get(postListURL)
+	postListURL := t.endpoint("/apis/post/list") + "?" + params.Encode()
+	resp, err := t.httpClient().Get(postListURL)
 	if err != nil {
 		return nil, err
 	}
@@ -271,18 +313,21 @@ postId: 글 번호 (필수)
 https://tistory.github.io/document-tistory-apis/apis/v1/post/read.html
 */
 func (t *Tistory) GetPost(postId int) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
 		"blogName":     {t.BlogName},
 		"postId":       {fmt.Sprintf("%d", postId)}}
 
-	postURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/post/read?%s", params.Encode())
+	postURL := t.endpoint("/apis/post/read") + "?" + params.Encode()
 
-	resp, err := http.Get(postURL)
+	resp, err := t.httpClient().Get(postURL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -313,6 +358,10 @@ password: 보호글 비밀번호
 https://tistory.github.io/document-tistory-apis/apis/v1/post/write.html
 */
 func (t *Tistory) WritePost(option map[string]interface{}) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -323,10 +372,10 @@ func (t *Tistory) WritePost(option map[string]interface{}) (map[string]interface
 		params.Add(key, fmt.Sprintf("%v", value))
 	}
 
-	writePostURL := "https://www.tistory.com/apis/post/write?"
-	resp, err := http.PostForm(writePostURL, params)
+	writePostURL := t.endpoint("/apis/post/write")
+	resp, err := t.httpClient().PostForm(writePostURL, params)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -355,9 +404,28 @@ slogan: 문자 주소
 tag: 태그 (',' 로 구분)
 acceptComment: 댓글 허용 (0, 1 - 기본값)
 password: 보호글 비밀번호
+Revisions 가 설정되어 있으면 수정 전 글을 revision 으로 저장합니다.
 https://tistory.github.io/document-tistory-apis/apis/v1/post/modify.html
 */
 func (t *Tistory) ModifyPost(option map[string]interface{}) (map[string]interface{}, error) {
+	return t.modifyPost(option, nil)
+}
+
+// modifyPost calls post/modify. before, if not nil, is the post as just read
+// by the caller; Revisions snapshots it instead of reading the post again.
+func (t *Tistory) modifyPost(option map[string]interface{}, before *Post) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+	var revision *Revision
+	if t.Revisions != nil {
+		r, err := t.snapshot(option, before)
+		if err != nil {
+			return nil, err
+		}
+		revision = &r
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -368,10 +436,10 @@ func (t *Tistory) ModifyPost(option map[string]interface{}) (map[string]interfac
 		params.Add(key, fmt.Sprintf("%v", value))
 	}
 
-	modifyPostURL := "https://www.tistory.com/apis/post/modify?"
-	resp, err := http.PostForm(modifyPostURL, params)
+	modifyPostURL := t.endpoint("/apis/post/modify")
+	resp, err := t.httpClient().PostForm(modifyPostURL, params)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -384,6 +452,12 @@ func (t *Tistory) ModifyPost(option map[string]interface{}) (map[string]interfac
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
 	}
+	// The post is only saved once it has really been replaced.
+	if revision != nil {
+		if _, err := t.Revisions.SaveRevision(*revision); err != nil {
+			return result, errors.Wrap(err, "Post modified, but failed to save its revision")
+		}
+	}
 	return result, nil
 }
 
@@ -391,30 +465,40 @@ func (t *Tistory) ModifyPost(option map[string]interface{}) (map[string]interfac
 AttachPost 파일 첨부
 blogName: Blog Name
 uploadedfile: 업로드할 파일 (multipart/form-data)
+AttachProcessor 가 설정되어 있으면 파일을 변환한 뒤 업로드합니다.
 https://tistory.github.io/document-tistory-apis/apis/v1/post/attach.html
 */
 func (t *Tistory) AttachPost(filePath string) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
 		"blogName":     {t.BlogName},
 	}
 
-	file, err := os.Open(filePath)
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed os open")
 	}
-	defer file.Close()
+	name := filePath
+	if t.AttachProcessor != nil {
+		if name, data, err = t.AttachProcessor.ProcessAttachment(name, data); err != nil {
+			return nil, errors.Wrapf(err, "Failed to process %s", filePath)
+		}
+	}
 
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	part, err := writer.CreateFormFile("uploadedfile", file.Name())
+	part, err := writer.CreateFormFile("uploadedfile", name)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed create form file")
 	}
 
-	_, err = io.Copy(part, file)
+	_, err = part.Write(data)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed io copy")
 	}
@@ -427,12 +511,11 @@ func (t *Tistory) AttachPost(filePath string) (map[string]interface{}, error) {
 
 	contentType := writer.FormDataContentType()
 	content := bytes.NewReader(body.Bytes())
-	attachPostURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/post/attach?%s", params.Encode())
+	attachPostURL := t.endpoint("/apis/post/attach") + "?" + params.Encode()
 
-	resp, err := http.Post(attachPostURL, contentType, content)
+	resp, err := t.httpClient().Post(attachPostURL, contentType, content)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -459,18 +542,21 @@ label: 부모 카테고리를 포함한 전체 이름 ('/'로 구분)
 entries: 카테고리내 글 수
 */
 func (t *Tistory) CategoryList() (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
 		"blogName":     {t.BlogName},
 	}
 
-	categoryListURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/category/list?%s", params.Encode())
+	categoryListURL := t.endpoint("/apis/category/list") + "?" + params.Encode()
 
-	resp, err := http.Get(categoryListURL)
+	resp, err := t.httpClient().Get(categoryListURL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -495,6 +581,10 @@ count: 페이지당 댓글 수 (기본값: 10, 최대값: 10)
 https://tistory.github.io/document-tistory-apis/apis/v1/comment/recent.html
 */
 func (t *Tistory) GetRecentCommentList(page, count int) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -503,12 +593,11 @@ func (t *Tistory) GetRecentCommentList(page, count int) (map[string]interface{},
 		"count":        {fmt.Sprintf("%d", count)},
 	}
 
-	getNewCommentListURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/comment/newest?%s", params.Encode())
+	getNewCommentListURL := t.endpoint("/apis/comment/newest") + "?" + params.Encode()
 
-	resp, err := http.Get(getNewCommentListURL)
+	resp, err := t.httpClient().Get(getNewCommentListURL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -532,6 +621,10 @@ postId: 글 번호 (ID)
 https://tistory.github.io/document-tistory-apis/apis/v1/comment/list.html
 */
 func (t *Tistory) GetCommentList(postId int) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -539,12 +632,11 @@ func (t *Tistory) GetCommentList(postId int) (map[string]interface{}, error) {
 		"postId":       {fmt.Sprintf("%d", postId)},
 	}
 
-	getCommentListURL := fmt.Sprintf(
-		"https://www.tistory.com/apis/comment/list?%s", params.Encode())
+	getCommentListURL := t.endpoint("/apis/comment/list") + "?" + params.Encode()
 
-	resp, err := http.Get(getCommentListURL)
+	resp, err := t.httpClient().Get(getCommentListURL)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -571,6 +663,10 @@ secret: 비밀 댓글 여부 (1: 비밀댓글, 0: 공개댓글 - 기본 값)
 https://tistory.github.io/document-tistory-apis/apis/v1/comment/write.html
 */
 func (t *Tistory) WriteComment(option map[string]interface{}) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -581,10 +677,10 @@ func (t *Tistory) WriteComment(option map[string]interface{}) (map[string]interf
 		params.Add(key, fmt.Sprintf("%v", value))
 	}
 
-	writeCommentURL := "https://www.tistory.com/apis/comment/write?"
-	resp, err := http.PostForm(writeCommentURL, params)
+	writeCommentURL := t.endpoint("/apis/comment/write")
+	resp, err := t.httpClient().PostForm(writeCommentURL, params)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -612,6 +708,10 @@ secret: 비밀 댓글 여부 (1: 비밀댓글, 0: 공개댓글 - 기본 값)
 https://tistory.github.io/document-tistory-apis/apis/v1/comment/modify.html
 */
 func (t *Tistory) ModifyComment(option map[string]interface{}) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -622,10 +722,10 @@ func (t *Tistory) ModifyComment(option map[string]interface{}) (map[string]inter
 		params.Add(key, fmt.Sprintf("%v", value))
 	}
 
-	modifyCommentURL := "https://www.tistory.com/apis/comment/modify?"
-	resp, err := http.PostForm(modifyCommentURL, params)
+	modifyCommentURL := t.endpoint("/apis/comment/modify")
+	resp, err := t.httpClient().PostForm(modifyCommentURL, params)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -650,6 +750,10 @@ commentId: 댓글 ID (필수)
 https://tistory.github.io/document-tistory-apis/apis/v1/comment/delete.html
 */
 func (t *Tistory) DeleteComment(option map[string]interface{}) (map[string]interface{}, error) {
+	if err := t.resolveBlogName(); err != nil {
+		return nil, err
+	}
+
 	params := url.Values{
 		"access_token": {t.AccessToken},
 		"output":       {output},
@@ -660,10 +764,10 @@ func (t *Tistory) DeleteComment(option map[string]interface{}) (map[string]inter
 		params.Add(key, fmt.Sprintf("%v", value))
 	}
 
-	deleteCommentURL := "https://www.tistory.com/apis/comment/delete?"
-	resp, err := http.PostForm(deleteCommentURL, params)
+	deleteCommentURL := t.endpoint("/apis/comment/delete")
+	resp, err := t.httpClient().PostForm(deleteCommentURL, params)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -1,19 +1,47 @@
 package tistory
 
 import (
-	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/LimJiAn/tistory-go/tistorytest"
 )
 
+func newTestTistory(srv *tistorytest.Server) *Tistory {
+	return &Tistory{
+		BlogURL:      "https://" + tistorytest.DefaultBlog + ".tistory.com",
+		BlogName:     tistorytest.DefaultBlog,
+		ClientId:     "client_id",
+		ClientSecret: "client_secret",
+		AccessToken:  srv.AccessToken,
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	}
+}
+
+// item returns result["tistory"]["item"].
+func item(t *testing.T, result map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	body, ok := result["tistory"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("missing tistory envelope: %v", result)
+	}
+	if body["status"] != "200" {
+		t.Fatalf("status = %v, want 200", body["status"])
+	}
+	if it, ok := body["item"].(map[string]interface{}); ok {
+		return it
+	}
+	return body
+}
+
 func TestTistory_GetAuthorizationCode(t *testing.T) {
 	type fields struct {
-		BlogURL            string
-		ClientId           string
-		ClientSecret       string
-		AccessToken        string
-		AuthenticationURL  string
-		RedirectAuthURL    string
-		AuthenticationCode string
+		BlogURL      string
+		ClientId     string
+		ClientSecret string
 	}
 	type args struct {
 		id       string
@@ -23,22 +51,8 @@ func TestTistory_GetAuthorizationCode(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		want    string
 		wantErr bool
 	}{
-		{
-			name: "valid credentials",
-			fields: fields{
-				BlogURL:      "https://example.com",
-				ClientId:     "client_id",
-				ClientSecret: "client_secret",
-			},
-			args: args{
-				id:       "username",
-				password: "password",
-			},
-			wantErr: false,
-		},
 		{
 			name: "empty credentials",
 			fields: fields{
@@ -56,147 +70,230 @@ func TestTistory_GetAuthorizationCode(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tst := &Tistory{
-				BlogURL:            tt.fields.BlogURL,
-				ClientId:           tt.fields.ClientId,
-				ClientSecret:       tt.fields.ClientSecret,
-				AccessToken:        tt.fields.AccessToken,
-				AuthenticationURL:  tt.fields.AuthenticationURL,
-				RedirectAuthURL:    tt.fields.RedirectAuthURL,
-				AuthenticationCode: tt.fields.AuthenticationCode,
+				BlogURL:      tt.fields.BlogURL,
+				ClientId:     tt.fields.ClientId,
+				ClientSecret: tt.fields.ClientSecret,
 			}
-			got, err := tst.GetAuthorizationCode(tt.args.id, tt.args.password)
+			_, err := tst.GetAuthorizationCode(tt.args.id, tt.args.password)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Tistory.GetAuthorizationCode() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if len(got) == 0 {
-				t.Errorf("Tistory.GetAuthorizationCode() = %v, want non-empty string", got)
 			}
 		})
 	}
 }
 
 func TestTistory_GetAccessToken(t *testing.T) {
-	type fields struct {
-		BlogURL            string
-		ClientId           string
-		ClientSecret       string
-		AccessToken        string
-		AuthenticationURL  string
-		RedirectAuthURL    string
-		AuthenticationCode string
-	}
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+
 	tests := []struct {
-		name    string
-		fields  fields
-		want    string
-		wantErr bool
+		name               string
+		authenticationCode string
+		wantErr            bool
 	}{
 		{
-			name: "valid credentials",
-			fields: fields{
-				BlogURL:            "https://example.com",
-				ClientId:           "client_id",
-				ClientSecret:       "client_secret",
-				AuthenticationCode: "authentication_code",
-			},
-			wantErr: false,
+			name:               "valid credentials",
+			authenticationCode: "authentication_code",
+			wantErr:            false,
 		},
 		{
-			name: "invalid credentials",
-			fields: fields{
-				BlogURL:            "https://example.com",
-				ClientId:           "client_id",
-				ClientSecret:       "client_secret",
-				AuthenticationCode: "",
-			},
-			wantErr: true,
+			name:               "invalid credentials",
+			authenticationCode: "",
+			wantErr:            true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tst := &Tistory{
-				BlogURL:            tt.fields.BlogURL,
-				ClientId:           tt.fields.ClientId,
-				ClientSecret:       tt.fields.ClientSecret,
-				AccessToken:        tt.fields.AccessToken,
-				AuthenticationURL:  tt.fields.AuthenticationURL,
-				RedirectAuthURL:    tt.fields.RedirectAuthURL,
-				AuthenticationCode: tt.fields.AuthenticationCode,
-			}
+			tst := newTestTistory(srv)
+			tst.AccessToken = ""
+			tst.AuthenticationCode = tt.authenticationCode
 			got, err := tst.GetAccessToken()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Tistory.GetAccessToken() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if len(got) == 0 {
-				t.Errorf("Tistory.GetAccessToken() = %v, want non-empty string", got)
+			if !tt.wantErr && got != srv.AccessToken {
+				t.Errorf("Tistory.GetAccessToken() = %v, want %v", got, srv.AccessToken)
 			}
 		})
 	}
 }
 
 func TestTistory_GetBlogInfo(t *testing.T) {
-	type fields struct {
-		BlogURL            string
-		ClientId           string
-		ClientSecret       string
-		AccessToken        string
-		AuthenticationURL  string
-		RedirectAuthURL    string
-		AuthenticationCode string
-	}
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+
 	tests := []struct {
-		name    string
-		fields  fields
-		want    string
-		wantErr bool
+		name        string
+		accessToken string
+		wantErr     bool
 	}{
 		{
-			name: "valid credentials",
-			fields: fields{
-				BlogURL:      "https://example.com",
-				ClientId:     "client_id",
-				ClientSecret: "client_secret",
-				AccessToken:  "access_token",
-				AuthenticationURL: fmt.Sprintf(
-					"https://www.tistory.com/oauth/authorize?client_id=%s&redirect_uri=%s&response_type=code", "client_id", "https://example.com"),
-			},
-			wantErr: false,
+			name:        "valid credentials",
+			accessToken: srv.AccessToken,
+			wantErr:     false,
 		},
 		{
-			name: "invalid credentials",
-			fields: fields{
-				BlogURL:            "https://example.com",
-				ClientId:           "client_id",
-				ClientSecret:       "client_secret",
-				AccessToken:        "",
-				AuthenticationURL:  "",
-				RedirectAuthURL:    "",
-				AuthenticationCode: "",
-			},
-			wantErr: true,
+			name:        "invalid credentials",
+			accessToken: "",
+			wantErr:     true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tst := &Tistory{
-				BlogURL:            tt.fields.BlogURL,
-				ClientId:           tt.fields.ClientId,
-				ClientSecret:       tt.fields.ClientSecret,
-				AccessToken:        tt.fields.AccessToken,
-				AuthenticationURL:  tt.fields.AuthenticationURL,
-				RedirectAuthURL:    tt.fields.RedirectAuthURL,
-				AuthenticationCode: tt.fields.AuthenticationCode,
-			}
+			tst := newTestTistory(srv)
+			tst.AccessToken = tt.accessToken
 			got, err := tst.GetBlogInfo()
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Tistory.GetBlogInfo() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if len(got) == 0 {
-				t.Errorf("Tistory.GetBlogInfo() = %v, want non-empty string", got)
+			if tt.wantErr {
+				return
+			}
+			blogs, _ := item(t, got)["blogs"].([]interface{})
+			if len(blogs) != 1 {
+				t.Errorf("Tistory.GetBlogInfo() blogs = %v, want 1 blog", blogs)
 			}
 		})
 	}
 }
+
+func TestTistory_Posts(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	categoryId := srv.AddCategory(tistorytest.DefaultBlog, tistorytest.Category{Name: "Go"})
+
+	written, err := tst.WritePost(map[string]interface{}{
+		"title": "title", "content": "content", "visibility": "3", "category": categoryId, "tag": "go,api"})
+	if err != nil {
+		t.Fatalf("Tistory.WritePost() error = %v", err)
+	}
+	postId := item(t, written)["postId"].(string)
+
+	if _, err := tst.ModifyPost(map[string]interface{}{
+		"postId": postId, "title": "modified", "content": "content", "visibility": "3"}); err != nil {
+		t.Fatalf("Tistory.ModifyPost() error = %v", err)
+	}
+	if _, err := tst.ModifyPost(map[string]interface{}{"postId": "999", "title": "title"}); err == nil {
+		t.Errorf("Tistory.ModifyPost() on missing post error = nil, want error")
+	}
+
+	list, err := tst.GetPostList(1)
+	if err != nil {
+		t.Fatalf("Tistory.GetPostList() error = %v", err)
+	}
+	posts := item(t, list)["posts"].([]interface{})
+	if len(posts) != 1 || posts[0].(map[string]interface{})["title"] != "modified" {
+		t.Errorf("Tistory.GetPostList() posts = %v", posts)
+	}
+
+	post, err := tst.GetPost(srv.Posts(tistorytest.DefaultBlog)[0].ID)
+	if err != nil {
+		t.Fatalf("Tistory.GetPost() error = %v", err)
+	}
+	if got := item(t, post)["visibility"]; got != "20" {
+		t.Errorf("Tistory.GetPost() visibility = %v, want 20", got)
+	}
+	if _, err := tst.GetPost(999); err == nil {
+		t.Errorf("Tistory.GetPost() on missing post error = nil, want error")
+	}
+
+	categories, err := tst.CategoryList()
+	if err != nil {
+		t.Fatalf("Tistory.CategoryList() error = %v", err)
+	}
+	if got := item(t, categories)["categories"].([]interface{}); len(got) != 1 {
+		t.Errorf("Tistory.CategoryList() categories = %v, want 1", got)
+	}
+}
+
+func TestTistory_AttachPost(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	filePath := filepath.Join(t.TempDir(), "test.png")
+	if err := os.WriteFile(filePath, []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tst.AttachPost(filePath)
+	if err != nil {
+		t.Fatalf("Tistory.AttachPost() error = %v", err)
+	}
+	if item(t, got)["replacer"] == "" {
+		t.Errorf("Tistory.AttachPost() = %v, want replacer", got)
+	}
+	if files := srv.Attachments(tistorytest.DefaultBlog); len(files) != 1 || string(files[0].Data) != "png" {
+		t.Errorf("uploaded files = %v", files)
+	}
+
+	if _, err := tst.AttachPost(filepath.Join(t.TempDir(), "missing.png")); err == nil {
+		t.Errorf("Tistory.AttachPost() on missing file error = nil, want error")
+	}
+
+	tst.AttachProcessor = jpegProcessor{}
+	if _, err := tst.AttachPost(filePath); err != nil {
+		t.Fatalf("Tistory.AttachPost() with AttachProcessor error = %v", err)
+	}
+	if files := srv.Attachments(tistorytest.DefaultBlog); len(files) != 2 || files[1].Name != "test.jpg" || string(files[1].Data) != "jpeg" {
+		t.Errorf("processed upload = %+v", files[len(files)-1])
+	}
+}
+
+// jpegProcessor pretends to convert every file to JPEG.
+type jpegProcessor struct{}
+
+func (jpegProcessor) ProcessAttachment(name string, data []byte) (string, []byte, error) {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".jpg", []byte("jpeg"), nil
+}
+
+func TestTistory_Comments(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	tst := newTestTistory(srv)
+
+	postId := srv.AddPost(tistorytest.DefaultBlog, tistorytest.Post{Title: "title"})
+	parentId := srv.AddComment(tistorytest.DefaultBlog, tistorytest.Comment{PostID: postId, Name: "reader", Content: "question"})
+
+	if _, err := tst.WriteComment(map[string]interface{}{
+		"postId": postId, "parentId": parentId, "content": "answer"}); err != nil {
+		t.Fatalf("Tistory.WriteComment() error = %v", err)
+	}
+
+	list, err := tst.GetCommentList(postId)
+	if err != nil {
+		t.Fatalf("Tistory.GetCommentList() error = %v", err)
+	}
+	if got := item(t, list)["totalCount"]; got != "2" {
+		t.Errorf("Tistory.GetCommentList() totalCount = %v, want 2", got)
+	}
+
+	recent, err := tst.GetRecentCommentList(1, 1)
+	if err != nil {
+		t.Fatalf("Tistory.GetRecentCommentList() error = %v", err)
+	}
+	comments := item(t, recent)["comments"].(map[string]interface{})["comment"].([]interface{})
+	if len(comments) != 1 || comments[0].(map[string]interface{})["comment"] != "answer" {
+		t.Errorf("Tistory.GetRecentCommentList() = %v", comments)
+	}
+
+	if _, err := tst.ModifyComment(map[string]interface{}{
+		"postId": postId, "commentId": parentId, "content": "edited"}); err != nil {
+		t.Fatalf("Tistory.ModifyComment() error = %v", err)
+	}
+	if got := srv.Comments(tistorytest.DefaultBlog, postId)[0].Content; got != "edited" {
+		t.Errorf("comment content = %v, want edited", got)
+	}
+
+	if _, err := tst.DeleteComment(map[string]interface{}{
+		"postId": postId, "commentId": parentId}); err != nil {
+		t.Fatalf("Tistory.DeleteComment() error = %v", err)
+	}
+	if got := srv.Comments(tistorytest.DefaultBlog, postId); len(got) != 0 {
+		t.Errorf("comments after delete = %v, want none", got)
+	}
+}
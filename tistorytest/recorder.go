@@ -0,0 +1,300 @@
+package tistorytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mode selects whether a Recorder talks to the network or to its cassette.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never hits the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to Transport and records the responses.
+	ModeRecord
+)
+
+// Redacted replaces scrubbed secrets in cassettes.
+const Redacted = "REDACTED"
+
+// scrubbedParams are removed from recorded requests and ignored when matching.
+var scrubbedParams = []string{"access_token", "client_secret"}
+
+var accessTokenBody = regexp.MustCompile(`access_token=[^&\s]+`)
+
+// Cassette is the on-disk format of recorded interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest holds the parts of a request used for matching.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// Form is the normalized query and body parameters (see NormalizeForm).
+	Form string `json:"form"`
+}
+
+// RecordedResponse is a replayable HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that records Tistory responses to a
+// cassette file once and replays them afterwards.
+//
+//	rec, err := tistorytest.NewRecorder("testdata/posts.json", tistorytest.ModeReplay)
+//	t := &tistory.Tistory{HTTPClient: rec.Client(), ...}
+//	defer rec.Save()
+type Recorder struct {
+	// Transport performs real requests in ModeRecord (default: http.DefaultTransport).
+	Transport http.RoundTripper
+
+	mode     Mode
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// NewRecorder opens the cassette at path. In ModeReplay the cassette must exist;
+// in ModeRecord any existing interactions are discarded.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path}
+	if mode == ModeRecord {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("tistorytest: read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, fmt.Errorf("tistorytest: parse cassette %s: %w", path, err)
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// RecordModeFromEnv returns ModeRecord when the TISTORY_RECORD environment
+// variable is set to a non-empty value, ModeReplay otherwise.
+func RecordModeFromEnv() Mode {
+	if os.Getenv("TISTORY_RECORD") != "" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// Client returns an http.Client using the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Save writes recorded interactions to the cassette file. It is a no-op in ModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, body, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	// recordRequest consumed the body; hand the transport a fresh copy.
+	outgoing := req.Clone(req.Context())
+	if body != nil {
+		outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       scrubBody(string(respBody)),
+		},
+	})
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// replay returns the first unused interaction matching req. Once every match
+// has been used the last one is served again, so polling loops keep working.
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, in := range r.cassette.Interactions {
+		if in.Request != recorded {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return newResponse(req, in.Response), nil
+		}
+	}
+	if last >= 0 {
+		return newResponse(req, r.cassette.Interactions[last].Response), nil
+	}
+	return nil, fmt.Errorf("tistorytest: no recorded interaction for %s %s?%s in %s",
+		recorded.Method, recorded.Path, recorded.Form, r.path)
+}
+
+func newResponse(req *http.Request, rec RecordedResponse) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        rec.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}
+}
+
+// recordRequest builds the matching key for req and returns the consumed body.
+func recordRequest(req *http.Request) (RecordedRequest, []byte, error) {
+	params := url.Values{}
+	for key, values := range req.URL.Query() {
+		params[key] = append(params[key], values...)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return RecordedRequest{}, nil, err
+		}
+		if err := addBodyParams(params, req.Header.Get("Content-Type"), body); err != nil {
+			return RecordedRequest{}, nil, err
+		}
+	}
+
+	return RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Form:   NormalizeForm(params),
+	}, body, nil
+}
+
+func addBodyParams(params url.Values, contentType string, body []byte) error {
+	mediaType, mediaParams, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return err
+		}
+		for key, values := range form {
+			params[key] = append(params[key], values...)
+		}
+	case "multipart/form-data":
+		mr := multipart.NewReader(bytes.NewReader(body), mediaParams["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			// Files are matched by base name only; their bytes are not part of the key.
+			if part.FileName() != "" {
+				params.Add(part.FormName(), filepath.Base(part.FileName()))
+				continue
+			}
+			value, err := io.ReadAll(part)
+			if err != nil {
+				return err
+			}
+			params.Add(part.FormName(), string(value))
+		}
+	}
+	return nil
+}
+
+// NormalizeForm encodes params with sorted keys and values after replacing
+// secrets with Redacted, so equivalent requests built from maps compare equal.
+func NormalizeForm(params url.Values) string {
+	normalized := url.Values{}
+	for key, values := range params {
+		values = append([]string(nil), values...)
+		sort.Strings(values)
+		normalized[key] = values
+	}
+	for _, key := range scrubbedParams {
+		if _, ok := normalized[key]; ok {
+			normalized[key] = []string{Redacted}
+		}
+	}
+	return normalized.Encode()
+}
+
+func scrubBody(body string) string {
+	return accessTokenBody.ReplaceAllString(body, "access_token="+Redacted)
+}
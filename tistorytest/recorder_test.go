@@ -0,0 +1,90 @@
+package tistorytest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestRecorder_RecordReplay(t *testing.T) {
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	option := map[string]interface{}{
+		"title": "title", "content": "content", "visibility": "3", "tag": "go,api", "slogan": "hello"}
+
+	// Record against the fake server.
+	srv := tistorytest.NewServer()
+	rec, err := tistorytest.NewRecorder(cassette, tistorytest.ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Transport = srv.Client().Transport
+	recording := &tistory.Tistory{
+		BlogName:    tistorytest.DefaultBlog,
+		AccessToken: srv.AccessToken,
+		BaseURL:     srv.URL,
+		HTTPClient:  rec.Client(),
+	}
+	if _, err := recording.WritePost(option); err != nil {
+		t.Fatalf("record WritePost() error = %v", err)
+	}
+	if _, err := recording.GetPostList(1); err != nil {
+		t.Fatalf("record GetPostList() error = %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), srv.AccessToken) {
+		t.Errorf("cassette contains the access token:\n%s", data)
+	}
+
+	// Replay with the server gone and a different token.
+	rep, err := tistorytest.NewRecorder(cassette, tistorytest.ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaying := &tistory.Tistory{
+		BlogName:    tistorytest.DefaultBlog,
+		AccessToken: "another-token",
+		BaseURL:     srv.URL,
+		HTTPClient:  rep.Client(),
+	}
+	// Map iteration order differs between runs; the form is normalized.
+	for i := 0; i < 3; i++ {
+		got, err := replaying.WritePost(option)
+		if err != nil {
+			t.Fatalf("replay WritePost() error = %v", err)
+		}
+		if got["tistory"].(map[string]interface{})["postId"] == "" {
+			t.Errorf("replay WritePost() = %v", got)
+		}
+	}
+	if _, err := replaying.GetPostList(1); err != nil {
+		t.Fatalf("replay GetPostList() error = %v", err)
+	}
+	if _, err := replaying.GetPostList(2); err == nil {
+		t.Errorf("replay of unrecorded request error = nil, want error")
+	}
+}
+
+func TestNormalizeForm(t *testing.T) {
+	got := tistorytest.NormalizeForm(map[string][]string{
+		"tag":           {"b", "a"},
+		"access_token":  {"secret"},
+		"client_secret": {"secret"},
+		"blogName":      {"test"},
+	})
+	want := "access_token=REDACTED&blogName=test&client_secret=REDACTED&tag=a&tag=b"
+	if got != want {
+		t.Errorf("NormalizeForm() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,820 @@
+// Package tistorytest provides an in-memory Tistory API server for tests.
+//
+// The server speaks the same JSON envelopes as https://www.tistory.com/apis
+// so code built on the tistory package can run without network access:
+//
+//	srv := tistorytest.NewServer()
+//	defer srv.Close()
+//
+//	t := &tistory.Tistory{
+//		BaseURL:     srv.URL,
+//		HTTPClient:  srv.Client(),
+//		AccessToken: srv.AccessToken,
+//		BlogName:    tistorytest.DefaultBlog,
+//	}
+package tistorytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBlog is the blog created by NewServer.
+	DefaultBlog = "test"
+	// DefaultAccessToken is the token accepted by a new Server.
+	DefaultAccessToken = "test-access-token"
+
+	postDateLayout = "2006-01-02 15:04:05"
+	postsPerPage   = 10
+)
+
+// kst is the time zone of post dates returned by Tistory.
+var kst = time.FixedZone("KST", 9*60*60)
+
+// Post is a post stored by the fake server.
+type Post struct {
+	ID            int
+	Title         string
+	Content       string
+	Visibility    int // 0: 비공개, 1: 보호, 3: 발행
+	CategoryID    int
+	Tags          []string
+	Slogan        string
+	AcceptComment bool
+	Password      string
+	Published     time.Time
+}
+
+// Category is a category stored by the fake server.
+type Category struct {
+	ID     int
+	Name   string
+	Parent int
+}
+
+// Comment is a comment stored by the fake server.
+type Comment struct {
+	ID       int
+	PostID   int
+	ParentID int
+	Name     string
+	Homepage string
+	Content  string
+	Secret   bool
+	Date     time.Time
+}
+
+// Attachment is a file received through post/attach.
+type Attachment struct {
+	Name string
+	Data []byte
+}
+
+type blog struct {
+	name         string
+	secondaryURL string
+	posts        map[int]*Post
+	categories   map[int]*Category
+	comments     map[int]*Comment
+	attachments  []Attachment
+}
+
+// Server is an httptest.Server implementing the Tistory Open API.
+type Server struct {
+	*httptest.Server
+
+	// AccessToken is the only token the server accepts.
+	AccessToken string
+	// Owner is the comment author name used for comments written via the API.
+	Owner string
+
+	mu     sync.Mutex
+	blogs  map[string]*blog
+	order  []string
+	nextID int
+	now    func() time.Time
+}
+
+// NewServer starts a fake server with an empty DefaultBlog.
+// The caller must call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		AccessToken: DefaultAccessToken,
+		Owner:       "owner",
+		blogs:       map[string]*blog{},
+		nextID:      1,
+		now:         time.Now,
+	}
+	s.AddBlog(DefaultBlog)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/access_token", s.handleAccessToken)
+	mux.HandleFunc("/apis/blog/info", s.auth(s.handleBlogInfo))
+	mux.HandleFunc("/apis/post/list", s.auth(s.blog(s.handlePostList)))
+	mux.HandleFunc("/apis/post/read", s.auth(s.blog(s.handlePostRead)))
+	mux.HandleFunc("/apis/post/write", s.auth(s.blog(s.handlePostWrite)))
+	mux.HandleFunc("/apis/post/modify", s.auth(s.blog(s.handlePostModify)))
+	mux.HandleFunc("/apis/post/attach", s.auth(s.blog(s.handlePostAttach)))
+	mux.HandleFunc("/apis/category/list", s.auth(s.blog(s.handleCategoryList)))
+	mux.HandleFunc("/apis/comment/newest", s.auth(s.blog(s.handleCommentNewest)))
+	mux.HandleFunc("/apis/comment/list", s.auth(s.blog(s.handleCommentList)))
+	mux.HandleFunc("/apis/comment/write", s.auth(s.blog(s.handleCommentWrite)))
+	mux.HandleFunc("/apis/comment/modify", s.auth(s.blog(s.handleCommentModify)))
+	mux.HandleFunc("/apis/comment/delete", s.auth(s.blog(s.handleCommentDelete)))
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetClock replaces the clock used for generated dates.
+func (s *Server) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// AddBlog registers an empty blog owned by the token's account.
+func (s *Server) AddBlog(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blogs[name]; ok {
+		return
+	}
+	s.blogs[name] = &blog{
+		name:       name,
+		posts:      map[int]*Post{},
+		categories: map[int]*Category{},
+		comments:   map[int]*Comment{},
+	}
+	s.order = append(s.order, name)
+}
+
+// SetSecondaryURL sets the custom domain URL (e.g. https://blog.example.com)
+// reported as secondaryUrl by blog/info.
+func (s *Server) SetSecondaryURL(blogName, secondaryURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mustBlog(blogName).secondaryURL = secondaryURL
+}
+
+// AddPost stores p in blogName and returns its ID. A zero p.ID is assigned.
+func (s *Server) AddPost(blogName string, p Post) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.mustBlog(blogName)
+	if p.ID == 0 {
+		p.ID = s.newID()
+	}
+	if p.Published.IsZero() {
+		p.Published = s.now()
+	}
+	b.posts[p.ID] = &p
+	return p.ID
+}
+
+// AddCategory stores c in blogName and returns its ID. A zero c.ID is assigned.
+func (s *Server) AddCategory(blogName string, c Category) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.mustBlog(blogName)
+	if c.ID == 0 {
+		c.ID = s.newID()
+	}
+	b.categories[c.ID] = &c
+	return c.ID
+}
+
+// AddComment stores c in blogName and returns its ID. A zero c.ID is assigned.
+func (s *Server) AddComment(blogName string, c Comment) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.mustBlog(blogName)
+	if c.ID == 0 {
+		c.ID = s.newID()
+	}
+	if c.Date.IsZero() {
+		c.Date = s.now()
+	}
+	b.comments[c.ID] = &c
+	return c.ID
+}
+
+// Post returns a copy of a stored post.
+func (s *Server) Post(blogName string, id int) (Post, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.mustBlog(blogName).posts[id]
+	if !ok {
+		return Post{}, false
+	}
+	return *p, true
+}
+
+// Posts returns copies of all posts in blogName ordered by ID.
+func (s *Server) Posts(blogName string) []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.mustBlog(blogName)
+	posts := make([]Post, 0, len(b.posts))
+	for _, p := range b.posts {
+		posts = append(posts, *p)
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID < posts[j].ID })
+	return posts
+}
+
+// Comments returns copies of the comments of postID ordered by ID.
+func (s *Server) Comments(blogName string, postID int) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyComments(s.mustBlog(blogName).commentsOf(postID))
+}
+
+// Attachments returns the files uploaded to blogName.
+func (s *Server) Attachments(blogName string) []Attachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attachment(nil), s.mustBlog(blogName).attachments...)
+}
+
+func (s *Server) mustBlog(name string) *blog {
+	b, ok := s.blogs[name]
+	if !ok {
+		panic(fmt.Sprintf("tistorytest: unknown blog %q", name))
+	}
+	return b
+}
+
+func (s *Server) newID() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func (s *Server) blogURL(name string) string {
+	return fmt.Sprintf("https://%s.tistory.com", name)
+}
+
+func (b *blog) commentsOf(postID int) []*Comment {
+	var comments []*Comment
+	for _, c := range b.comments {
+		if c.PostID == postID {
+			comments = append(comments, c)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].ID < comments[j].ID })
+	return comments
+}
+
+func copyComments(in []*Comment) []Comment {
+	out := make([]Comment, len(in))
+	for i, c := range in {
+		out[i] = *c
+	}
+	return out
+}
+
+// response writers
+
+func writeJSON(w http.ResponseWriter, status int, body map[string]interface{}) {
+	body["status"] = strconv.Itoa(status)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"tistory": body})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error_message": message})
+}
+
+// middleware
+
+type blogHandler func(w http.ResponseWriter, r *http.Request, b *blog)
+
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := parseForm(r); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if r.Form.Get("access_token") != s.AccessToken {
+			writeError(w, http.StatusUnauthorized, "access_token 이 유효하지 않습니다.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) blog(next blogHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		b, ok := s.blogs[r.Form.Get("blogName")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "블로그 정보가 존재하지 않습니다.")
+			return
+		}
+		next(w, r, b)
+	}
+}
+
+func parseForm(r *http.Request) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.ParseMultipartForm(32 << 20)
+	}
+	return r.ParseForm()
+}
+
+func formInt(r *http.Request, key string) (int, error) {
+	v := r.Form.Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s 값이 올바르지 않습니다.", key)
+	}
+	return n, nil
+}
+
+// visibilityCode maps a write visibility (0, 1, 3) to the code returned by
+// post/list and post/read (0, 15, 20).
+func visibilityCode(v int) string {
+	switch v {
+	case 1:
+		return "15"
+	case 3:
+		return "20"
+	default:
+		return "0"
+	}
+}
+
+func boolFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// handlers
+
+func (s *Server) handleAccessToken(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	if r.Form.Get("code") == "" || r.Form.Get("client_id") == "" || r.Form.Get("client_secret") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, "error=invalid_request&error_description=missing+code")
+		return
+	}
+	io.WriteString(w, "access_token="+s.AccessToken)
+}
+
+func (s *Server) handleBlogInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	blogs := make([]map[string]interface{}, 0, len(s.order))
+	for i, name := range s.order {
+		b := s.blogs[name]
+		comments := len(b.comments)
+		def := "N"
+		if i == 0 {
+			def = "Y"
+		}
+		blogs = append(blogs, map[string]interface{}{
+			"name":         name,
+			"url":          s.blogURL(name),
+			"secondaryUrl": b.secondaryURL,
+			"nickname":     s.Owner,
+			"title":        name,
+			"description":  "",
+			"default":      def,
+			"role":         "소유자",
+			"blogId":       strconv.Itoa(i + 1),
+			"statistics": map[string]interface{}{
+				"post":    strconv.Itoa(len(b.posts)),
+				"comment": strconv.Itoa(comments),
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"item": map[string]interface{}{
+			"id":     s.Owner + "@tistory.test",
+			"userId": "1",
+			"blogs":  blogs,
+		},
+	})
+}
+
+func (s *Server) handlePostList(w http.ResponseWriter, r *http.Request, b *blog) {
+	page, err := formInt(r, "page")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	ids := make([]int, 0, len(b.posts))
+	for id := range b.posts {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+	posts := []map[string]interface{}{}
+	for i := (page - 1) * postsPerPage; i < len(ids) && i < page*postsPerPage; i++ {
+		p := b.posts[ids[i]]
+		posts = append(posts, map[string]interface{}{
+			"id":         strconv.Itoa(p.ID),
+			"title":      p.Title,
+			"postUrl":    fmt.Sprintf("%s/%d", s.blogURL(b.name), p.ID),
+			"visibility": visibilityCode(p.Visibility),
+			"categoryId": strconv.Itoa(p.CategoryID),
+			"comments":   strconv.Itoa(len(b.commentsOf(p.ID))),
+			"trackbacks": "0",
+			"date":       p.Published.In(kst).Format(postDateLayout),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"item": map[string]interface{}{
+			"url":          s.blogURL(b.name),
+			"secondaryUrl": "",
+			"page":         strconv.Itoa(page),
+			"count":        strconv.Itoa(len(posts)),
+			"totalCount":   strconv.Itoa(len(ids)),
+			"posts":        posts,
+		},
+	})
+}
+
+func (s *Server) handlePostRead(w http.ResponseWriter, r *http.Request, b *blog) {
+	id, err := formInt(r, "postId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p, ok := b.posts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "게시글이 존재하지 않습니다.")
+		return
+	}
+
+	tags := append([]string{}, p.Tags...)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"item": map[string]interface{}{
+			"url":             s.blogURL(b.name),
+			"secondaryUrl":    "",
+			"id":              strconv.Itoa(p.ID),
+			"title":           p.Title,
+			"content":         p.Content,
+			"categoryId":      strconv.Itoa(p.CategoryID),
+			"postUrl":         fmt.Sprintf("%s/%d", s.blogURL(b.name), p.ID),
+			"visibility":      visibilityCode(p.Visibility),
+			"acceptComment":   boolFlag(p.AcceptComment),
+			"acceptTrackback": "1",
+			"tags":            map[string]interface{}{"tag": tags},
+			"comments":        strconv.Itoa(len(b.commentsOf(p.ID))),
+			"trackbacks":      "0",
+			"date":            p.Published.In(kst).Format(postDateLayout),
+		},
+	})
+}
+
+// applyPostForm copies post/write and post/modify parameters onto p.
+func (s *Server) applyPostForm(r *http.Request, b *blog, p *Post) error {
+	p.Title = r.Form.Get("title")
+	if p.Title == "" {
+		return fmt.Errorf("title 이 없습니다.")
+	}
+	p.Content = r.Form.Get("content")
+	p.Slogan = r.Form.Get("slogan")
+	p.Password = r.Form.Get("password")
+	p.Tags = nil
+	for _, tag := range strings.Split(r.Form.Get("tag"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			p.Tags = append(p.Tags, tag)
+		}
+	}
+
+	var err error
+	if p.Visibility, err = formInt(r, "visibility"); err != nil {
+		return err
+	}
+	if p.CategoryID, err = formInt(r, "category"); err != nil {
+		return err
+	}
+	if p.CategoryID != 0 {
+		if _, ok := b.categories[p.CategoryID]; !ok {
+			return fmt.Errorf("카테고리가 존재하지 않습니다.")
+		}
+	}
+
+	p.AcceptComment = r.Form.Get("acceptComment") != "0"
+
+	p.Published = s.now()
+	if published := r.Form.Get("published"); published != "" {
+		ts, err := strconv.ParseInt(published, 10, 64)
+		if err != nil {
+			return fmt.Errorf("published 값이 올바르지 않습니다.")
+		}
+		p.Published = time.Unix(ts, 0)
+	}
+	return nil
+}
+
+func (s *Server) handlePostWrite(w http.ResponseWriter, r *http.Request, b *blog) {
+	p := &Post{}
+	if err := s.applyPostForm(r, b, p); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p.ID = s.newID()
+	b.posts[p.ID] = p
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"postId": strconv.Itoa(p.ID),
+		"url":    fmt.Sprintf("%s/%d", s.blogURL(b.name), p.ID),
+	})
+}
+
+func (s *Server) handlePostModify(w http.ResponseWriter, r *http.Request, b *blog) {
+	id, err := formInt(r, "postId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p, ok := b.posts[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "게시글이 존재하지 않습니다.")
+		return
+	}
+
+	updated := *p
+	if err := s.applyPostForm(r, b, &updated); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	*p = updated
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"postId": strconv.Itoa(p.ID),
+		"url":    fmt.Sprintf("%s/%d", s.blogURL(b.name), p.ID),
+	})
+}
+
+func (s *Server) handlePostAttach(w http.ResponseWriter, r *http.Request, b *blog) {
+	if r.MultipartForm == nil || len(r.MultipartForm.File["uploadedfile"]) == 0 {
+		writeError(w, http.StatusBadRequest, "uploadedfile 이 없습니다.")
+		return
+	}
+	header := r.MultipartForm.File["uploadedfile"][0]
+	file, err := header.Open()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b.attachments = append(b.attachments, Attachment{Name: header.Filename, Data: data})
+
+	key := fmt.Sprintf("%d", s.newID())
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"url":      fmt.Sprintf("https://cfile.tistory.test/image/%s", key),
+		"replacer": fmt.Sprintf("[##_1N|cfile@%s|width=\"0\" height=\"0\" filename=\"%s\" filemime=\"\"|_##]", key, header.Filename),
+	})
+}
+
+func (s *Server) handleCategoryList(w http.ResponseWriter, r *http.Request, b *blog) {
+	ids := make([]int, 0, len(b.categories))
+	for id := range b.categories {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	entries := map[int]int{}
+	for _, p := range b.posts {
+		entries[p.CategoryID]++
+	}
+
+	categories := []map[string]interface{}{}
+	for _, id := range ids {
+		c := b.categories[id]
+		parent, label := "", c.Name
+		if c.Parent != 0 {
+			parent = strconv.Itoa(c.Parent)
+			if pc, ok := b.categories[c.Parent]; ok {
+				label = pc.Name + "/" + c.Name
+			}
+		}
+		categories = append(categories, map[string]interface{}{
+			"id":      strconv.Itoa(c.ID),
+			"name":    c.Name,
+			"parent":  parent,
+			"label":   label,
+			"entries": strconv.Itoa(entries[c.ID]),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"item": map[string]interface{}{
+			"url":          b.name,
+			"secondaryUrl": "",
+			"categories":   categories,
+		},
+	})
+}
+
+func (s *Server) commentJSON(b *blog, c *Comment) map[string]interface{} {
+	parent := ""
+	if c.ParentID != 0 {
+		parent = strconv.Itoa(c.ParentID)
+	}
+	visibility := "2"
+	open := "Y"
+	if c.Secret {
+		visibility = "0"
+		open = "N"
+	}
+	return map[string]interface{}{
+		"id":         strconv.Itoa(c.ID),
+		"date":       strconv.FormatInt(c.Date.Unix(), 10),
+		"postId":     strconv.Itoa(c.PostID),
+		"name":       c.Name,
+		"parentId":   parent,
+		"homepage":   c.Homepage,
+		"visibility": visibility,
+		"comment":    c.Content,
+		"open":       open,
+		"link":       fmt.Sprintf("%s/%d#comment%d", s.blogURL(b.name), c.PostID, c.ID),
+	}
+}
+
+func (s *Server) handleCommentNewest(w http.ResponseWriter, r *http.Request, b *blog) {
+	page, err := formInt(r, "page")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	count, err := formInt(r, "count")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if page < 1 {
+		page = 1
+	}
+	if count < 1 || count > 10 {
+		count = 10
+	}
+
+	all := make([]*Comment, 0, len(b.comments))
+	for _, c := range b.comments {
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID > all[j].ID })
+
+	comments := []map[string]interface{}{}
+	for i := (page - 1) * count; i < len(all) && i < page*count; i++ {
+		comments = append(comments, s.commentJSON(b, all[i]))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"item": map[string]interface{}{
+			"url":          s.blogURL(b.name),
+			"secondaryUrl": "",
+			"comments":     map[string]interface{}{"comment": comments},
+		},
+	})
+}
+
+func (s *Server) handleCommentList(w http.ResponseWriter, r *http.Request, b *blog) {
+	postID, err := formInt(r, "postId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := b.posts[postID]; !ok {
+		writeError(w, http.StatusNotFound, "게시글이 존재하지 않습니다.")
+		return
+	}
+
+	comments := []map[string]interface{}{}
+	for _, c := range b.commentsOf(postID) {
+		comments = append(comments, s.commentJSON(b, c))
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"item": map[string]interface{}{
+			"url":          s.blogURL(b.name),
+			"secondaryUrl": "",
+			"postId":       strconv.Itoa(postID),
+			"totalCount":   strconv.Itoa(len(comments)),
+			"comments":     map[string]interface{}{"comment": comments},
+		},
+	})
+}
+
+// commentTarget resolves the postId and commentId parameters of a comment request.
+func commentTarget(r *http.Request, b *blog, needComment bool) (*Post, *Comment, error) {
+	postID, err := formInt(r, "postId")
+	if err != nil {
+		return nil, nil, err
+	}
+	p, ok := b.posts[postID]
+	if !ok {
+		return nil, nil, fmt.Errorf("게시글이 존재하지 않습니다.")
+	}
+	if !needComment {
+		return p, nil, nil
+	}
+	commentID, err := formInt(r, "commentId")
+	if err != nil {
+		return nil, nil, err
+	}
+	c, ok := b.comments[commentID]
+	if !ok || c.PostID != postID {
+		return nil, nil, fmt.Errorf("댓글이 존재하지 않습니다.")
+	}
+	return p, c, nil
+}
+
+func (s *Server) handleCommentWrite(w http.ResponseWriter, r *http.Request, b *blog) {
+	p, _, err := commentTarget(r, b, false)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	parentID, err := formInt(r, "parentId")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if parentID != 0 {
+		if parent, ok := b.comments[parentID]; !ok || parent.PostID != p.ID {
+			writeError(w, http.StatusBadRequest, "부모 댓글이 존재하지 않습니다.")
+			return
+		}
+	}
+
+	c := &Comment{
+		ID:       s.newID(),
+		PostID:   p.ID,
+		ParentID: parentID,
+		Name:     s.Owner,
+		Content:  r.Form.Get("content"),
+		Secret:   r.Form.Get("secret") == "1",
+		Date:     s.now(),
+	}
+	b.comments[c.ID] = c
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"commentUrl": fmt.Sprintf("%s/%d#comment%d", s.blogURL(b.name), p.ID, c.ID),
+		"result":     "OK",
+	})
+}
+
+func (s *Server) handleCommentModify(w http.ResponseWriter, r *http.Request, b *blog) {
+	p, c, err := commentTarget(r, b, true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.Content = r.Form.Get("content")
+	c.Secret = r.Form.Get("secret") == "1"
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"commentUrl": fmt.Sprintf("%s/%d#comment%d", s.blogURL(b.name), p.ID, c.ID),
+		"result":     "OK",
+	})
+}
+
+func (s *Server) handleCommentDelete(w http.ResponseWriter, r *http.Request, b *blog) {
+	_, c, err := commentTarget(r, b, true)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	delete(b.comments, c.ID)
+	for _, reply := range b.comments {
+		if reply.ParentID == c.ID {
+			delete(b.comments, reply.ID)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
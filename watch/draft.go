@@ -0,0 +1,160 @@
+package watch
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// StatusPublish is the front matter status that makes a draft publish.
+const StatusPublish = "publish"
+
+// FrontMatter is the YAML header of a draft between "---" lines.
+//
+//	---
+//	title: 제목
+//	status: publish
+//	category: 123
+//	tags: [go, api]
+//	visibility: 3
+//	published: 2026-11-01T09:00:00+09:00
+//	---
+//	<p>본문</p>
+type FrontMatter struct {
+	Title         string    `yaml:"title"`
+	Status        string    `yaml:"status,omitempty"`
+	Category      int       `yaml:"category,omitempty"`
+	Tags          Tags      `yaml:"tags,omitempty"`
+	Visibility    *int      `yaml:"visibility,omitempty"`
+	Published     time.Time `yaml:"published,omitempty"`
+	Slogan        string    `yaml:"slogan,omitempty"`
+	AcceptComment *bool     `yaml:"accept_comment,omitempty"`
+	Password      string    `yaml:"password,omitempty"`
+
+	// Written after publishing.
+	PostId      int       `yaml:"post_id,omitempty"`
+	URL         string    `yaml:"url,omitempty"`
+	PublishedAt time.Time `yaml:"published_at,omitempty"`
+
+	// Extra keeps unknown keys.
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Tags accepts a YAML list or a comma separated string.
+type Tags []string
+
+func (t *Tags) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = nil
+		for _, tag := range strings.Split(node.Value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				*t = append(*t, tag)
+			}
+		}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// Draft is a post file: front matter and an HTML body.
+type Draft struct {
+	FrontMatter
+	Body string
+}
+
+/*
+ParseDraft splits data into front matter and body. The front matter starts
+on the first line with "---" and ends at the next "---" line. A file without
+front matter is a draft with an empty title.
+*/
+func ParseDraft(data []byte) (*Draft, error) {
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	d := &Draft{}
+	header, body, ok := splitFrontMatter(text)
+	if !ok {
+		d.Body = text
+		return d, nil
+	}
+	if err := yaml.Unmarshal([]byte(header), &d.FrontMatter); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse front matter")
+	}
+	d.Body = body
+	return d, nil
+}
+
+func splitFrontMatter(text string) (header, body string, ok bool) {
+	line, rest, found := strings.Cut(text, "\n")
+	if !found || strings.TrimRight(line, "\r") != "---" {
+		return "", "", false
+	}
+	for offset := 0; offset <= len(rest); {
+		line, _, found := strings.Cut(rest[offset:], "\n")
+		if strings.TrimRight(line, "\r") == "---" {
+			end := offset + len(line)
+			if found {
+				end++
+			}
+			return rest[:offset], rest[end:], true
+		}
+		if !found {
+			break
+		}
+		offset += len(line) + 1
+	}
+	return "", "", false
+}
+
+// Bytes encodes the draft with its front matter.
+func (d *Draft) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(d.FrontMatter); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("---\n")
+	buf.WriteString(d.Body)
+	return buf.Bytes(), nil
+}
+
+// Option returns the post/write parameters set by the draft.
+func (d *Draft) Option() map[string]interface{} {
+	option := map[string]interface{}{
+		"title":   d.Title,
+		"content": d.Body,
+	}
+	if d.Visibility != nil {
+		option["visibility"] = *d.Visibility
+	}
+	if d.Category != 0 {
+		option["category"] = d.Category
+	}
+	if len(d.Tags) > 0 {
+		option["tag"] = strings.Join(d.Tags, ",")
+	}
+	if !d.Published.IsZero() {
+		option["published"] = d.Published.Unix()
+	}
+	if d.Slogan != "" {
+		option["slogan"] = d.Slogan
+	}
+	if d.AcceptComment != nil && !*d.AcceptComment {
+		option["acceptComment"] = 0
+	}
+	if d.Password != "" {
+		option["password"] = d.Password
+	}
+	return option
+}
@@ -0,0 +1,303 @@
+// Package watch publishes drafts from a directory.
+//
+// A Watcher polls Dir for drafts (HTML with a YAML front matter). When the
+// front matter status of a draft becomes "publish", the local images of the
+// draft are uploaded with AttachPost, the post is written with WritePost and
+// the file is moved to PublishedDir with the post id in its front matter.
+//
+//	w := watch.New(blog, "drafts")
+//	w.Log = os.Stderr
+//	err := w.Run(ctx) // returns after ctx is canceled (e.g. on SIGTERM)
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LimJiAn/tistory-go"
+	"github.com/pkg/errors"
+)
+
+// DefaultExtensions are the draft file extensions watched by default.
+var DefaultExtensions = []string{".html", ".htm"}
+
+// Result is the outcome of publishing one draft.
+type Result struct {
+	File   string // path of the published file
+	PostId int
+	URL    string
+	Err    error
+}
+
+// Watcher publishes the drafts of Dir.
+type Watcher struct {
+	Blog *tistory.Tistory
+	Dir  string
+	// PublishedDir receives published drafts (default: Dir/published).
+	PublishedDir string
+	// Interval is the polling interval of Run (default: 10s).
+	Interval time.Duration
+	// Extensions are the draft file extensions (default: DefaultExtensions).
+	Extensions []string
+	// Defaults are post/write parameters used when a draft does not set them.
+	// Drafts without a visibility are published (3).
+	Defaults map[string]interface{}
+	// Log receives one line per published or failed draft (nil: no log).
+	Log io.Writer
+
+	// seen remembers the modification time of drafts that are not ready or
+	// invalid, so they are parsed again only after they change. Other
+	// failures, such as API errors, are retried on the next scan.
+	seen map[string]time.Time
+}
+
+func New(blog *tistory.Tistory, dir string) *Watcher {
+	return &Watcher{Blog: blog, Dir: dir, Interval: 10 * time.Second}
+}
+
+/*
+Run scans Dir every Interval until ctx is done. A draft being published is
+finished before Run returns, so SIGTERM never leaves a half-moved file.
+*/
+func (w *Watcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	for {
+		if _, err := w.Scan(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Scan publishes the drafts of Dir whose status is publish. Errors of single
+// drafts are reported in the results; the error is for Dir itself.
+func (w *Watcher) Scan(ctx context.Context) ([]Result, error) {
+	if w.seen == nil {
+		w.seen = map[string]time.Time{}
+	}
+	entries, err := os.ReadDir(w.Dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read drafts")
+	}
+
+	var results []Result
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if entry.IsDir() || !w.watched(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(w.Dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if modTime, ok := w.seen[path]; ok && modTime.Equal(info.ModTime()) {
+			continue
+		}
+
+		result, ready := w.publish(path)
+		if !ready {
+			w.seen[path] = info.ModTime()
+			continue
+		}
+		if result.Err != nil {
+			var invalid *draftError
+			if errors.As(result.Err, &invalid) {
+				w.seen[path] = info.ModTime()
+			}
+			w.logf("failed to publish %s: %v", path, result.Err)
+		} else {
+			delete(w.seen, path)
+			w.logf("published %s as post %d (%s)", result.File, result.PostId, result.URL)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// publish publishes the draft at path if its status is publish.
+func (w *Watcher) publish(path string) (Result, bool) {
+	result := Result{File: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		result.Err = err
+		return result, true
+	}
+	d, err := ParseDraft(data)
+	if err != nil {
+		result.Err = &draftError{err}
+		return result, true
+	}
+	// A draft with a post id was written before; it only needs to be moved.
+	if d.PostId == 0 && !strings.EqualFold(strings.TrimSpace(d.Status), StatusPublish) {
+		return result, false
+	}
+
+	if d.PostId == 0 {
+		if d.Title == "" {
+			result.Err = &draftError{errors.New("front matter has no title")}
+			return result, true
+		}
+		if d.Body, err = w.uploadImages(filepath.Dir(path), d.Body); err != nil {
+			result.Err = err
+			return result, true
+		}
+
+		option := d.Option()
+		for key, value := range w.Defaults {
+			if _, ok := option[key]; !ok {
+				option[key] = value
+			}
+		}
+		if _, ok := option["visibility"]; !ok {
+			option["visibility"] = 3
+		}
+		written, err := w.Blog.WritePost(option)
+		if err != nil {
+			result.Err = err
+			return result, true
+		}
+		b, _ := written["tistory"].(map[string]interface{})
+		if d.PostId, _ = strconv.Atoi(fmt.Sprintf("%v", b["postId"])); d.PostId == 0 {
+			result.Err = &draftError{errors.New("post/write returned no postId")}
+			return result, true
+		}
+		d.URL = fmt.Sprintf("%v", b["url"])
+		d.PublishedAt = time.Now().Truncate(time.Second)
+		d.Status = "published"
+
+		// Record the post id in place first, so a failed move never publishes twice.
+		if err := writeDraft(path, d); err != nil {
+			result.Err = &draftError{errors.Wrapf(err, "post %d was written but the draft could not be updated", d.PostId)}
+			return result, true
+		}
+	}
+	result.PostId, result.URL = d.PostId, d.URL
+
+	dest, err := w.move(path)
+	if err != nil {
+		result.Err = errors.Wrapf(err, "post %d was written but the draft could not be moved", d.PostId)
+		return result, true
+	}
+	result.File = dest
+	return result, true
+}
+
+/*
+draftError is a failure that retrying cannot fix before the draft changes:
+an invalid front matter, or a post that may have been written without its
+id being recorded, which a retry would publish twice.
+*/
+type draftError struct{ error }
+
+func (e *draftError) Unwrap() error { return e.error }
+
+var imgSrc = regexp.MustCompile(`(?i)(<img\b[^>]*?\bsrc\s*=\s*)(["'])([^"']+)(["'])`)
+
+// uploadImages uploads the local images referenced by <img src> and points
+// them at the uploaded URLs. Remote and data: URLs are left alone.
+func (w *Watcher) uploadImages(dir, body string) (string, error) {
+	uploaded := map[string]string{}
+	var uploadErr error
+	body = imgSrc.ReplaceAllStringFunc(body, func(tag string) string {
+		m := imgSrc.FindStringSubmatch(tag)
+		src := m[3]
+		if uploadErr != nil || !isLocal(src) {
+			return tag
+		}
+		file := src
+		if unescaped, err := url.PathUnescape(src); err == nil {
+			file = unescaped
+		}
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, filepath.FromSlash(file))
+		}
+
+		remote, ok := uploaded[file]
+		if !ok {
+			result, err := w.Blog.AttachPost(file)
+			if err != nil {
+				uploadErr = errors.Wrapf(err, "Failed to upload %s", src)
+				return tag
+			}
+			b, _ := result["tistory"].(map[string]interface{})
+			remote = fmt.Sprintf("%v", b["url"])
+			uploaded[file] = remote
+		}
+		return m[1] + m[2] + remote + m[4]
+	})
+	return body, uploadErr
+}
+
+func isLocal(src string) bool {
+	u, err := url.Parse(src)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// move moves path into PublishedDir without overwriting existing files.
+func (w *Watcher) move(path string) (string, error) {
+	dir := w.PublishedDir
+	if dir == "" {
+		dir = filepath.Join(w.Dir, "published")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	dest := filepath.Join(dir, name)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dest); os.IsNotExist(err) {
+			break
+		}
+		dest = filepath.Join(dir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+	return dest, os.Rename(path, dest)
+}
+
+func writeDraft(path string, d *Draft) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (w *Watcher) watched(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	extensions := w.Extensions
+	if len(extensions) == 0 {
+		extensions = DefaultExtensions
+	}
+	for _, ext := range extensions {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watcher) logf(format string, args ...interface{}) {
+	if w.Log != nil {
+		fmt.Fprintf(w.Log, format+"\n", args...)
+	}
+}
@@ -0,0 +1,204 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LimJiAn/tistory-go/internal/testblog"
+	"github.com/LimJiAn/tistory-go/tistorytest"
+)
+
+func TestParseDraft(t *testing.T) {
+	d, err := ParseDraft([]byte("---\ntitle: 제목\nstatus: publish\ntags: go, api\nvisibility: 0\nseries: notes\n---\n<p>본문</p>\n"))
+	if err != nil {
+		t.Fatalf("ParseDraft() error = %v", err)
+	}
+	if d.Title != "제목" || d.Status != "publish" || !reflect.DeepEqual([]string(d.Tags), []string{"go", "api"}) ||
+		*d.Visibility != 0 || d.Extra["series"] != "notes" || d.Body != "<p>본문</p>\n" {
+		t.Errorf("ParseDraft() = %+v", d)
+	}
+	option := d.Option()
+	if option["tag"] != "go,api" || option["visibility"] != 0 || option["content"] != "<p>본문</p>\n" {
+		t.Errorf("Draft.Option() = %v", option)
+	}
+
+	d.PostId = 7
+	data, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseDraft(data)
+	if err != nil || again.PostId != 7 || again.Extra["series"] != "notes" || again.Body != d.Body {
+		t.Errorf("ParseDraft(Draft.Bytes()) = %+v, %v", again, err)
+	}
+
+	if d, err := ParseDraft([]byte("<p>no front matter</p>")); err != nil || d.Title != "" || d.Body != "<p>no front matter</p>" {
+		t.Errorf("ParseDraft() without front matter = %+v, %v", d, err)
+	}
+	if _, err := ParseDraft([]byte("---\ntitle: [\n---\n")); err == nil {
+		t.Errorf("ParseDraft() with bad YAML error = nil, want error")
+	}
+}
+
+func TestWatcher_Scan(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "images"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write("images/cat.png", "png")
+	draft := "---\ntitle: hello\nstatus: draft\ntags: [go]\n---\n" +
+		`<p><img src="images/cat.png" alt="cat"> <img src='https://example.com/dog.png'></p>` + "\n"
+	path := write("hello.html", draft)
+	write("notes.txt", "ignored")
+
+	var log strings.Builder
+	w := New(blog, dir)
+	w.Log = &log
+	ctx := context.Background()
+
+	if results, err := w.Scan(ctx); err != nil || len(results) != 0 || len(srv.Posts(tistorytest.DefaultBlog)) != 0 {
+		t.Fatalf("Watcher.Scan() draft = %+v, %v", results, err)
+	}
+
+	write("hello.html", strings.Replace(draft, "status: draft", "status: publish", 1))
+	// Make sure the modification time differs from the remembered one.
+	os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+	results, err := w.Scan(ctx)
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("Watcher.Scan() = %+v, %v", results, err)
+	}
+
+	posts := srv.Posts(tistorytest.DefaultBlog)
+	if len(posts) != 1 || posts[0].Title != "hello" || posts[0].Visibility != 3 {
+		t.Fatalf("posts = %+v", posts)
+	}
+	if !strings.Contains(posts[0].Content, `src="https://cfile.tistory.test/image/`) || !strings.Contains(posts[0].Content, "https://example.com/dog.png") {
+		t.Errorf("published content = %q", posts[0].Content)
+	}
+	if len(srv.Attachments(tistorytest.DefaultBlog)) != 1 {
+		t.Errorf("attachments = %d, want 1", len(srv.Attachments(tistorytest.DefaultBlog)))
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("draft still in %s", path)
+	}
+	moved := filepath.Join(dir, "published", "hello.html")
+	data, err := os.ReadFile(moved)
+	if err != nil {
+		t.Fatalf("published file: %v", err)
+	}
+	if d, err := ParseDraft(data); err != nil || d.PostId != posts[0].ID || d.URL == "" {
+		t.Errorf("published front matter = %+v, %v", d, err)
+	}
+	if results[0].File != moved || !strings.Contains(log.String(), "published") {
+		t.Errorf("result = %+v, log = %q", results[0], log.String())
+	}
+
+	// An invalid draft is not retried until it changes.
+	write("invalid.html", "---\nstatus: publish\n---\n<p>no title</p>\n")
+	if results, _ := w.Scan(ctx); len(results) != 1 || results[0].Err == nil {
+		t.Errorf("Watcher.Scan() invalid = %+v, want an error", results)
+	}
+	if results, _ := w.Scan(ctx); len(results) != 0 {
+		t.Errorf("Watcher.Scan() retried unchanged invalid draft: %+v", results)
+	}
+	os.Remove(filepath.Join(dir, "invalid.html"))
+
+	// A draft with a missing image is retried and published once it exists.
+	write("broken.html", "---\ntitle: broken\nstatus: publish\n---\n<img src=\"missing.png\">\n")
+	for i := 0; i < 2; i++ {
+		if results, _ := w.Scan(ctx); len(results) != 1 || results[0].Err == nil {
+			t.Errorf("Watcher.Scan() broken = %+v, want an error", results)
+		}
+	}
+	write("missing.png", "png")
+	if results, _ := w.Scan(ctx); len(results) != 1 || results[0].Err != nil {
+		t.Errorf("Watcher.Scan() after adding the image = %+v", results)
+	}
+
+	// A temporary API error is retried on the next scan.
+	write("later.html", "---\ntitle: later\nstatus: publish\n---\n<p>x</p>\n")
+	srv.AccessToken = "expired"
+	if results, _ := w.Scan(ctx); len(results) != 1 || results[0].Err == nil {
+		t.Errorf("Watcher.Scan() with API error = %+v, want an error", results)
+	}
+	srv.AccessToken = blog.AccessToken
+	if results, _ := w.Scan(ctx); len(results) != 1 || results[0].Err != nil || results[0].PostId == 0 {
+		t.Errorf("Watcher.Scan() after API error = %+v", results)
+	}
+}
+
+func TestWatcher_ScanMoveFailure(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	blog := testblog.New(srv)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.html")
+	if err := os.WriteFile(path, []byte("---\ntitle: hello\nstatus: publish\n---\n<p>x</p>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A file where the published directory should be makes the move fail.
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.WriteFile(blocked, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := New(blog, dir)
+	w.PublishedDir = blocked
+	ctx := context.Background()
+
+	results, err := w.Scan(ctx)
+	if err != nil || len(results) != 1 || results[0].Err == nil || results[0].PostId == 0 {
+		t.Fatalf("Watcher.Scan() with failing move = %+v, %v", results, err)
+	}
+	data, _ := os.ReadFile(path)
+	if d, err := ParseDraft(data); err != nil || d.PostId != results[0].PostId {
+		t.Errorf("draft after failed move = %+v, %v", d, err)
+	}
+
+	// The next scan only moves the draft, without writing the post again.
+	w.PublishedDir = filepath.Join(dir, "published")
+	results, err = w.Scan(ctx)
+	if err != nil || len(results) != 1 || results[0].Err != nil || results[0].File != filepath.Join(dir, "published", "hello.html") {
+		t.Fatalf("Watcher.Scan() retrying the move = %+v, %v", results, err)
+	}
+	if posts := srv.Posts(tistorytest.DefaultBlog); len(posts) != 1 {
+		t.Errorf("posts = %d, want 1", len(posts))
+	}
+}
+
+func TestWatcher_Run(t *testing.T) {
+	srv := tistorytest.NewServer()
+	defer srv.Close()
+	w := New(testblog.New(srv), t.TempDir())
+	w.Interval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watcher.Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher.Run() did not return after cancel")
+	}
+}